@@ -0,0 +1,84 @@
+package zeroslog
+
+import "log/slog"
+
+// allowStage returns the pipeline stage implementing AllowKeys. Dropped attributes are counted in st.
+func allowStage(allow keyMatcher, st *stats) attrStage {
+	var filter func(prefix string, a slog.Attr, allowed bool) (slog.Attr, bool)
+	// filter keeps a if it matches, or if allowed is true because a parent group already matched.
+	filter = func(prefix string, a slog.Attr, allowed bool) (slog.Attr, bool) {
+		key := prefix + a.Key
+		allowed = allowed || allow.match(key)
+		a.Value = a.Value.Resolve()
+		if a.Value.Kind() != slog.KindGroup {
+			if !allowed {
+				countDropped(st)
+			}
+			return a, allowed
+		}
+		if a.Key != "" {
+			prefix = key + "."
+		}
+		group := a.Value.Group()
+		members := make([]slog.Attr, 0, len(group))
+		for _, m := range group {
+			if m, ok := filter(prefix, m, allowed); ok {
+				members = append(members, m)
+			}
+		}
+		if len(members) == 0 && !allowed {
+			return a, false
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(members...)}, true
+	}
+	return func(prefix string, a slog.Attr) (slog.Attr, bool) {
+		return filter(prefix, a, false)
+	}
+}
+
+// leafStage returns a pipeline stage applying f to every attribute which is not a group,
+// including group members.
+func leafStage(f func(a slog.Attr) slog.Attr) attrStage {
+	var apply func(a slog.Attr) slog.Attr
+	apply = func(a slog.Attr) slog.Attr {
+		if a.Value.Kind() != slog.KindGroup {
+			return f(a)
+		}
+		group := a.Value.Group()
+		members := make([]slog.Attr, len(group))
+		for i, m := range group {
+			m.Value = m.Value.Resolve()
+			members[i] = apply(m)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(members...)}
+	}
+	return func(_ string, a slog.Attr) (slog.Attr, bool) {
+		return apply(a), true
+	}
+}
+
+// emptyKeyStage returns the pipeline stage implementing DropEmptyKeys. Groups with an empty key are kept,
+// and groups left empty are dropped.
+func emptyKeyStage() attrStage {
+	var filter func(a slog.Attr) (slog.Attr, bool)
+	filter = func(a slog.Attr) (slog.Attr, bool) {
+		a.Value = a.Value.Resolve()
+		if a.Value.Kind() != slog.KindGroup {
+			return a, a.Key != ""
+		}
+		group := a.Value.Group()
+		members := make([]slog.Attr, 0, len(group))
+		for _, m := range group {
+			if m, ok := filter(m); ok {
+				members = append(members, m)
+			}
+		}
+		if len(members) == 0 && len(group) > 0 {
+			return a, false
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(members...)}, true
+	}
+	return func(_ string, a slog.Attr) (slog.Attr, bool) {
+		return filter(a)
+	}
+}
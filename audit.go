@@ -0,0 +1,82 @@
+package zeroslog
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+// auditIncompleteKey is the key of the field listing the keys missing from an audit record.
+const auditIncompleteKey = "audit_incomplete"
+
+// auditMarkerKey is the key of the boolean attribute flagging audit records.
+const auditMarkerKey = "audit"
+
+// auditKeys returns keys extended with the flattened keys of attrs, prefixed with prefix.
+// Keys are only tracked when audit keys are configured.
+func (h *Handler) auditKeys(keys []string, prefix string, attrs []slog.Attr) []string {
+	if len(h.opts.AuditKeys) == 0 || len(attrs) == 0 {
+		return keys
+	}
+	keys = slices.Clip(keys)
+	for _, a := range attrs {
+		keys = appendFlatKeys(keys, prefix, a)
+	}
+	return keys
+}
+
+// audit checks that a record carries all the audit keys if it's an audit record.
+// ctxKeys are the flattened keys added with WithAttrs, attrs are the resolved record attributes,
+// and prefix is their group path. rec is only used for its level and message.
+// It returns the top-level attributes to add to an incomplete audit record, and nil otherwise.
+func (h *Handler) audit(ctxKeys []string, prefix string, rec *slog.Record, attrs []slog.Attr) []slog.Attr {
+	if len(h.opts.AuditKeys) == 0 || !isAuditRecord(h.opts.AuditLevel, rec.Level, attrs) {
+		return nil
+	}
+	keys := slices.Clone(ctxKeys)
+	for _, a := range attrs {
+		keys = appendFlatKeys(keys, prefix, a)
+	}
+	var missing []string
+	for _, key := range h.opts.AuditKeys {
+		if !slices.Contains(keys, key) {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	h.reportError(fmt.Errorf("zeroslog: incomplete audit record %q: missing %s", rec.Message, strings.Join(missing, ", ")))
+	return []slog.Attr{slog.Any(auditIncompleteKey, missing)}
+}
+
+// isAuditRecord reports whether a record at level lvl with the given attributes is at the audit level,
+// or carries a true audit marker attribute.
+func isAuditRecord(auditLevel slog.Leveler, lvl slog.Level, attrs []slog.Attr) bool {
+	if auditLevel != nil && lvl == auditLevel.Level() {
+		return true
+	}
+	for _, a := range attrs {
+		if a.Key == auditMarkerKey && a.Value.Kind() == slog.KindBool {
+			return a.Value.Bool()
+		}
+	}
+	return false
+}
+
+// appendFlatKeys appends the key of a prefixed with prefix to keys,
+// or the keys of its members, dot-joined, if a is a group.
+func appendFlatKeys(keys []string, prefix string, a slog.Attr) []string {
+	value := a.Value.Resolve()
+	if value.Kind() != slog.KindGroup {
+		return append(keys, prefix+a.Key)
+	}
+	if a.Key != "" {
+		prefix = prefix + a.Key + "."
+	}
+	for _, attr := range value.Group() {
+		keys = appendFlatKeys(keys, prefix, attr)
+	}
+	return keys
+}
@@ -0,0 +1,89 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"testing"
+)
+
+func TestAudit(t *testing.T) {
+	const levelAudit = slog.LevelInfo + 2
+	for name, tc := range map[string]struct {
+		hdl     func(slog.Handler) slog.Handler
+		level   slog.Level
+		attrs   []slog.Attr
+		missing []any
+	}{
+		"complete": {
+			attrs: []slog.Attr{slog.Bool("audit", true), slog.String("actor", "bob"), slog.String("action", "delete"), slog.Group("req", slog.String("target", "file"))},
+		},
+		"complete-with-context": {
+			hdl: func(h slog.Handler) slog.Handler {
+				return h.WithAttrs([]slog.Attr{slog.String("actor", "bob")})
+			},
+			attrs: []slog.Attr{slog.Bool("audit", true), slog.String("action", "delete"), slog.Group("req", slog.String("target", "file"))},
+		},
+		"complete-grouped": {
+			hdl: func(h slog.Handler) slog.Handler {
+				return h.WithAttrs([]slog.Attr{slog.String("actor", "bob"), slog.String("action", "delete")}).WithGroup("req")
+			},
+			level: levelAudit,
+			attrs: []slog.Attr{slog.String("target", "file")},
+		},
+		"incomplete": {
+			attrs:   []slog.Attr{slog.Bool("audit", true), slog.Group("user", slog.String("actor", "bob")), slog.String("action", "delete")},
+			missing: []any{"actor", "req.target"},
+		},
+		"incomplete-level": {
+			hdl: func(h slog.Handler) slog.Handler {
+				return h.WithGroup("req").WithAttrs([]slog.Attr{slog.String("actor", "bob")})
+			},
+			level:   levelAudit,
+			attrs:   []slog.Attr{slog.String("action", "delete"), slog.String("target", "file")},
+			missing: []any{"actor", "action"},
+		},
+		"not-audit": {
+			attrs: []slog.Attr{slog.Bool("audit", false), slog.String("actor", "bob")},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			out := bytes.Buffer{}
+			var reported error
+			var hdl slog.Handler = NewJsonHandler(&out, &HandlerOptions{
+				AuditKeys:  []string{"actor", "action", "req.target"},
+				AuditLevel: levelAudit,
+				OnError:    func(err error) { reported = err },
+			})
+			if tc.hdl != nil {
+				hdl = tc.hdl(hdl)
+			}
+			if tc.level == 0 {
+				tc.level = slog.LevelInfo
+			}
+			rec := slog.NewRecord(now, tc.level, "foobar", 0)
+			rec.AddAttrs(tc.attrs...)
+			if err := hdl.Handle(context.Background(), rec); err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			m := map[string]any{}
+			if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+				t.Fatalf("Failed to json decode log output: %s", err.Error())
+			}
+			if tc.missing == nil {
+				if _, found := m[auditIncompleteKey]; found || reported != nil {
+					t.Fatalf("Unexpected incomplete audit record %v: %v", m, reported)
+				}
+				return
+			}
+			if !reflect.DeepEqual(m[auditIncompleteKey], tc.missing) {
+				t.Fatalf("Unexpected missing keys %v, expected %v", m[auditIncompleteKey], tc.missing)
+			}
+			if reported == nil {
+				t.Fatalf("Expected incomplete audit record to be reported")
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package zeroslog
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// ConfigMessage is the message of the record written with LogConfigOnFirstUse.
+	ConfigMessage = "zeroslog configuration"
+	// ConfigKey is the key of the object holding the configuration in the record written with LogConfigOnFirstUse.
+	ConfigKey = "config"
+)
+
+// configEmitter writes the configuration record of LogConfigOnFirstUse once, for a root handler and all
+// its derived handlers.
+type configEmitter struct {
+	root *Handler
+	once sync.Once
+}
+
+// emit writes the configuration record, if it's the first call. It's safe to call on a nil configEmitter.
+// Concurrent calls wait for the record to be written, so that it's written before any other record.
+func (e *configEmitter) emit() {
+	if e == nil {
+		return
+	}
+	e.once.Do(func() {
+		h := e.root
+		logger := h.logger
+		if logger.GetLevel() != zerolog.Disabled {
+			// The record is informative, and written whatever the level of the handler.
+			logger = logger.Level(zerolog.TraceLevel)
+		}
+		var evt *zerolog.Event
+		switch {
+		case h.opts.OmitLevel:
+			evt = logger.Log()
+		case h.opts.FieldNames.Level != "":
+			if evt = logger.Log(); evt != nil {
+				evt.Str(h.opts.FieldNames.Level, zerolog.LevelFieldMarshalFunc(zerolog.InfoLevel))
+			}
+		default:
+			evt = logger.WithLevel(zerolog.InfoLevel)
+		}
+		if evt == nil {
+			return
+		}
+		evt = evt.Ctx(context.Background())
+		rec := slog.NewRecord(time.Now(), slog.LevelInfo, ConfigMessage, 0)
+		if h.opts.EnvelopeFirst {
+			h.writeSeverity(evt, rec.Level)
+			h.writeEnvelope(evt, &rec)
+		}
+		mapAttr(evt, e.root.limits, slog.Attr{Key: ConfigKey, Value: slog.GroupValue(h.configAttrs()...)})
+		h.endLog(&rec, evt, nil)
+	})
+}
+
+// configAttrs summarizes the effective configuration of the root handler h. Options which may hold
+// sensitive values, like keys and message patterns, are only summarized by their count.
+func (h *Handler) configAttrs() []slog.Attr {
+	opts := h.opts
+	levelSource := "logger"
+	switch opts.Level.(type) {
+	case nil:
+	case *slog.LevelVar:
+		levelSource = "level_var"
+	default:
+		levelSource = "option"
+	}
+	format := "logger"
+	switch {
+	case h.nanoTime:
+		format = "console"
+	case h.out != nil:
+		format = "json"
+	}
+	attrs := []slog.Attr{
+		slog.Group("level", slog.String("source", levelSource), slog.String("value", LevelString(h.MinLevel()))),
+		slog.String("format", format),
+		slog.Bool("add_source", opts.AddSource),
+		slog.Bool("strict", opts.StrictSlogCompliance),
+	}
+	if opts.WriterLabel != "" {
+		attrs = append(attrs, slog.String("writer", opts.WriterLabel))
+	}
+	if opts.SuppressRepeats > 0 {
+		attrs = append(attrs, slog.Group("suppress_repeats",
+			slog.Duration("window", opts.SuppressRepeats), slog.Duration("summary_interval", opts.SummaryInterval)))
+	}
+	if opts.WriteTimeout > 0 {
+		attrs = append(attrs, slog.Duration("write_timeout", opts.WriteTimeout))
+	}
+	if opts.MaxUniqueKeys > 0 {
+		attrs = append(attrs, slog.Int("max_unique_keys", opts.MaxUniqueKeys))
+	}
+	for _, count := range []struct {
+		key string
+		n   int
+	}{
+		{"allow_keys", len(opts.AllowKeys)},
+		{"audit_keys", len(opts.AuditKeys)},
+		{"drop_messages", len(opts.DropMessages)},
+		{"only_messages", len(opts.OnlyMessages)},
+		{"stringify_values", len(opts.StringifyValues)},
+		{"default_attrs", len(opts.DefaultAttrs)},
+	} {
+		if count.n > 0 {
+			attrs = append(attrs, slog.Int(count.key, count.n))
+		}
+	}
+	if opts.ReplaceAttr != nil {
+		attrs = append(attrs, slog.Bool("replace_attr", true))
+	}
+	return attrs
+}
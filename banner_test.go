@@ -0,0 +1,80 @@
+package zeroslog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLogConfigOnFirstUse(t *testing.T) {
+	out := &gatedWriter{release: make(chan struct{})}
+	close(out.release)
+	lvl := new(slog.LevelVar)
+	lvl.Set(slog.LevelWarn)
+	hdl := NewJsonHandler(out, &HandlerOptions{
+		LogConfigOnFirstUse: true,
+		Level:               lvl,
+		AllowKeys:           []string{"secret_a", "secret_b"},
+		SuppressRepeats:     time.Second,
+		WriterLabel:         "stdout",
+	})
+	logger := slog.New(hdl)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			logger.With("secret_a", i).WithGroup("g").Warn(fmt.Sprint("msg", i), "secret_b", i)
+		}(i)
+	}
+	wg.Wait()
+
+	var banners []map[string]any
+	lines := 0
+	scanner := bufio.NewScanner(&out.buf)
+	for scanner.Scan() {
+		var m map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			t.Fatal(err)
+		}
+		if m["message"] == ConfigMessage {
+			if lines != 0 {
+				t.Errorf("Expected the configuration to be written first, got it at line %d", lines)
+			}
+			banners = append(banners, m)
+		}
+		lines++
+	}
+	if len(banners) != 1 || lines != 9 {
+		t.Fatalf("Expected a single configuration record and 8 records, got %d and %d", len(banners), lines-len(banners))
+	}
+	banner := banners[0]
+	exp := map[string]any{
+		"level":            map[string]any{"source": "level_var", "value": "WARN"},
+		"format":           "json",
+		"add_source":       false,
+		"strict":           false,
+		"writer":           "stdout",
+		"suppress_repeats": map[string]any{"window": 1000.0, "summary_interval": 0.0},
+		"allow_keys":       2.0,
+	}
+	if banner["level"] != "info" || !jsonEqual(banner[ConfigKey], exp) {
+		t.Errorf("Unexpected configuration record %v", banner)
+	}
+	if config, _ := json.Marshal(banner[ConfigKey]); bytes.Contains(config, []byte("secret")) {
+		t.Errorf("Expected the allowed keys not to be written in the configuration, got %s", config)
+	}
+}
+
+func TestLogConfigOnFirstUse_Disabled(t *testing.T) {
+	out := bytes.Buffer{}
+	slog.New(NewJsonHandler(&out, nil)).Info("msg")
+	if bytes.Contains(out.Bytes(), []byte(ConfigMessage)) {
+		t.Errorf("Unexpected configuration record %s", out.String())
+	}
+}
@@ -0,0 +1,326 @@
+package zeroslog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// BatchOptions configures how batching handlers group and deliver records.
+type BatchOptions struct {
+	// MaxBatch is the maximum number of records in a batch.
+	// A batch is delivered as soon as it is full. Values lower than 1 mean 1.
+	MaxBatch int
+	// MaxDelay is the maximum time a record waits before being delivered.
+	// Zero means records are only delivered when a batch is full, or on Flush and Close.
+	MaxDelay time.Duration
+	// Backoff is the delay before retrying a failed delivery.
+	// It doubles after each failed attempt.
+	Backoff time.Duration
+	// Headers are additional headers sent with each request by HTTP handlers.
+	Headers http.Header
+	// Gzip enables gzip compression of the request bodies sent by HTTP handlers.
+	Gzip bool
+	// MaxPending, if greater than zero, bounds the number of records waiting for delivery.
+	// When it's reached, Handle waits for pending records to be delivered, unless the context passed to Handle
+	// is done, in which case the record is dropped, counted in Stats and reported to OnError as ErrQueueFull.
+	// The context of Handle is only used to avoid blocking: records are delivered even if it's canceled.
+	MaxPending int
+	// ShutdownTimeout, if greater than zero, bounds the time Close waits for pending records to be delivered.
+	// Past it, the context of the delivery is canceled, interrupting HTTP requests and retries, and the
+	// undelivered records are dropped, counted in Stats and reported to OnError.
+	ShutdownTimeout time.Duration
+}
+
+var (
+	// ErrClosed is reported when a record is handled after the handler has been closed.
+	ErrClosed = errors.New("zeroslog: handler closed")
+	// ErrQueueFull is reported when a record is dropped because BatchOptions.MaxPending is reached
+	// and the context passed to Handle is done, or because its queue is full with QueueOptions.DropWhenFull.
+	ErrQueueFull = errors.New("zeroslog: queue full")
+)
+
+// BatchingHandler is a Handler delivering serialized records in batches
+// from a background goroutine.
+type BatchingHandler struct {
+	*Handler
+	writer *batchWriter
+}
+
+// NewBatchingHandler creates a handler which serializes records with zerolog and
+// passes them to flush in batches. A batch is delivered from a background goroutine
+// as soon as it holds maxBatch records, or every maxDelay otherwise.
+// Each record in a batch is a single JSON line, including the trailing newline.
+//
+// A failing flush is retried opts.FlushRetries times, after which the batch is dropped
+// and the error reported to opts.OnError.
+//
+// As with NewJsonHandler, records below zerolog.InfoLevel are discarded unless opts.Level is set.
+// Close must be called to deliver pending records and release the background goroutine.
+func NewBatchingHandler(flush func(batch [][]byte) error, maxBatch int, maxDelay time.Duration, opts *HandlerOptions) *BatchingHandler {
+	flushCtx := func(_ context.Context, batch [][]byte) error { return flush(batch) }
+	return newBatchingHandler(flushCtx, BatchOptions{MaxBatch: maxBatch, MaxDelay: maxDelay}, opts)
+}
+
+// newBatchingHandler creates a BatchingHandler passing batches to flush, with the context of the delivery.
+func newBatchingHandler(flush func(ctx context.Context, batch [][]byte) error, batch BatchOptions, opts *HandlerOptions) *BatchingHandler {
+	h := NewHandler(zerolog.New(nil).Level(shortcutLevel(opts)), opts)
+	w := newBatchWriter(flush, batch, h.opts.FlushRetries, h.reportBatchError)
+	h.setOutput(w)
+	h.queue = w
+	h.filtered = true
+	return &BatchingHandler{Handler: h, writer: w}
+}
+
+// Flush synchronously delivers all the pending records.
+func (h *BatchingHandler) Flush() error {
+	return h.writer.deliver(false)
+}
+
+// Close stops the background goroutine and synchronously delivers all the pending records.
+// Records handled after Close are dropped.
+func (h *BatchingHandler) Close() error {
+	return errors.Join(h.Handler.Close(), h.writer.Close())
+}
+
+// reportBatchError counts the n records of a failed batch as dropped, and reports err.
+func (h *Handler) reportBatchError(err error, n int) {
+	h.stats.dropped.Add(uint64(n))
+	h.reportError(err)
+}
+
+// waitQueue waits for room in the queue of a batching handler. If the queue is full and ctx is done,
+// it counts and reports the record as dropped, and returns false.
+func (h *Handler) waitQueue(ctx context.Context) bool {
+	if h.queue == nil || h.queue.wait(ctx) {
+		return true
+	}
+	h.reportBatchError(fmt.Errorf("%w: %w", ErrQueueFull, context.Cause(ctx)), 1)
+	return false
+}
+
+// batchWriter is an io.Writer accumulating records and delivering them in batches.
+type batchWriter struct {
+	flush           func(ctx context.Context, batch [][]byte) error
+	maxBatch        int
+	maxPending      int
+	retries         int
+	backoff         time.Duration
+	shutdownTimeout time.Duration
+	onError         func(err error, n int)
+	// ctx is the context of deliveries, canceled by Close.
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+
+	mu    sync.Mutex
+	batch [][]byte
+	// freed is closed when pending records are taken for delivery.
+	freed  chan struct{}
+	closed bool
+
+	// deliverMu serializes deliveries so that batches are flushed in order.
+	deliverMu sync.Mutex
+	kick      chan struct{}
+	done      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// newBatchWriter creates a batchWriter and starts its background goroutine.
+func newBatchWriter(flush func(ctx context.Context, batch [][]byte) error, opts BatchOptions, retries int, onError func(err error, n int)) *batchWriter {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	w := &batchWriter{
+		flush:           flush,
+		maxBatch:        max(opts.MaxBatch, 1),
+		maxPending:      opts.MaxPending,
+		retries:         retries,
+		backoff:         opts.Backoff,
+		shutdownTimeout: opts.ShutdownTimeout,
+		onError:         onError,
+		ctx:             ctx,
+		cancel:          cancel,
+		freed:           make(chan struct{}),
+		kick:            make(chan struct{}, 1),
+		done:            make(chan struct{}),
+	}
+	w.wg.Add(1)
+	go w.run(opts.MaxDelay)
+	return w
+}
+
+// Write implements io.Writer.
+func (w *batchWriter) Write(p []byte) (int, error) {
+	line := append([]byte(nil), p...) // zerolog reuses p once Write returns.
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		w.onError(ErrClosed, 1)
+		return len(p), nil
+	}
+	w.batch = append(w.batch, line)
+	full := len(w.batch) >= w.maxBatch || w.full()
+	w.mu.Unlock()
+	if full {
+		w.kickDelivery()
+	}
+	return len(p), nil
+}
+
+// full reports whether MaxPending records are waiting for delivery. w.mu must be held.
+func (w *batchWriter) full() bool {
+	return w.maxPending > 0 && len(w.batch) >= w.maxPending
+}
+
+// kickDelivery wakes the background goroutine up, to deliver full batches.
+func (w *batchWriter) kickDelivery() {
+	select {
+	case w.kick <- struct{}{}:
+	default:
+	}
+}
+
+// wait waits until fewer than MaxPending records are waiting for delivery. It returns false if ctx
+// is done first. Records handled concurrently may exceed MaxPending by the number of concurrent callers.
+func (w *batchWriter) wait(ctx context.Context) bool {
+	var done <-chan struct{}
+	if ctx != nil {
+		done = ctx.Done()
+	}
+	for {
+		w.mu.Lock()
+		if w.closed || !w.full() {
+			w.mu.Unlock()
+			return true
+		}
+		freed := w.freed
+		w.mu.Unlock()
+		w.kickDelivery()
+		select {
+		case <-freed:
+		case <-done:
+			return false
+		}
+	}
+}
+
+// run delivers full batches when kicked, and all pending records every maxDelay.
+func (w *batchWriter) run(maxDelay time.Duration) {
+	defer w.wg.Done()
+	var tick <-chan time.Time
+	if maxDelay > 0 {
+		ticker := time.NewTicker(maxDelay)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-w.kick:
+			w.deliver(true)
+		case <-tick:
+			w.deliver(false)
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// deliver flushes pending records in batches of at most maxBatch records.
+// If fullOnly is true, a trailing incomplete batch is kept pending, unless MaxPending is reached.
+func (w *batchWriter) deliver(fullOnly bool) error {
+	w.deliverMu.Lock()
+	defer w.deliverMu.Unlock()
+
+	w.mu.Lock()
+	pending := w.batch
+	keep := 0
+	if fullOnly && !w.full() {
+		keep = len(pending) % w.maxBatch
+	}
+	w.batch = append([][]byte(nil), pending[len(pending)-keep:]...)
+	pending = pending[:len(pending)-keep]
+	if len(pending) > 0 {
+		close(w.freed)
+		w.freed = make(chan struct{})
+	}
+	w.mu.Unlock()
+
+	var errs []error
+	for len(pending) > 0 {
+		n := min(len(pending), w.maxBatch)
+		if err := w.flushBatch(pending[:n]); err != nil {
+			errs = append(errs, err)
+		}
+		pending = pending[n:]
+	}
+	return errors.Join(errs...)
+}
+
+// flushBatch calls flush, retrying on failure, and reports the batch as dropped if all attempts failed,
+// or if the delivery context is canceled.
+func (w *batchWriter) flushBatch(batch [][]byte) error {
+	var err error
+	backoff := w.backoff
+	attempt := 0
+	for ; attempt <= w.retries; attempt++ {
+		if attempt > 0 && backoff > 0 {
+			if !sleepContext(w.ctx, backoff) {
+				break
+			}
+			backoff *= 2
+		}
+		if w.ctx.Err() != nil {
+			break
+		}
+		if err = w.flush(w.ctx, batch); err == nil {
+			return nil
+		}
+	}
+	if cause := context.Cause(w.ctx); cause != nil {
+		err = cause
+	}
+	err = fmt.Errorf("zeroslog: failed to flush batch of %d records after %d attempts: %w", len(batch), attempt, err)
+	w.onError(err, len(batch))
+	return err
+}
+
+// sleepContext waits for d, and returns false if ctx is done first.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Close stops the background goroutine and delivers all pending records,
+// canceling the delivery context after the shutdown timeout, if any.
+func (w *batchWriter) Close() error {
+	return w.closeContext(context.Background())
+}
+
+// closeContext is like Close, but also cancels the delivery context when ctx is done.
+func (w *batchWriter) closeContext(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+	defer w.cancel(nil)
+	defer context.AfterFunc(ctx, func() { w.cancel(context.Cause(ctx)) })()
+	if w.shutdownTimeout > 0 {
+		timer := time.AfterFunc(w.shutdownTimeout, func() { w.cancel(nil) })
+		defer timer.Stop()
+	}
+	close(w.done)
+	w.wg.Wait()
+	return w.deliver(false)
+}
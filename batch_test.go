@@ -0,0 +1,260 @@
+package zeroslog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// batchRecorder collects the batches flushed by a batching handler.
+type batchRecorder struct {
+	mu      sync.Mutex
+	batches [][][]byte
+	fail    int
+}
+
+func (r *batchRecorder) flush(batch [][]byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.fail > 0 {
+		r.fail--
+		return errors.New("failure")
+	}
+	r.batches = append(r.batches, batch)
+	return nil
+}
+
+func (r *batchRecorder) sizes() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sizes := make([]int, len(r.batches))
+	for i, b := range r.batches {
+		sizes[i] = len(b)
+	}
+	return sizes
+}
+
+func (r *batchRecorder) messages(t *testing.T) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	msgs := []string{}
+	for _, b := range r.batches {
+		for _, line := range b {
+			m := map[string]any{}
+			if err := json.Unmarshal(line, &m); err != nil {
+				t.Fatalf("Failed to json decode log output: %s", err.Error())
+			}
+			msgs = append(msgs, m["message"].(string))
+		}
+	}
+	return msgs
+}
+
+func TestBatchingHandler_Size(t *testing.T) {
+	rec := &batchRecorder{}
+	hdl := NewBatchingHandler(rec.flush, 3, time.Hour, nil)
+	for i := 0; i < 7; i++ {
+		hdl.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, strconv.Itoa(i), 0))
+	}
+	deadline := time.Now().Add(time.Second)
+	for len(rec.sizes()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if err := hdl.Close(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if sizes := rec.sizes(); len(sizes) != 3 || sizes[0] != 3 || sizes[1] != 3 || sizes[2] != 1 {
+		t.Fatalf("Unexpected batch sizes %v", sizes)
+	}
+	for i, msg := range rec.messages(t) {
+		if msg != strconv.Itoa(i) {
+			t.Fatalf("Unexpected record %q at position %d", msg, i)
+		}
+	}
+}
+
+func TestBatchingHandler_Timer(t *testing.T) {
+	rec := &batchRecorder{}
+	hdl := NewBatchingHandler(rec.flush, 100, 10*time.Millisecond, nil)
+	defer hdl.Close()
+	hdl.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "foobar", 0))
+	deadline := time.Now().Add(time.Second)
+	for len(rec.sizes()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if sizes := rec.sizes(); len(sizes) != 1 || sizes[0] != 1 {
+		t.Fatalf("Unexpected batch sizes %v", sizes)
+	}
+}
+
+func TestBatchingHandler_Close(t *testing.T) {
+	rec := &batchRecorder{}
+	hdl := NewBatchingHandler(rec.flush, 10, time.Hour, nil)
+	child := hdl.WithAttrs([]slog.Attr{slog.String("foo", "bar")}).WithGroup("g")
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 25; j++ {
+				child.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "foobar", 0))
+			}
+		}()
+	}
+	wg.Wait()
+	if err := hdl.Close(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if msgs := rec.messages(t); len(msgs) != 250 {
+		t.Fatalf("Expected 250 records but got %d", len(msgs))
+	}
+
+	hdl.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "late", 0))
+	if st := hdl.Stats(); st.Dropped != 1 || st.Emitted != 251 {
+		t.Fatalf("Unexpected stats %+v", st)
+	}
+}
+
+func TestBatchingHandler_Retries(t *testing.T) {
+	var reported []error
+	rec := &batchRecorder{fail: 2}
+	hdl := NewBatchingHandler(rec.flush, 10, time.Hour, &HandlerOptions{
+		FlushRetries: 1,
+		OnError:      func(err error) { reported = append(reported, err) },
+	})
+	hdl.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "lost", 0))
+	if err := hdl.Flush(); err == nil {
+		t.Fatalf("Expected flush to fail")
+	}
+	hdl.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "kept", 0))
+	if err := hdl.Close(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if msgs := rec.messages(t); len(msgs) != 1 || msgs[0] != "kept" {
+		t.Fatalf("Unexpected records %v", msgs)
+	}
+	if len(reported) != 1 || hdl.Stats().Dropped != 1 {
+		t.Fatalf("Expected one reported failure, got %v", reported)
+	}
+}
+
+// blockingFlusher is a flush function blocking until released, or until the delivery context is done.
+type blockingFlusher struct {
+	batchRecorder
+	started chan struct{}
+	release chan struct{}
+}
+
+func newBlockingFlusher() *blockingFlusher {
+	return &blockingFlusher{started: make(chan struct{}, 100), release: make(chan struct{})}
+}
+
+func (f *blockingFlusher) flush(ctx context.Context, batch [][]byte) error {
+	f.started <- struct{}{}
+	select {
+	case <-f.release:
+		return f.batchRecorder.flush(batch)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestBatchingHandler_FullQueueCanceled(t *testing.T) {
+	var reported []error
+	f := newBlockingFlusher()
+	hdl := newBatchingHandler(f.flush, BatchOptions{MaxBatch: 10, MaxPending: 2}, &HandlerOptions{
+		OnError: func(err error) { reported = append(reported, err) },
+	})
+	logger := slog.New(hdl).WithGroup("g")
+	ctx := context.Background()
+	logger.InfoContext(ctx, "1")
+	logger.InfoContext(ctx, "2")
+	<-f.started // The first two records are being delivered.
+	logger.InfoContext(ctx, "3")
+	logger.InfoContext(ctx, "4")
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	logger.InfoContext(canceled, "dropped")
+	if st := hdl.Stats(); st.Dropped != 1 || len(reported) != 1 || !errors.Is(reported[0], ErrQueueFull) {
+		t.Fatalf("Unexpected stats %+v and errors %v", st, reported)
+	}
+
+	close(f.release)
+	if err := hdl.Close(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if msgs := f.messages(t); len(msgs) != 4 || msgs[3] != "4" {
+		t.Fatalf("Unexpected records %v", msgs)
+	}
+}
+
+func TestBatchingHandler_FullQueueWaits(t *testing.T) {
+	f := newBlockingFlusher()
+	hdl := newBatchingHandler(f.flush, BatchOptions{MaxBatch: 1, MaxPending: 1}, nil)
+	ctx := context.Background()
+	hdl.Handle(ctx, slog.NewRecord(now, slog.LevelInfo, "1", 0))
+	<-f.started
+	hdl.Handle(ctx, slog.NewRecord(now, slog.LevelInfo, "2", 0))
+
+	handled := make(chan struct{})
+	go func() {
+		defer close(handled)
+		hdl.Handle(ctx, slog.NewRecord(now, slog.LevelInfo, "3", 0))
+	}()
+	select {
+	case <-handled:
+		t.Fatal("Handle didn't wait for room in the queue")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(f.release)
+	<-handled
+	if err := hdl.Close(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if msgs := f.messages(t); len(msgs) != 3 || hdl.Stats().Dropped != 0 {
+		t.Fatalf("Unexpected records %v", msgs)
+	}
+}
+
+func TestBatchingHandler_ShutdownTimeout(t *testing.T) {
+	t.Run("drained", func(t *testing.T) {
+		f := newBlockingFlusher()
+		close(f.release)
+		hdl := newBatchingHandler(f.flush, BatchOptions{MaxBatch: 10, ShutdownTimeout: time.Second}, nil)
+		for i := 0; i < 25; i++ {
+			hdl.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, strconv.Itoa(i), 0))
+		}
+		if err := hdl.Close(); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if msgs := f.messages(t); len(msgs) != 25 || hdl.Stats().Dropped != 0 {
+			t.Fatalf("Unexpected records %v", msgs)
+		}
+	})
+	t.Run("expired", func(t *testing.T) {
+		var reported []error
+		f := newBlockingFlusher()
+		hdl := newBatchingHandler(f.flush, BatchOptions{MaxBatch: 10, ShutdownTimeout: 20 * time.Millisecond}, &HandlerOptions{
+			OnError: func(err error) { reported = append(reported, err) },
+		})
+		for i := 0; i < 15; i++ {
+			hdl.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, strconv.Itoa(i), 0))
+		}
+		start := time.Now()
+		if err := hdl.Close(); !errors.Is(err, context.Canceled) {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Fatalf("Close took %s", elapsed)
+		}
+		if st := hdl.Stats(); st.Dropped != 15 || len(reported) != 2 {
+			t.Fatalf("Unexpected stats %+v and errors %v", st, reported)
+		}
+	})
+}
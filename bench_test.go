@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"log/slog"
+	"math"
 	"testing"
 	"time"
 
@@ -71,3 +72,157 @@ func BenchmarkLoggers(b *testing.B) {
 	}
 
 }
+
+func BenchmarkHandler_Groups(b *testing.B) {
+	ctx := context.Background()
+	h := NewJsonHandler(io.Discard, nil).WithGroup("a").WithAttrs([]slog.Attr{slog.String("foo", "bar")}).WithGroup("b")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "hello", 0)
+		rec.AddAttrs(slog.String("bar", "baz"), slog.Int("n", i), slog.Group("sub", slog.Int("x", 1), slog.Int("y", 2)))
+		h.Handle(ctx, rec)
+	}
+}
+
+func BenchmarkHandler_SpeculativeGroups(b *testing.B) {
+	ctx := context.Background()
+	for name, h := range map[string]slog.Handler{
+		"no-group": NewJsonHandler(io.Discard, nil),
+		"groups":   NewJsonHandler(io.Discard, nil).WithGroup("req").WithGroup("auth").WithGroup("trace"),
+	} {
+		b.Run(name, func(b *testing.B) {
+			rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "hello", 0)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				h.Handle(ctx, rec)
+			}
+		})
+	}
+}
+
+func BenchmarkHandler_WithAttrsChain(b *testing.B) {
+	ctx := context.Background()
+	for name, group := range map[string]bool{"root": false, "group": true} {
+		b.Run(name, func(b *testing.B) {
+			var root slog.Handler = NewJsonHandler(io.Discard, nil)
+			if group {
+				root = root.WithGroup("g")
+			}
+			rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "hello", 0)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				h := root
+				for j := 0; j < 10; j++ {
+					h = h.WithAttrs([]slog.Attr{slog.String("layer", "middleware"), slog.Int("depth", j)})
+				}
+				h.Handle(ctx, rec)
+			}
+		})
+	}
+}
+
+func BenchmarkHandler_Enabled(b *testing.B) {
+	ctx := context.Background()
+	for name, opts := range map[string]*HandlerOptions{
+		"logger-level": nil,
+		"level":        {Level: slog.LevelInfo},
+		"level-var":    {Level: new(slog.LevelVar)},
+	} {
+		b.Run(name, func(b *testing.B) {
+			h := NewJsonHandler(io.Discard, opts).WithGroup("g")
+			for i := 0; i < b.N; i++ {
+				h.Enabled(ctx, slog.LevelDebug)
+			}
+		})
+	}
+}
+
+func BenchmarkSharedEncoding(b *testing.B) {
+	ctx := context.Background()
+	for name, h := range map[string]slog.Handler{
+		"shared": NewSharedEncodingHandler([]Destination{
+			{Writer: io.Discard, Leveler: slog.LevelDebug}, {Writer: io.Discard}, {Writer: io.Discard, Leveler: slog.LevelWarn},
+		}, nil),
+		"fan-out": &multiHandler{handlers: []slog.Handler{
+			NewJsonHandler(io.Discard, &HandlerOptions{Level: slog.LevelDebug}),
+			NewJsonHandler(io.Discard, nil),
+			NewJsonHandler(io.Discard, &HandlerOptions{Level: slog.LevelWarn}),
+		}},
+	} {
+		b.Run(name, func(b *testing.B) {
+			h = h.WithAttrs([]slog.Attr{slog.String("foo", "bar")}).WithGroup("g")
+			rec := slog.NewRecord(time.Now(), slog.LevelWarn, "hello", 0)
+			rec.AddAttrs(slog.String("bar", "baz"), slog.Int("n", 42), slog.Duration("latency", time.Millisecond))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				h.Handle(ctx, rec)
+			}
+		})
+	}
+}
+
+func BenchmarkQueued_Contention(b *testing.B) {
+	ctx := context.Background()
+	queued := NewQueuedHandler(zerolog.SyncWriter(io.Discard), QueueOptions{}, nil)
+	defer queued.Close()
+	for name, h := range map[string]slog.Handler{
+		"direct": NewJsonHandler(zerolog.SyncWriter(io.Discard), nil),
+		"queued": queued,
+	} {
+		b.Run(name, func(b *testing.B) {
+			rec := slog.NewRecord(time.Now(), slog.LevelInfo, "hello", 0)
+			rec.AddAttrs(slog.String("bar", "baz"), slog.Int("n", 42), slog.Duration("latency", time.Millisecond))
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					h.Handle(ctx, rec)
+				}
+			})
+		})
+	}
+}
+
+// TestHandler_DefaultPathCost guards the cost of Handle with default options,
+// which must stay free of per-record option work: no allocations and no more
+// than 2.5 times the time zerolog takes to write the same record directly.
+func TestHandler_DefaultPathCost(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping timing test in short mode")
+	}
+	ctx := context.Background()
+	now := time.Now()
+	hdl := NewJsonHandler(io.Discard, &HandlerOptions{Level: slog.LevelDebug})
+	if hdl.filtered || hdl.buffersAttrs() {
+		t.Fatalf("default handler filters or buffers records")
+	}
+	h := hdl.WithAttrs([]slog.Attr{slog.String("foo", "bar")})
+	rec := slog.NewRecord(now, slog.LevelInfo, "hello", 0)
+	rec.AddAttrs(slog.String("bar", "baz"))
+	if n := testing.AllocsPerRun(100, func() { h.Handle(ctx, rec) }); n != 0 {
+		t.Fatalf("Handle allocates %v times per record", n)
+	}
+
+	raw := zerolog.New(io.Discard).Level(zerolog.DebugLevel).With().Str("foo", "bar").Logger()
+	bench := func(f func()) int64 {
+		return testing.Benchmark(func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				f()
+			}
+		}).NsPerOp()
+	}
+	// Alternate both measurements and keep the best of each, so that noise
+	// from other processes affects them alike.
+	direct, handled := int64(math.MaxInt64), int64(math.MaxInt64)
+	for i := 0; i < 5; i++ {
+		direct = min(direct, bench(func() { raw.Info().Str("bar", "baz").Time(zerolog.TimestampFieldName, now).Msg("hello") }))
+		handled = min(handled, bench(func() { h.Handle(ctx, rec) }))
+	}
+	if handled*2 > direct*5 {
+		t.Errorf("Handle takes %d ns/op, more than 2.5 times zerolog's %d ns/op", handled, direct)
+	}
+}
@@ -0,0 +1,138 @@
+package zeroslog
+
+import (
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// levelBoost is a temporary level installed with BoostLevel.
+type levelBoost struct {
+	level slog.Level
+	from  time.Time
+	until time.Time
+}
+
+// levelBoosts holds the boosts of a handler, shared with the handlers derived from it.
+type levelBoosts struct {
+	// active are the boosts neither canceled nor removed by a timer, sorted from the last to end. The slice is
+	// replaced on changes, so that handlers read it with a single atomic load, and it's nil without boost.
+	active atomic.Pointer[[]*levelBoost]
+	mu     sync.Mutex
+	// now and afterFunc are replaced by tests. Boosts expire according to now, their timers only
+	// remove them, so that handlers without boost only pay an atomic load.
+	now       func() time.Time
+	afterFunc func(d time.Duration, f func()) (stop func() bool)
+}
+
+// newLevelBoosts creates the boosts of a root handler.
+func newLevelBoosts() *levelBoosts {
+	return &levelBoosts{
+		now:       time.Now,
+		afterFunc: func(d time.Duration, f func()) func() bool { return time.AfterFunc(d, f).Stop },
+	}
+}
+
+// BoostLevel temporarily lowers the level of h, and of the handlers derived from it, to level for d, so that
+// verbosity can be raised without the risk of forgetting to restore it. Records at level or above are written
+// while the boost is active, whatever opts.Level, the level of the wrapped logger, and AttrLevelOverrides.
+// Disabled loggers and zerolog's global level still apply.
+//
+// The boost ends after d, or when the returned cancel function is called, whichever comes first. Calling cancel
+// more than once, or after the boost ended, does nothing. Overlapping boosts are merged: the most verbose level
+// of the boosts overlapping each other applies until the last of them ends, and canceling one of them removes
+// it from the merge. Boosts of a zero Handler, or with d <= 0, do nothing.
+func (h *Handler) BoostLevel(level slog.Level, d time.Duration) (cancel func()) {
+	if h.boosts == nil || d <= 0 {
+		return func() {}
+	}
+	return h.boosts.add(level, d)
+}
+
+// add installs a boost to level for d, and returns the function canceling it.
+func (b *levelBoosts) add(level slog.Level, d time.Duration) func() {
+	now := b.now()
+	boost := &levelBoost{level: level, from: now, until: now.Add(d)}
+	b.update(func(active []*levelBoost) []*levelBoost {
+		i, _ := slices.BinarySearchFunc(active, boost, func(a, t *levelBoost) int { return t.until.Compare(a.until) })
+		return slices.Insert(active, i, boost)
+	})
+	stop := b.afterFunc(d, func() { b.remove(nil) })
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			stop()
+			b.remove(boost)
+		})
+	}
+}
+
+// remove removes boost, if it's still there, and the boosts which no longer apply from the active boosts.
+// Timers call it with a nil boost, since an ended boost still applies while it overlaps one which didn't end.
+func (b *levelBoosts) remove(boost *levelBoost) {
+	now := b.now()
+	b.update(func(active []*levelBoost) []*levelBoost {
+		active = slices.DeleteFunc(active, func(a *levelBoost) bool { return a == boost })
+		return active[:applying(active, now)]
+	})
+}
+
+// applying returns the number of boosts of active, sorted from the last to end, which apply at now: the ones
+// not ended, and the ones overlapping them, directly or through other boosts.
+func applying(active []*levelBoost, now time.Time) int {
+	// Boosts all start before now, so a boost ending after start overlaps the applying ones.
+	start := now
+	for i, boost := range active {
+		if !boost.until.After(start) {
+			return i
+		}
+		if boost.from.Before(start) {
+			start = boost.from
+		}
+	}
+	return len(active)
+}
+
+// update replaces the active boosts with the result of fn, called with a copy of them.
+func (b *levelBoosts) update(fn func(active []*levelBoost) []*levelBoost) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var active []*levelBoost
+	if p := b.active.Load(); p != nil {
+		active = slices.Clone(*p)
+	}
+	if active = fn(active); len(active) == 0 {
+		b.active.Store(nil)
+		return
+	}
+	b.active.Store(&active)
+}
+
+// level returns the most verbose level of the applying boosts, and false if there's none.
+// It is safe to call on nil boosts.
+func (b *levelBoosts) level() (slog.Level, bool) {
+	if b == nil {
+		return 0, false
+	}
+	active := b.active.Load()
+	if active == nil {
+		return 0, false
+	}
+	n := applying(*active, b.now())
+	if n == 0 {
+		return 0, false
+	}
+	lvl := (*active)[0].level
+	for _, boost := range (*active)[1:n] {
+		lvl = min(lvl, boost.level)
+	}
+	return lvl, true
+}
+
+// boostEmits reports whether a boost lets records at lvl be written, ignoring the other levels.
+func (h *Handler) boostEmits(lvl slog.Level) bool {
+	level, ok := h.boosts.level()
+	return ok && ZerologLevel(lvl) >= ZerologLevel(level)
+}
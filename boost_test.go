@@ -0,0 +1,234 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeBoostClock replaces the clock and the timers of the boosts of h. It returns the function
+// advancing the clock by d, which fires the expired timers.
+func fakeBoostClock(h *Handler) (advance func(d time.Duration)) {
+	var (
+		mu     sync.Mutex
+		clock  atomic.Int64
+		timers = map[*time.Time]func(){}
+	)
+	clock.Store(now.UnixNano())
+	h.boosts.now = func() time.Time { return time.Unix(0, clock.Load()) }
+	h.boosts.afterFunc = func(d time.Duration, f func()) func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		at := h.boosts.now().Add(d)
+		timers[&at] = f
+		return func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			_, ok := timers[&at]
+			delete(timers, &at)
+			return ok
+		}
+	}
+	return func(d time.Duration) {
+		clock.Add(int64(d))
+		mu.Lock()
+		var fired []func()
+		for at, f := range timers {
+			if !h.boosts.now().Before(*at) {
+				fired = append(fired, f)
+				delete(timers, at)
+			}
+		}
+		mu.Unlock()
+		for _, f := range fired {
+			f()
+		}
+	}
+}
+
+func TestBoostLevel(t *testing.T) {
+	out := bytes.Buffer{}
+	hdl := NewJsonHandler(&out, &HandlerOptions{Level: slog.LevelInfo})
+	advance := fakeBoostClock(hdl)
+	child := hdl.WithAttrs([]slog.Attr{slog.String("k", "v")}).WithGroup("g")
+	ctx := context.Background()
+
+	if hdl.Enabled(ctx, slog.LevelDebug) {
+		t.Fatal("Expected debug to be disabled before the boost")
+	}
+	cancel := hdl.BoostLevel(slog.LevelDebug, time.Minute)
+	if !hdl.Enabled(ctx, slog.LevelDebug) || !child.Enabled(ctx, slog.LevelDebug) || child.Enabled(ctx, slog.LevelDebug-1) {
+		t.Fatal("Expected the boost to enable debug, and only debug, on the handler and its children")
+	}
+	if lvl := hdl.MinLevel(); lvl != slog.LevelDebug {
+		t.Fatalf("Expected boosted min level debug, got %s", lvl)
+	}
+	slog.New(child).Debug("boosted")
+	records := decodeAll(t, &out)
+	if len(records) != 1 || records[0]["message"] != "boosted" || records[0]["level"] != "debug" {
+		t.Fatalf("Unexpected records %v", records)
+	}
+
+	advance(time.Minute)
+	if hdl.Enabled(ctx, slog.LevelDebug) || hdl.MinLevel() != slog.LevelInfo {
+		t.Fatal("Expected the boost to expire")
+	}
+	if hdl.boosts.active.Load() != nil {
+		t.Fatal("Expected the timer to remove the expired boost")
+	}
+	cancel()
+	cancel()
+	slog.New(child).Debug("dropped")
+	if records := decodeAll(t, &out); len(records) != 0 {
+		t.Fatalf("Unexpected records %v", records)
+	}
+}
+
+func TestBoostLevel_Expiry(t *testing.T) {
+	hdl := NewJsonHandler(&bytes.Buffer{}, &HandlerOptions{Level: slog.LevelInfo})
+	clock := now
+	hdl.boosts.now = func() time.Time { return clock }
+	hdl.boosts.afterFunc = func(time.Duration, func()) func() bool { return func() bool { return true } }
+
+	hdl.BoostLevel(slog.LevelDebug, time.Minute)
+	clock = clock.Add(time.Minute - time.Nanosecond)
+	if !hdl.Enabled(context.Background(), slog.LevelDebug) {
+		t.Fatal("Expected the boost to be active until its end")
+	}
+	// The boost ends with the clock, even if its timer didn't fire yet.
+	clock = clock.Add(time.Nanosecond)
+	if hdl.Enabled(context.Background(), slog.LevelDebug) {
+		t.Fatal("Expected the boost to end at its deadline")
+	}
+}
+
+func TestBoostLevel_Overlap(t *testing.T) {
+	hdl := NewJsonHandler(&bytes.Buffer{}, &HandlerOptions{Level: slog.LevelWarn})
+	advance := fakeBoostClock(hdl)
+	ctx := context.Background()
+
+	hdl.BoostLevel(slog.LevelInfo, 5*time.Minute)
+	hdl.BoostLevel(slog.LevelDebug, time.Minute)
+	if !hdl.Enabled(ctx, slog.LevelDebug) || hdl.MinLevel() != slog.LevelDebug {
+		t.Fatal("Expected the most verbose boost to apply")
+	}
+	advance(time.Minute)
+	if !hdl.Enabled(ctx, slog.LevelDebug) || hdl.MinLevel() != slog.LevelDebug {
+		t.Fatal("Expected the most verbose boost to apply until the longest one ends")
+	}
+	advance(4 * time.Minute)
+	if hdl.Enabled(ctx, slog.LevelInfo) || hdl.boosts.active.Load() != nil {
+		t.Fatal("Expected all the boosts to end")
+	}
+
+	// A boost ended before another one started isn't merged with it.
+	hdl.BoostLevel(slog.LevelDebug, time.Minute)
+	advance(2 * time.Minute)
+	hdl.BoostLevel(slog.LevelInfo, time.Minute)
+	if hdl.Enabled(ctx, slog.LevelDebug) || !hdl.Enabled(ctx, slog.LevelInfo) {
+		t.Fatal("Expected boosts which don't overlap to apply separately")
+	}
+}
+
+func TestBoostLevel_Cancel(t *testing.T) {
+	hdl := NewJsonHandler(&bytes.Buffer{}, &HandlerOptions{Level: slog.LevelWarn})
+	advance := fakeBoostClock(hdl)
+	ctx := context.Background()
+
+	cancelDebug := hdl.BoostLevel(slog.LevelDebug, time.Hour)
+	cancelInfo := hdl.BoostLevel(slog.LevelInfo, time.Hour)
+	cancelDebug()
+	if hdl.Enabled(ctx, slog.LevelDebug) || !hdl.Enabled(ctx, slog.LevelInfo) {
+		t.Fatal("Expected cancel to end its boost only")
+	}
+	cancelDebug()
+	if !hdl.Enabled(ctx, slog.LevelInfo) {
+		t.Fatal("Expected a second cancel to do nothing")
+	}
+	cancelInfo()
+	if hdl.Enabled(ctx, slog.LevelInfo) || hdl.boosts.active.Load() != nil {
+		t.Fatal("Expected all the boosts to be canceled")
+	}
+	// The canceled boosts' timers were stopped.
+	advance(time.Hour)
+
+	if cancel := hdl.BoostLevel(slog.LevelDebug, 0); hdl.Enabled(ctx, slog.LevelDebug) {
+		t.Fatal("Expected a boost without duration to do nothing")
+	} else {
+		cancel()
+	}
+	var zero Handler
+	zero.BoostLevel(slog.LevelDebug, time.Minute)()
+	if zero.Enabled(ctx, slog.LevelError) {
+		t.Fatal("Expected the zero handler to stay disabled")
+	}
+}
+
+func TestBoostLevel_Overrides(t *testing.T) {
+	out := bytes.Buffer{}
+	hdl := NewJsonHandler(&out, &HandlerOptions{
+		Level:              slog.LevelDebug,
+		AttrLevelOverrides: AttrLevelOverrides{Key: "component", Levels: map[string]slog.Leveler{"db": slog.LevelError}},
+	})
+	fakeBoostClock(hdl)
+	logger := slog.New(hdl).With("component", "db")
+
+	logger.Info("dropped")
+	cancel := hdl.BoostLevel(slog.LevelInfo, time.Minute)
+	logger.Info("boosted")
+	logger.Debug("dropped")
+	cancel()
+	logger.Info("dropped")
+	records := decodeAll(t, &out)
+	if len(records) != 1 || records[0]["message"] != "boosted" {
+		t.Fatalf("Unexpected records %v", records)
+	}
+}
+
+func TestBoostLevel_Concurrent(t *testing.T) {
+	out := &syncBuffer{}
+	hdl := NewJsonHandler(out, &HandlerOptions{Level: slog.LevelInfo})
+	advance := fakeBoostClock(hdl)
+	logger := slog.New(hdl.WithGroup("g"))
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					logger.Debug("debug", "k", 1)
+					logger.Info("info")
+				}
+			}
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		cancel := hdl.BoostLevel(slog.LevelDebug, time.Duration(i%3+1)*time.Second)
+		advance(time.Second)
+		if i%2 == 0 {
+			cancel()
+		}
+	}
+	advance(time.Minute)
+	close(stop)
+	wg.Wait()
+
+	if hdl.boosts.active.Load() != nil || hdl.Enabled(context.Background(), slog.LevelDebug) {
+		t.Fatal("Expected all the boosts to end")
+	}
+	for _, rec := range decodeAll(t, &out.buf) {
+		if rec["level"] != "debug" && rec["level"] != "info" {
+			t.Fatalf("Unexpected record %v", rec)
+		}
+	}
+}
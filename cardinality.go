@@ -0,0 +1,105 @@
+package zeroslog
+
+import (
+	"log/slog"
+	"sync"
+)
+
+// OverflowKey is the key of the fields holding the attributes whose key is rewritten because of MaxUniqueKeys.
+const OverflowKey = "overflow_key"
+
+// keyTracker records the distinct keys written by a handler, up to a maximum.
+type keyTracker struct {
+	mu   sync.RWMutex
+	max  int
+	seen map[string]struct{}
+}
+
+// newKeyTracker creates a keyTracker recording up to max keys, or nil if max is not positive.
+func newKeyTracker(max int) *keyTracker {
+	if max <= 0 {
+		return nil
+	}
+	return &keyTracker{max: max, seen: make(map[string]struct{})}
+}
+
+// admit reports whether key was already seen, or can still be recorded, in which case it's recorded.
+func (t *keyTracker) admit(key string) bool {
+	t.mu.RLock()
+	_, ok := t.seen[key]
+	full := len(t.seen) >= t.max
+	t.mu.RUnlock()
+	if ok || full {
+		return ok
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.seen[key]; ok {
+		return true
+	}
+	if len(t.seen) >= t.max {
+		return false
+	}
+	t.seen[key] = struct{}{}
+	return true
+}
+
+// overflowAttrs collects the attributes of one object level whose key is rewritten because of MaxUniqueKeys,
+// so that they're written in a single OverflowKey group after the other attributes, rather than in as many
+// fields with the same key.
+type overflowAttrs []slog.Attr
+
+// add collects the members of a and reports true if a is an OverflowKey group, and reports false otherwise.
+func (o *overflowAttrs) add(a slog.Attr) bool {
+	if a.Key != OverflowKey || a.Value.Kind() != slog.KindGroup {
+		return false
+	}
+	*o = append(*o, a.Value.Group()...)
+	return true
+}
+
+// attr returns the OverflowKey group holding the collected attributes, if any.
+func (o overflowAttrs) attr() (slog.Attr, bool) {
+	if len(o) == 0 {
+		return slog.Attr{}, false
+	}
+	return slog.Attr{Key: OverflowKey, Value: slog.GroupValue(o...)}, true
+}
+
+// cardinalityStage returns the pipeline stage rewriting the attributes whose dot-joined key path is not
+// admitted by keys to an OverflowKey group holding them. Group members are checked once their group is admitted,
+// and the rewritten members of a group are collected into a single OverflowKey member.
+// Rewritten attributes are counted in st.
+func cardinalityStage(keys *keyTracker, st *stats) attrStage {
+	var apply func(prefix string, a slog.Attr) slog.Attr
+	apply = func(prefix string, a slog.Attr) slog.Attr {
+		if a.Key != "" {
+			if !keys.admit(prefix + a.Key) {
+				if st != nil {
+					st.overflowKeys.Add(1)
+				}
+				return slog.Attr{Key: OverflowKey, Value: slog.GroupValue(a)}
+			}
+			prefix = prefix + a.Key + "."
+		}
+		if a.Value.Kind() != slog.KindGroup {
+			return a
+		}
+		group := a.Value.Group()
+		members := make([]slog.Attr, 0, len(group))
+		var over overflowAttrs
+		for _, m := range group {
+			m.Value = m.Value.Resolve()
+			if m = apply(prefix, m); !over.add(m) {
+				members = append(members, m)
+			}
+		}
+		if o, ok := over.attr(); ok {
+			members = append(members, o)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(members...)}
+	}
+	return func(prefix string, a slog.Attr) (slog.Attr, bool) {
+		return apply(prefix, a), true
+	}
+}
@@ -0,0 +1,98 @@
+package zeroslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMaxUniqueKeys(t *testing.T) {
+	out := bytes.Buffer{}
+	hdl := NewJsonHandler(&out, &HandlerOptions{MaxUniqueKeys: 4})
+	logger := slog.New(hdl)
+
+	records := []struct {
+		log func()
+		exp map[string]any
+	}{
+		{
+			log: func() { logger.Info("msg", "user", "bob", slog.Group("req", "id", 1)) },
+			exp: map[string]any{"user": "bob", "req": map[string]any{"id": 1.0}},
+		},
+		{
+			log: func() {
+				logger.Info("msg", "0f8fad5b", 1, "req", slog.GroupValue(slog.Int("id", 2), slog.Int("6ba7b810", 3)))
+			},
+			exp: map[string]any{
+				"0f8fad5b": 1.0,
+				"req":      map[string]any{"id": 2.0, OverflowKey: map[string]any{"6ba7b810": 3.0}},
+			},
+		},
+		{
+			log: func() {
+				logger.Info("msg", "user", "alice", "7c9e6679", slog.GroupValue(slog.Int("id", 4)), "0f8fad5b", 5)
+			},
+			exp: map[string]any{
+				"user":      "alice",
+				OverflowKey: map[string]any{"7c9e6679": map[string]any{"id": 4.0}},
+				"0f8fad5b":  5.0,
+			},
+		},
+	}
+	for _, r := range records {
+		out.Reset()
+		r.log()
+		var m map[string]any
+		if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+			t.Fatal(err)
+		}
+		delete(m, "level")
+		delete(m, "time")
+		delete(m, "message")
+		if !reflect.DeepEqual(m, r.exp) {
+			t.Errorf("Got %v, expected %v", m, r.exp)
+		}
+	}
+	if st := hdl.Stats(); st.OverflowKeys != 2 {
+		t.Errorf("Expected 2 overflowing keys, got %d", st.OverflowKeys)
+	}
+}
+
+func TestMaxUniqueKeys_WithAttrs(t *testing.T) {
+	out := bytes.Buffer{}
+	logger := slog.New(NewJsonHandler(&out, &HandlerOptions{MaxUniqueKeys: 1})).With("a", 1, "b", 2)
+	logger.Info("msg", "a", 3)
+	exp := `{"level":"info","a":1,"overflow_key":{"b":2},"a":3,`
+	if got := out.String(); !strings.HasPrefix(got, exp) {
+		t.Errorf("Got %s, expected %s", got, exp)
+	}
+}
+
+func TestMaxUniqueKeys_SingleOverflow(t *testing.T) {
+	for _, r := range []struct {
+		log func(*slog.Logger)
+		exp string
+	}{
+		{
+			log: func(l *slog.Logger) { l.Info("msg", "a", 1, "u1", 2, "u2", 3) },
+			exp: `"a":1,"overflow_key":{"u1":2,"u2":3},`,
+		},
+		{
+			log: func(l *slog.Logger) { l.Info("msg", slog.Group("a", "u1", 1, "u2", 2), "u3", 3, "u4", 4) },
+			exp: `"a":{"overflow_key":{"u1":1,"u2":2}},"overflow_key":{"u3":3,"u4":4},`,
+		},
+		{
+			log: func(l *slog.Logger) { l.WithGroup("a").Info("msg", "u1", 1, "u2", 2, "u3", 3) },
+			exp: `"a":{"u1":1,"overflow_key":{"u2":2,"u3":3}},`,
+		},
+	} {
+		out := bytes.Buffer{}
+		r.log(slog.New(NewJsonHandler(&out, &HandlerOptions{MaxUniqueKeys: 1})))
+		if got := out.String(); !strings.Contains(got, r.exp) {
+			t.Errorf("Got %s, expected %s", got, r.exp)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package zeroslog
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog"
+)
+
+// ProblemCode identifies a kind of misconfiguration reported by Handler.Check.
+type ProblemCode string
+
+// Problems reported by Handler.Check.
+const (
+	// ProblemNilWriter is reported when the wrapped logger has no writer, like a zero zerolog.Logger,
+	// and silently writes nothing.
+	ProblemNilWriter ProblemCode = "nil_writer"
+	// ProblemDisabledLogger is reported when the wrapped logger is disabled, like the logger returned by
+	// zerolog.Ctx for a context without logger, and writes nothing whatever the handler level.
+	ProblemDisabledLogger ProblemCode = "disabled_logger"
+	// ProblemDuplicateTime is reported when the wrapped logger writes its own timestamp, like with
+	// Timestamp(), so that records get two time fields.
+	ProblemDuplicateTime ProblemCode = "duplicate_time"
+	// ProblemDuplicateCaller is reported when the wrapped logger writes its own caller with AddSource set,
+	// so that records get two caller fields.
+	ProblemDuplicateCaller ProblemCode = "duplicate_caller"
+	// ProblemGlobalLevel is reported when zerolog's global level discards records the handler reports
+	// as enabled.
+	ProblemGlobalLevel ProblemCode = "global_level"
+	// ProblemBlankFieldName is reported when a field written by the handler has an empty name, because
+	// of zerolog's global field names, so that it's silently omitted.
+	ProblemBlankFieldName ProblemCode = "blank_field_name"
+	// ProblemZeroHandler is reported for a zero Handler, which is a no-op handler writing nothing.
+	ProblemZeroHandler ProblemCode = "zero_handler"
+)
+
+// Problem is a misconfiguration of a handler, reported by Handler.Check.
+type Problem struct {
+	Code ProblemCode
+	// Message describes the problem.
+	Message string
+	// Suggestion tells how to fix it.
+	Suggestion string
+}
+
+var _ error = Problem{}
+
+// Error implements error.
+func (p Problem) Error() string {
+	return fmt.Sprintf("%s: %s (%s)", p.Code, p.Message, p.Suggestion)
+}
+
+// Check looks for the common misconfigurations of a handler, and of the logger it wraps, which make it
+// write nothing, or not what's expected, without reporting any error. The logger is probed without writing
+// any record, so that its hooks don't run. Check returns nil if it finds no problem.
+func (h *Handler) Check() []Problem {
+	var problems []Problem
+	report := func(code ProblemCode, suggestion, format string, args ...any) {
+		problems = append(problems, Problem{Code: code, Message: fmt.Sprintf(format, args...), Suggestion: suggestion})
+	}
+
+	if h.isZero() {
+		report(ProblemZeroHandler, "create the handler with NewHandler or one of the shortcut constructors",
+			"the handler is a zero Handler, nothing is written")
+		return problems
+	}
+
+	if h.logger.GetLevel() == zerolog.Disabled {
+		report(ProblemDisabledLogger, "pass a logger created with zerolog.New, and check that zerolog.Ctx finds a logger in the context",
+			"the wrapped logger is disabled, nothing is written")
+	} else if zerolog.GlobalLevel() != zerolog.Disabled {
+		// Records without level are only discarded by a missing writer or a disabled global level.
+		// The event is dropped without being sent, so that nothing is written.
+		probe := h.logger.Level(zerolog.TraceLevel).Sample(nil)
+		if probe.Log() == nil {
+			report(ProblemNilWriter, "create the logger with zerolog.New instead of using a zero zerolog.Logger",
+				"the wrapped logger has no writer, nothing is written")
+		}
+	}
+
+	if len(problems) == 0 {
+		hasTime, hasCaller := probeLoggerFields(h.logger)
+		if hasTime && !h.loggerTime {
+			report(ProblemDuplicateTime, "remove Timestamp() from the logger, or set TrustLoggerTimestamps",
+				"the wrapped logger writes its own %q field, records get two of them", zerolog.TimestampFieldName)
+		}
+		if hasCaller && h.opts.AddSource && !h.loggerCaller {
+			report(ProblemDuplicateCaller, "remove Caller() from the logger, or unset AddSource",
+				"the wrapped logger writes its own %q field, records get two of them", zerolog.CallerFieldName)
+		}
+	}
+
+	minLvl := SlogLevel(h.loggerLevel())
+	if h.opts.Level != nil {
+		minLvl = h.opts.Level.Level()
+	}
+	if global := zerolog.GlobalLevel(); ZerologLevel(minLvl) < global {
+		report(ProblemGlobalLevel, "lower zerolog's global level with zerolog.SetGlobalLevel",
+			"zerolog's global level %s discards records from level %s, which the handler reports as enabled", global, LevelString(minLvl))
+	}
+
+	names := &h.opts.FieldNames
+	for _, field := range []struct {
+		option, name string
+		written      bool
+	}{
+		{"Level", names.level(), !h.opts.OmitLevel},
+		{"Time", names.time(), !h.loggerTime},
+		{"Message", names.message(), true},
+		{"Caller", names.caller(), h.opts.AddSource && !h.loggerCaller},
+	} {
+		if field.written && field.name == "" {
+			report(ProblemBlankFieldName, "set FieldNames."+field.option+", or restore zerolog's global field name",
+				"the %s field has an empty name", field.option)
+		}
+	}
+	return problems
+}
+
+// MustCheck panics if Check finds problems, and returns h otherwise, for use in main, like in
+//
+//	logger := slog.New(zeroslog.NewJsonHandler(os.Stdout, opts).MustCheck())
+func (h *Handler) MustCheck() *Handler {
+	problems := h.Check()
+	if len(problems) == 0 {
+		return h
+	}
+	errs := make([]error, len(problems))
+	for i, p := range problems {
+		errs[i] = p
+	}
+	panic(fmt.Errorf("zeroslog: misconfigured handler: %w", errors.Join(errs...)))
+}
@@ -0,0 +1,88 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func()
+		hdl   func() *Handler
+		exp   []ProblemCode
+	}{
+		{"OK", nil, func() *Handler { return NewJsonHandler(&bytes.Buffer{}, nil) }, nil},
+		{"NilWriter", nil, func() *Handler { return NewHandler(zerolog.Logger{}, nil) }, []ProblemCode{ProblemNilWriter}},
+		{"DisabledLogger", nil, func() *Handler { return NewHandler(*zerolog.Ctx(context.Background()), nil) }, []ProblemCode{ProblemDisabledLogger}},
+		{"DuplicateTime", nil, func() *Handler {
+			return NewHandler(zerolog.New(&bytes.Buffer{}).With().Timestamp().Logger(), nil)
+		}, []ProblemCode{ProblemDuplicateTime}},
+		{"TrustedTime", nil, func() *Handler {
+			return NewHandler(zerolog.New(&bytes.Buffer{}).With().Timestamp().Logger(), &HandlerOptions{TrustLoggerTimestamps: true})
+		}, nil},
+		{"DuplicateCaller", nil, func() *Handler {
+			return NewHandler(zerolog.New(&bytes.Buffer{}).With().Caller().Logger(), &HandlerOptions{AddSource: true})
+		}, []ProblemCode{ProblemDuplicateCaller}},
+		{"GlobalLevel", func() { zerolog.SetGlobalLevel(zerolog.WarnLevel) }, func() *Handler {
+			return NewJsonHandler(&bytes.Buffer{}, &HandlerOptions{Level: slog.LevelDebug})
+		}, []ProblemCode{ProblemGlobalLevel}},
+		{"BlankFieldName", func() { zerolog.MessageFieldName = "" }, func() *Handler {
+			return NewJsonHandler(&bytes.Buffer{}, nil)
+		}, []ProblemCode{ProblemBlankFieldName}},
+		{"BlankFieldName_Overridden", func() { zerolog.MessageFieldName = "" }, func() *Handler {
+			return NewJsonHandler(&bytes.Buffer{}, &HandlerOptions{FieldNames: FieldNames{Message: "msg"}})
+		}, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defer func(lvl zerolog.Level, msg string) {
+				zerolog.SetGlobalLevel(lvl)
+				zerolog.MessageFieldName = msg
+			}(zerolog.GlobalLevel(), zerolog.MessageFieldName)
+			if test.setup != nil {
+				test.setup()
+			}
+			var codes []ProblemCode
+			for _, p := range test.hdl().Check() {
+				codes = append(codes, p.Code)
+				if p.Message == "" || p.Suggestion == "" {
+					t.Errorf("Expected a message and a suggestion, got %+v", p)
+				}
+			}
+			if !slices.Equal(codes, test.exp) {
+				t.Errorf("Expected problems %v, got %v", test.exp, codes)
+			}
+		})
+	}
+}
+
+func TestCheck_Probe(t *testing.T) {
+	out := bytes.Buffer{}
+	hdl := NewHandler(zerolog.New(&out).With().Timestamp().Logger(), nil)
+	hdl.Check()
+	if out.Len() > 0 {
+		t.Errorf("Expected Check to write nothing to the output, got %s", out.String())
+	}
+}
+
+func TestMustCheck(t *testing.T) {
+	hdl := NewJsonHandler(&bytes.Buffer{}, nil)
+	if hdl.MustCheck() != hdl {
+		t.Errorf("Expected MustCheck to return the handler")
+	}
+	defer func() {
+		err, _ := recover().(error)
+		var p Problem
+		if !errors.As(err, &p) || p.Code != ProblemNilWriter {
+			t.Errorf("Expected a panic with a nil writer problem, got %v", err)
+		}
+	}()
+	NewHandler(zerolog.Logger{}, nil).MustCheck()
+}
@@ -0,0 +1,31 @@
+package zeroslog
+
+// DefaultComponentKey is the key of the field holding the component name set with Named, unless
+// HandlerOptions.ComponentKey is set.
+const DefaultComponentKey = "component"
+
+// Named returns a handler writing records like h, with name appended to its component name, slash-joined,
+// like "server/http/router" after Named("server"), Named("http") and Named("router"). The component name
+// is written as a single top-level field, named after HandlerOptions.ComponentKey, in the records handled
+// by the returned handler and the handlers derived from it. Unlike groups, it doesn't nest attributes.
+// An empty name returns h, as does a zero Handler.
+func (h *Handler) Named(name string) *Handler {
+	if name == "" || h.isZero() {
+		return h
+	}
+	h2 := *h
+	if h.component != "" {
+		name = h.component + "/" + name
+	}
+	h2.component = name
+	h2.chain = h.chain.byte(fingerprintComponent).string(name)
+	return &h2
+}
+
+// componentKey returns the key of the component field.
+func (h *Handler) componentKey() string {
+	if h.opts.ComponentKey != "" {
+		return h.opts.ComponentKey
+	}
+	return DefaultComponentKey
+}
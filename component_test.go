@@ -0,0 +1,66 @@
+package zeroslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"testing"
+)
+
+func TestHandler_Named(t *testing.T) {
+	out := bytes.Buffer{}
+	root := NewJsonHandler(&out, nil)
+	server := root.Named("server")
+	router := server.Named("http").Named("").Named("router")
+
+	decode := func() map[string]any {
+		t.Helper()
+		var m map[string]any
+		if err := json.NewDecoder(&out).Decode(&m); err != nil {
+			t.Fatal(err)
+		}
+		delete(m, "time")
+		return m
+	}
+
+	slog.New(router).Info("routed", "path", "/")
+	if exp := map[string]any{"level": "info", "message": "routed", "component": "server/http/router", "path": "/"}; !reflect.DeepEqual(decode(), exp) {
+		t.Errorf("Expected %v", exp)
+	}
+
+	slog.New(router).With("req", 1).WithGroup("g").Info("grouped", "a", 1)
+	if exp := map[string]any{
+		"level": "info", "message": "grouped", "component": "server/http/router", "req": 1.0, "g": map[string]any{"a": 1.0},
+	}; !reflect.DeepEqual(decode(), exp) {
+		t.Errorf("Expected %v", exp)
+	}
+
+	slog.New(server).Info("parent")
+	if m := decode(); m["component"] != "server" {
+		t.Errorf("Unexpected component in %v", m)
+	}
+	slog.New(root).Info("root")
+	if m := decode(); m["component"] != nil {
+		t.Errorf("Unexpected component in %v", m)
+	}
+
+	if root.Fingerprint() == server.Fingerprint() || server.Fingerprint() == router.Fingerprint() {
+		t.Error("Named handlers must have different fingerprints")
+	}
+	if _, ok := router.Schema()["component"]; !ok {
+		t.Errorf("Missing component in schema %v", router.Schema())
+	}
+}
+
+func TestHandler_Named_ComponentKey(t *testing.T) {
+	out := bytes.Buffer{}
+	slog.New(NewJsonHandler(&out, &HandlerOptions{ComponentKey: "logger"}).Named("db")).Info("msg")
+	var m map[string]any
+	if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["logger"] != "db" || m["component"] != nil {
+		t.Errorf("Unexpected record %s", out.String())
+	}
+}
@@ -0,0 +1,214 @@
+package zeroslog
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// Codec creates the compressed streams written by NewCompressedFileHandler.
+type Codec interface {
+	// Extension is appended to the names of the files, like ".gz".
+	Extension() string
+	// NewWriter returns a writer compressing data into w. Closing it must finish the stream
+	// without closing w. Streams are concatenated in a file, so the format must support it,
+	// as gzip and zstd do.
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+}
+
+// GzipCodec returns a Codec compressing files with gzip, at one of the levels defined in compress/gzip.
+func GzipCodec(level int) Codec {
+	return gzipCodec(level)
+}
+
+// gzipCodec is a Codec using compress/gzip at the given level.
+type gzipCodec int
+
+// Extension implements Codec.
+func (c gzipCodec) Extension() string {
+	return ".gz"
+}
+
+// NewWriter implements Codec.
+func (c gzipCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriterLevel(w, int(c))
+}
+
+// CompressedFileHandler is a Handler writing records into compressed files.
+type CompressedFileHandler struct {
+	*Handler
+	writer *compressedWriter
+}
+
+// NewCompressedFileHandler creates a handler writing records as JSON lines into the file path followed
+// by the extension of codec, compressed by codec.
+//
+// Once the uncompressed records written to the file exceed rotateBytes, the file is completed and renamed by
+// inserting the first unused number, starting at 1, before the extension, like app.log.1.gz, and a new file
+// is started. Records are never split across files: a record larger than rotateBytes is written alone.
+// A file left by a previous process is rotated the same way when the handler is created.
+// A rotateBytes lower than 1 disables rotation.
+//
+// Rotation failures, like a failed rename, are reported to opts.OnError, and records are still written to path,
+// in a new compressed stream appended to the file if it couldn't be renamed.
+//
+// Flush completes the current compressed stream, so that the file is a valid archive, and following records
+// are written into a new stream appended to it. Close must be called to complete the last file.
+//
+// As with NewJsonHandler, records below zerolog.InfoLevel are discarded unless opts.Level is set.
+func NewCompressedFileHandler(path string, codec Codec, rotateBytes int64, opts *HandlerOptions) (*CompressedFileHandler, error) {
+	w := &compressedWriter{path: path + codec.Extension(), codec: codec, rotateBytes: rotateBytes, rename: os.Rename}
+	if info, err := os.Stat(w.path); err == nil && info.Size() > 0 {
+		if err := w.rotate(); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	h := NewHandler(zerolog.New(nil).Level(shortcutLevel(opts)), opts)
+	w.onError = h.reportError
+	h.setOutput(w)
+	return &CompressedFileHandler{Handler: h, writer: w}, nil
+}
+
+// Flush completes the current compressed stream, and syncs the file to disk.
+func (h *CompressedFileHandler) Flush() error {
+	return h.writer.Flush()
+}
+
+// Close completes and closes the current file. Records handled after Close are dropped.
+func (h *CompressedFileHandler) Close() error {
+	return errors.Join(h.Handler.Close(), h.writer.Close())
+}
+
+// compressedWriter is an io.Writer compressing records into size-rotated files.
+type compressedWriter struct {
+	path        string
+	codec       Codec
+	rotateBytes int64
+	// onError is called with rotation failures.
+	onError func(error)
+	// rename renames rotated files, and is replaced in tests.
+	rename func(oldpath, newpath string) error
+
+	mu sync.Mutex
+	// file is nil if it couldn't be reopened after being closed for rotation.
+	file *os.File
+	// stream is the current compressed stream, nil until a record is written after a flush.
+	stream io.WriteCloser
+	// size is the uncompressed size of the records written to the file.
+	size int64
+	// last is the number the file was last renamed with, the next rotation looks for the following unused one.
+	last   int
+	closed bool
+}
+
+// Write implements io.Writer. p is a single record.
+func (w *compressedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, ErrClosed
+	}
+	if w.rotateBytes > 0 && w.size > 0 && w.size+int64(len(p)) > w.rotateBytes {
+		if err := errors.Join(w.finish(), w.rotate()); err != nil {
+			// Keep writing to path rather than losing records.
+			w.onError(err)
+		}
+	}
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+	if w.stream == nil {
+		stream, err := w.codec.NewWriter(w.file)
+		if err != nil {
+			return 0, err
+		}
+		w.stream = stream
+	}
+	n, err := w.stream.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// open opens the current file for appending, creating it if needed. The file only exists if it couldn't
+// be renamed, and then holds completed streams, which new ones are appended to.
+func (w *compressedWriter) open() error {
+	f, err := os.OpenFile(w.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	w.file = f
+	w.size = 0
+	return nil
+}
+
+// finish completes the current stream, if any, and syncs and closes the file.
+func (w *compressedWriter) finish() error {
+	err := w.closeStream()
+	if w.file != nil {
+		err = errors.Join(err, w.file.Sync(), w.file.Close())
+		w.file = nil
+	}
+	return err
+}
+
+// closeStream completes the current stream, if any.
+func (w *compressedWriter) closeStream() error {
+	if w.stream == nil {
+		return nil
+	}
+	err := w.stream.Close()
+	w.stream = nil
+	return err
+}
+
+// rotate renames the current file, which must be closed, with the first unused number.
+func (w *compressedWriter) rotate() error {
+	ext := w.codec.Extension()
+	base := w.path[:len(w.path)-len(ext)]
+	for n := w.last + 1; ; n++ {
+		name := fmt.Sprintf("%s.%d%s", base, n, ext)
+		if _, err := os.Stat(name); errors.Is(err, os.ErrNotExist) {
+			if err := w.rename(w.path, name); err != nil {
+				return err
+			}
+			w.last = n
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// Flush completes the current stream, and syncs the file.
+func (w *compressedWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	if err := w.closeStream(); err != nil || w.file == nil {
+		return err
+	}
+	return w.file.Sync()
+}
+
+// Close completes the current stream, and closes the file.
+func (w *compressedWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.finish()
+}
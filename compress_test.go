@@ -0,0 +1,150 @@
+package zeroslog
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// readGzipLines decompresses the file name, and returns its lines.
+func readGzipLines(t *testing.T, name string) []string {
+	t.Helper()
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	scanner := bufio.NewScanner(zr)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Invalid archive %s: %v", name, err)
+	}
+	return lines
+}
+
+func TestCompressedFileHandler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	h, err := NewCompressedFileHandler(path, GzipCodec(gzip.DefaultCompression), 2048, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := slog.New(h)
+	for i := 0; i < 20; i++ {
+		l.Info("hello", "i", i, "padding", strings.Repeat("x", 50))
+	}
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if files, _ := filepath.Glob(path + "*"); len(files) != 2 {
+		t.Fatalf("Expected 2 files, got %q", files)
+	}
+	rotated := readGzipLines(t, path+".1.gz")
+	current := readGzipLines(t, path+".gz")
+	if len(rotated) == 0 || len(current) == 0 {
+		t.Fatalf("Expected records in both files, got %d and %d", len(rotated), len(current))
+	}
+	if size := len(strings.Join(rotated, "\n")) + 1; size > 2048 {
+		t.Errorf("Expected the rotated file to hold at most 2048 bytes of records, got %d", size)
+	}
+	lines := append(rotated, current...)
+	if len(lines) != 20 {
+		t.Fatalf("Expected 20 records, got %d", len(lines))
+	}
+	for i, line := range lines {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("Invalid record %q: %v", line, err)
+		}
+		if m["i"] != float64(i) {
+			t.Errorf("Expected record %d, got %v", i, m["i"])
+		}
+	}
+}
+
+func TestCompressedFileHandler_Flush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	h, err := NewCompressedFileHandler(path, GzipCodec(gzip.BestSpeed), 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := slog.New(h)
+	l.Info("first")
+	if err := h.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if lines := readGzipLines(t, path+".gz"); len(lines) != 1 {
+		t.Errorf("Expected a valid archive with 1 record after Flush, got %q", lines)
+	}
+	l.Info("second")
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if lines := readGzipLines(t, path+".gz"); len(lines) != 2 {
+		t.Errorf("Expected 2 records after Close, got %q", lines)
+	}
+}
+
+func TestCompressedFileHandler_Existing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	for i := 0; i < 2; i++ {
+		h, err := NewCompressedFileHandler(path, GzipCodec(gzip.DefaultCompression), 0, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		slog.New(h).Info("hello", "run", i)
+		if err := h.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if lines := readGzipLines(t, path+".1.gz"); len(lines) != 1 || !strings.Contains(lines[0], `"run":0`) {
+		t.Errorf("Expected the first run in the rotated file, got %q", lines)
+	}
+	if lines := readGzipLines(t, path+".gz"); len(lines) != 1 || !strings.Contains(lines[0], `"run":1`) {
+		t.Errorf("Expected the second run in the current file, got %q", lines)
+	}
+}
+
+func TestCompressedFileHandler_RenameFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	var reported []error
+	h, err := NewCompressedFileHandler(path, GzipCodec(gzip.BestSpeed), 1, &HandlerOptions{OnError: func(err error) { reported = append(reported, err) }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	errRename := errors.New("rename failed")
+	h.writer.rename = func(string, string) error { return errRename }
+	l := slog.New(h)
+	l.Info("first")
+	l.Info("second")
+	h.writer.rename = os.Rename
+	l.Info("third")
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(reported) != 1 || !errors.Is(reported[0], errRename) {
+		t.Errorf("Expected the rename failure to be reported once, got %v", reported)
+	}
+	if n := h.Stats().Errors; n != 1 {
+		t.Errorf("Expected 1 error in Stats, got %d", n)
+	}
+	if lines := readGzipLines(t, path+".1.gz"); len(lines) != 2 {
+		t.Errorf("Expected the first 2 records in the rotated file, got %q", lines)
+	}
+	if lines := readGzipLines(t, path+".gz"); len(lines) != 1 || !strings.Contains(lines[0], "third") {
+		t.Errorf("Expected the third record in the current file, got %q", lines)
+	}
+}
@@ -0,0 +1,242 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// conformanceConstructor creates a handler writing to out, and decodes one of its output lines into
+// the level, message and fields of the record.
+type conformanceConstructor struct {
+	new    func(out io.Writer, opts *HandlerOptions) slog.Handler
+	decode func(t *testing.T, line string) (level, msg string, fields map[string]any)
+}
+
+var conformanceConstructors = map[string]conformanceConstructor{
+	"json": {
+		new:    func(out io.Writer, opts *HandlerOptions) slog.Handler { return NewJsonHandler(out, opts) },
+		decode: decodeJSONLine,
+	},
+	"console": {
+		new:    func(out io.Writer, opts *HandlerOptions) slog.Handler { return NewConsoleHandler(out, opts) },
+		decode: decodeConsoleLine,
+	},
+}
+
+// conformanceCase logs one record with attrs, through a handler created with opts and derived with derive.
+// exp are the expected fields of the record, besides the level and message.
+type conformanceCase struct {
+	opts   *HandlerOptions
+	derive func(slog.Handler) slog.Handler
+	attrs  []slog.Attr
+	exp    string
+}
+
+var conformanceCases = map[string]conformanceCase{
+	"kinds": {
+		attrs: []slog.Attr{
+			slog.String("str", "a b"), slog.Int("int", -1), slog.Uint64("uint", 2), slog.Float64("float", 1.5),
+			slog.Bool("bool", true), slog.Duration("dur", 2*time.Millisecond), slog.Time("at", now),
+			slog.Any("err", io.EOF), slog.Any("ip", net.IPv4(127, 0, 0, 1)), slog.Any("list", []int{1, 2}),
+		},
+		exp: `{"str":"a b","int":-1,"uint":2,"float":1.5,"bool":true,"dur":2,"at":"` + now.Format(time.RFC3339) + `",
+			"err":"EOF","ip":"127.0.0.1","list":[1,2]}`,
+	},
+	"groups": {
+		attrs: []slog.Attr{slog.Group("g", slog.Int("a", 1), slog.Group("h", slog.String("b", "c")))},
+		exp:   `{"g":{"a":1,"h":{"b":"c"}}}`,
+	},
+	"log-valuer": {
+		attrs: []slog.Attr{slog.Any("lazy", Lazy(func() slog.Value { return slog.GroupValue(slog.Int("a", 1)) }))},
+		exp:   `{"lazy":{"a":1}}`,
+	},
+	"with-attrs": {
+		derive: func(h slog.Handler) slog.Handler { return h.WithAttrs([]slog.Attr{slog.Int("a", 1)}) },
+		attrs:  []slog.Attr{slog.Int("b", 2)},
+		exp:    `{"a":1,"b":2}`,
+	},
+	"with-group": {
+		derive: func(h slog.Handler) slog.Handler {
+			return h.WithAttrs([]slog.Attr{slog.Int("a", 1)}).WithGroup("g").WithAttrs([]slog.Attr{slog.Int("b", 2)}).WithGroup("h")
+		},
+		attrs: []slog.Attr{slog.Int("c", 3)},
+		exp:   `{"a":1,"g":{"b":2,"h":{"c":3}}}`,
+	},
+	"allow-keys": {
+		opts: &HandlerOptions{AllowKeys: []string{"a", "g.b*"}},
+		derive: func(h slog.Handler) slog.Handler {
+			return h.WithAttrs([]slog.Attr{slog.Int("a", 1), slog.Int("x", 0)}).WithGroup("g")
+		},
+		attrs: []slog.Attr{slog.Int("b1", 2), slog.Int("c", 3), slog.Group("b2", slog.Int("d", 4))},
+		exp:   `{"a":1,"g":{"b1":2,"b2":{"d":4}}}`,
+	},
+	"unit-coercion": {
+		opts:  &HandlerOptions{UnitCoercion: map[string]Unit{"_ms": UnitMilliseconds, "_bytes": UnitBytes}},
+		attrs: []slog.Attr{slog.Duration("latency_ms", 1500*time.Microsecond), slog.Group("g", slog.Float64("size_bytes", 1.6))},
+		exp:   `{"latency_ms":1.5,"g":{"size_bytes":2}}`,
+	},
+	"intern-strings": {
+		opts:  &HandlerOptions{InternStrings: 2},
+		attrs: []slog.Attr{slog.Any("status", status(1)), slog.Group("g", slog.Any("status", status(2)))},
+		exp:   `{"status":"status-1","g":{"status":"status-2"}}`,
+	},
+	"reserved-rename": {
+		opts:  &HandlerOptions{ReservedKeyPolicy: ReservedKeyRename},
+		attrs: []slog.Attr{slog.String("message", "m"), slog.Group("g", slog.String("message", "n"))},
+		exp:   `{"field_message":"m","g":{"message":"n"}}`,
+	},
+	"reserved-drop": {
+		opts:  &HandlerOptions{ReservedKeyPolicy: ReservedKeyDrop},
+		attrs: []slog.Attr{slog.String("level", "l"), slog.Int("a", 1)},
+		exp:   `{"a":1}`,
+	},
+	"audit": {
+		opts:  &HandlerOptions{AuditKeys: []string{"actor", "target"}},
+		attrs: []slog.Attr{slog.Bool("audit", true), slog.String("actor", "bob")},
+		exp:   `{"audit":true,"actor":"bob","audit_incomplete":["target"]}`,
+	},
+}
+
+func init() {
+	for name, tc := range strictCases {
+		conformanceCases["strict-"+name] = conformanceCase{
+			opts:   &HandlerOptions{StrictSlogCompliance: true},
+			derive: tc.derive,
+			attrs:  tc.attrs,
+			exp:    tc.exp,
+		}
+	}
+}
+
+// TestConformance runs all the conformance cases against all the constructors,
+// which must write semantically equivalent records.
+func TestConformance(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	for cname, c := range conformanceConstructors {
+		for name, tc := range conformanceCases {
+			t.Run(cname+"/"+name, func(t *testing.T) {
+				out := bytes.Buffer{}
+				var h slog.Handler = c.new(&out, tc.opts)
+				if tc.derive != nil {
+					h = tc.derive(h)
+				}
+				rec := slog.NewRecord(time.Time{}, slog.LevelWarn, "hello", 0)
+				rec.AddAttrs(tc.attrs...)
+				if err := h.Handle(context.Background(), rec); err != nil {
+					t.Fatalf("Unexpected error: %s", err)
+				}
+				level, msg, fields := c.decode(t, strings.TrimSuffix(out.String(), "\n"))
+				if level != zerolog.WarnLevel.String() || msg != "hello" {
+					t.Fatalf("Unexpected level %q and message %q in %s", level, msg, out.String())
+				}
+				exp := map[string]any{}
+				dec := json.NewDecoder(strings.NewReader(tc.exp))
+				dec.UseNumber()
+				if err := dec.Decode(&exp); err != nil {
+					t.Fatalf("Invalid expected fields: %s", err)
+				}
+				got, want := fmt.Sprint(canonicalValue(fields)), fmt.Sprint(canonicalValue(exp))
+				if got != want {
+					t.Fatalf("Unexpected fields %s, expected %s, in %s", got, want, out.String())
+				}
+			})
+		}
+	}
+}
+
+// canonicalValue converts scalar values to their text, since text formats like the console one
+// don't tell strings from other values.
+func canonicalValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, val := range v {
+			m[k] = canonicalValue(val)
+		}
+		return m
+	case []any:
+		s := make([]any, len(v))
+		for i, val := range v {
+			s[i] = canonicalValue(val)
+		}
+		return s
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// decodeJSONLine decodes a JSON record.
+func decodeJSONLine(t *testing.T, line string) (string, string, map[string]any) {
+	fields := map[string]any{}
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.UseNumber()
+	if err := dec.Decode(&fields); err != nil {
+		t.Fatalf("Failed to json decode log output %q: %s", line, err)
+	}
+	level, _ := fields[zerolog.LevelFieldName].(string)
+	msg, _ := fields[zerolog.MessageFieldName].(string)
+	delete(fields, zerolog.LevelFieldName)
+	delete(fields, zerolog.MessageFieldName)
+	return level, msg, fields
+}
+
+// decodeConsoleLine decodes an uncolored console record without time, made of the level, a single word message,
+// and key=value fields, whose values are quoted strings, JSON values, or bare strings.
+func decodeConsoleLine(t *testing.T, line string) (string, string, map[string]any) {
+	parts := strings.SplitN(line, " ", 4)
+	if len(parts) < 3 || parts[0] != "<nil>" {
+		t.Fatalf("Unexpected console output %q", line)
+	}
+	level := parts[1]
+	for lvl, formatted := range zerolog.FormattedLevels {
+		if formatted == parts[1] {
+			level = lvl.String()
+		}
+	}
+	fields := map[string]any{}
+	rest := ""
+	if len(parts) == 4 {
+		rest = parts[3]
+	}
+	for rest != "" {
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			t.Fatalf("Missing field value in %q", line)
+		}
+		switch {
+		case strings.HasPrefix(value, `"`):
+			quoted, err := strconv.QuotedPrefix(value)
+			if err != nil {
+				t.Fatalf("Invalid quoted value in %q: %s", line, err)
+			}
+			fields[key], _ = strconv.Unquote(quoted)
+			rest = value[len(quoted):]
+		case strings.HasPrefix(value, "{"), strings.HasPrefix(value, "["):
+			dec := json.NewDecoder(strings.NewReader(value))
+			dec.UseNumber()
+			var v any
+			if err := dec.Decode(&v); err != nil {
+				t.Fatalf("Invalid JSON value in %q: %s", line, err)
+			}
+			fields[key] = v
+			rest = value[dec.InputOffset():]
+		default:
+			bare, after, _ := strings.Cut(value, " ")
+			fields[key] = bare
+			rest = " " + after
+		}
+		rest = strings.TrimPrefix(rest, " ")
+	}
+	return level, parts[2], fields
+}
@@ -0,0 +1,38 @@
+package zeroslog
+
+import (
+	"bytes"
+	"io"
+)
+
+// lineWriter buffers writes until they end with a newline, so that out receives whole lines in
+// single Write calls. It's not safe for concurrent use: console handlers serialize the calls to
+// the ConsoleWriter writing to it.
+type lineWriter struct {
+	out io.Writer
+	buf []byte
+}
+
+// Write implements io.Writer.
+func (w *lineWriter) Write(p []byte) (int, error) {
+	if len(w.buf) == 0 && bytes.HasSuffix(p, []byte{'\n'}) {
+		return w.flush(p, len(p))
+	}
+	w.buf = append(w.buf, p...)
+	i := bytes.LastIndexByte(w.buf, '\n')
+	if i < 0 {
+		return len(p), nil
+	}
+	n, err := w.flush(w.buf[:i+1], len(p))
+	w.buf = append(w.buf[:0], w.buf[i+1:]...)
+	return n, err
+}
+
+// flush writes lines to out, and returns n, the length of the data written by the caller, unless
+// the write failed.
+func (w *lineWriter) flush(lines []byte, n int) (int, error) {
+	if _, err := w.out.Write(lines); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
@@ -0,0 +1,79 @@
+package zeroslog
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"sync"
+	"testing"
+)
+
+// lineCheckWriter fails the test if a Write call is not made of a single whole line.
+type lineCheckWriter struct {
+	t   *testing.T
+	out io.Writer
+}
+
+func (w lineCheckWriter) Write(p []byte) (int, error) {
+	if bytes.IndexByte(p, '\n') != len(p)-1 {
+		w.t.Errorf("Partial write %q", p)
+	}
+	return w.out.Write(p)
+}
+
+func TestConsoleHandler_Concurrent(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	const goroutines, records = 50, 100
+	r, w := io.Pipe()
+	logger := slog.New(NewConsoleHandler(lineCheckWriter{t, w}, nil)).With("service", "api").WithGroup("req")
+
+	lines := make(chan int)
+	go func() {
+		line := regexp.MustCompile(`^\d{4}-\d\d-\d\d \d\d:\d\d:\d\d INF hello world req=\{"g":\d+,"i":\d+,"text":"some text to log"\} service=api$`)
+		n := 0
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			if !line.MatchString(scanner.Text()) {
+				t.Errorf("Unexpected line %q", scanner.Text())
+			}
+			n++
+		}
+		lines <- n
+	}()
+
+	wg := sync.WaitGroup{}
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < records; i++ {
+				logger.Info("hello world", "g", g, "i", i, "text", "some text to log")
+			}
+		}(g)
+	}
+	wg.Wait()
+	w.Close()
+	if n := <-lines; n != goroutines*records {
+		t.Errorf("Expected %d lines, got %d", goroutines*records, n)
+	}
+}
+
+func TestLineWriter(t *testing.T) {
+	out := bytes.Buffer{}
+	w := &lineWriter{out: lineCheckWriter{t, &out}}
+	for _, part := range []string{"a", "b", "c\n", "d\n", "e"} {
+		if n, err := w.Write([]byte(part)); n != len(part) || err != nil {
+			t.Fatalf("Write(%q) = %d, %v", part, n, err)
+		}
+	}
+	if out.String() != "abc\nd\n" {
+		t.Errorf("Unexpected output %q", out.String())
+	}
+	fmt.Fprint(w, "f\n")
+	if out.String() != "abc\nd\nef\n" {
+		t.Errorf("Unexpected output %q", out.String())
+	}
+}
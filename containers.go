@@ -0,0 +1,141 @@
+package zeroslog
+
+import (
+	"bytes"
+	"log/slog"
+	"slices"
+
+	"github.com/rs/zerolog"
+)
+
+// DefaultMaxAttrDepth is the default value of HandlerOptions.MaxAttrDepth.
+const DefaultMaxAttrDepth = 100
+
+// DefaultMaxAttrWidth is the default value of HandlerOptions.MaxAttrWidth.
+const DefaultMaxAttrWidth = 10_000
+
+// TruncatedValue is written instead of the groups and containers nested deeper than HandlerOptions.MaxAttrDepth.
+// It's also the key of the number of members omitted from groups and maps with more than
+// HandlerOptions.MaxAttrWidth members, and the last element of longer slices.
+const TruncatedValue = "!TRUNCATED"
+
+// attrLimits bounds the groups and containers written in attribute values. Zero limits are disabled.
+type attrLimits struct {
+	// depth is the maximum number of groups or containers enclosing each other.
+	depth int
+	// width is the maximum number of members written for a group or container.
+	width int
+}
+
+// attrLimits returns the limits set with MaxAttrDepth and MaxAttrWidth.
+func (o *HandlerOptions) attrLimits() attrLimits {
+	l := attrLimits{depth: DefaultMaxAttrDepth, width: DefaultMaxAttrWidth}
+	if o.MaxAttrDepth != 0 {
+		l.depth = max(o.MaxAttrDepth, 0)
+	}
+	if o.MaxAttrWidth != 0 {
+		l.width = max(o.MaxAttrWidth, 0)
+	}
+	return l
+}
+
+// tooDeep reports whether a group or container enclosed in depth groups or containers must be truncated.
+func (l attrLimits) tooDeep(depth int) bool {
+	return l.depth > 0 && depth >= l.depth
+}
+
+// truncated returns the number of members written of a group or container of n members, and the number of
+// omitted ones.
+func (l attrLimits) truncated(n int) (int, int) {
+	if l.width > 0 && n > l.width {
+		return l.width, n - l.width
+	}
+	return n, 0
+}
+
+// groupJSON marshals group members as a JSON object, the same way handlers write groups.
+type groupJSON struct {
+	attrs  []slog.Attr
+	limits attrLimits
+	// depth is the number of groups or containers enclosing the members.
+	depth int
+}
+
+// MarshalJSON implements json.Marshaler.
+func (g groupJSON) MarshalJSON() ([]byte, error) {
+	buf := bytes.Buffer{}
+	l := zerolog.New(&buf)
+	mapGroupDepth(l.Log(), g.limits, g.depth, g.attrs).Send()
+	return bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}), nil
+}
+
+// jsonValue returns v with the slog values it contains, directly or in slices and maps, converted
+// so that encoding/json writes them like handlers do: groups as objects and LogValuers resolved.
+// Other values are returned unchanged. depth is the number of groups or containers enclosing v,
+// and groups and containers are truncated with lim, like with mapAttrDepth.
+func jsonValue(v any, lim attrLimits, depth int) any {
+	switch v := v.(type) {
+	case slog.LogValuer:
+		return jsonValue(slog.AnyValue(v).Resolve(), lim, depth)
+	case slog.Value:
+		v = v.Resolve()
+		switch v.Kind() {
+		case slog.KindGroup:
+			return jsonValue(v.Group(), lim, depth)
+		case slog.KindAny:
+			return jsonValue(v.Any(), lim, depth)
+		default:
+			return v.Any()
+		}
+	case []slog.Attr:
+		if lim.tooDeep(depth) {
+			return TruncatedValue
+		}
+		return groupJSON{attrs: v, limits: lim, depth: depth + 1}
+	case []slog.Value:
+		return jsonSlice(v, lim, depth)
+	case []any:
+		return jsonSlice(v, lim, depth)
+	case map[string]any:
+		if lim.tooDeep(depth) {
+			return TruncatedValue
+		}
+		n, omitted := lim.truncated(len(v))
+		values := make(map[string]any, n+1)
+		if omitted == 0 {
+			for k, e := range v {
+				values[k] = jsonValue(e, lim, depth+1)
+			}
+			return values
+		}
+		// Keys are written sorted, the first ones are kept.
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys[:n] {
+			values[k] = jsonValue(v[k], lim, depth+1)
+		}
+		values[TruncatedValue] = omitted
+		return values
+	default:
+		return v
+	}
+}
+
+// jsonSlice returns the elements of v converted with jsonValue, for a slice enclosed in depth groups or containers.
+func jsonSlice[E any](v []E, lim attrLimits, depth int) any {
+	if lim.tooDeep(depth) {
+		return TruncatedValue
+	}
+	n, omitted := lim.truncated(len(v))
+	values := make([]any, n, n+1)
+	for i, e := range v[:n] {
+		values[i] = jsonValue(e, lim, depth+1)
+	}
+	if omitted > 0 {
+		values = append(values, TruncatedValue)
+	}
+	return values
+}
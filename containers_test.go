@@ -0,0 +1,55 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+type groupValuer struct {
+	attrs []slog.Attr
+}
+
+func (v groupValuer) LogValue() slog.Value {
+	return slog.GroupValue(v.attrs...)
+}
+
+func TestGroupValuesInContainers(t *testing.T) {
+	inner := groupValuer{[]slog.Attr{slog.Int("y", 2)}}
+	outer := groupValuer{[]slog.Attr{slog.Int("x", 1), slog.Any("inner", inner)}}
+	for name, tc := range map[string]struct {
+		attr slog.Attr
+		exp  string
+	}{
+		"nested-groups": {
+			attr: slog.Group("a", slog.Group("b", slog.Any("v", outer))),
+			exp:  `"a":{"b":{"v":{"x":1,"inner":{"y":2}}}}`,
+		},
+		"slice": {
+			attr: slog.Any("list", []any{outer, slog.GroupValue(slog.Int("z", 3)), 4, []slog.Attr{slog.String("k", "v")}}),
+			exp:  `"list":[{"x":1,"inner":{"y":2}},{"z":3},4,{"k":"v"}]`,
+		},
+		"values": {
+			attr: slog.Any("values", []slog.Value{slog.AnyValue(inner), slog.StringValue("s")}),
+			exp:  `"values":[{"y":2},"s"]`,
+		},
+		"map": {
+			attr: slog.Any("map", map[string]any{"g": []any{outer}}),
+			exp:  `"map":{"g":[{"x":1,"inner":{"y":2}}]}`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			out := bytes.Buffer{}
+			rec := slog.NewRecord(now, slog.LevelInfo, "hello", 0)
+			rec.AddAttrs(tc.attr)
+			if err := NewJsonHandler(&out, nil).WithGroup("g").Handle(context.Background(), rec); err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if !strings.Contains(out.String(), `"g":{`+tc.exp+`}`) {
+				t.Fatalf("Unexpected output %s, expected %s", out.String(), tc.exp)
+			}
+		})
+	}
+}
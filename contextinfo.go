@@ -0,0 +1,35 @@
+package zeroslog
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// ContextDeadlineKey is the key of the field holding the milliseconds left before the deadline of the
+	// context of a record, written with AddContextInfo.
+	ContextDeadlineKey = "ctx_deadline_ms"
+	// ContextCanceledKey is the key of the field telling whether the context of a record was done,
+	// written with AddContextInfo.
+	ContextCanceledKey = "ctx_canceled"
+)
+
+// writeContextInfo writes the context fields of AddContextInfo into evt, for a record logged at t.
+// The deadline is compared to t rather than to the current time, unless t is zero.
+func writeContextInfo(evt *zerolog.Event, ctx context.Context, t time.Time) {
+	canceled := ctx.Err() != nil
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		if canceled {
+			evt.Bool(ContextCanceledKey, true)
+		}
+		return
+	}
+	if t.IsZero() {
+		t = time.Now()
+	}
+	evt.Int64(ContextDeadlineKey, deadline.Sub(t).Milliseconds())
+	evt.Bool(ContextCanceledKey, canceled)
+}
@@ -0,0 +1,60 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestAddContextInfo(t *testing.T) {
+	now := time.Now()
+	deadlineCtx, cancel := context.WithDeadline(context.Background(), now.Add(1500*time.Millisecond))
+	defer cancel()
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	expiredCtx, cancel := context.WithDeadline(context.Background(), now.Add(-time.Second))
+	defer cancel()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		deadline any
+		canceled any
+	}{
+		{"Deadline", deadlineCtx, 1500.0, false},
+		{"Canceled", canceledCtx, nil, true},
+		{"Expired", expiredCtx, -1000.0, true},
+		{"Background", context.Background(), nil, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out := bytes.Buffer{}
+			hdl := NewJsonHandler(&out, &HandlerOptions{AddContextInfo: true}).WithGroup("g")
+			rec := slog.NewRecord(now, slog.LevelInfo, "msg", 0)
+			rec.AddAttrs(slog.Int("a", 1))
+			if err := hdl.Handle(test.ctx, rec); err != nil {
+				t.Fatal(err)
+			}
+			var m map[string]any
+			if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+				t.Fatal(err)
+			}
+			if m[ContextDeadlineKey] != test.deadline || m[ContextCanceledKey] != test.canceled {
+				t.Errorf("Expected deadline %v and canceled %v at the top level, got %s", test.deadline, test.canceled, out.String())
+			}
+		})
+	}
+}
+
+func TestAddContextInfo_Disabled(t *testing.T) {
+	out := bytes.Buffer{}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	slog.New(NewJsonHandler(&out, nil)).InfoContext(ctx, "msg")
+	if bytes.Contains(out.Bytes(), []byte("ctx_")) {
+		t.Errorf("Unexpected context info in %s", out.String())
+	}
+}
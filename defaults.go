@@ -0,0 +1,42 @@
+package zeroslog
+
+import (
+	"log/slog"
+	"slices"
+
+	"github.com/rs/zerolog"
+)
+
+// providesKey reports whether attrs have an attribute with key at their level, including the members
+// of groups with an empty key if inline is true, as they're inlined with StrictSlogCompliance.
+func providesKey(attrs []slog.Attr, key string, inline bool) bool {
+	for _, a := range attrs {
+		if a.Key == key {
+			return true
+		}
+		if inline && a.Key == "" && a.Value.Kind() == slog.KindGroup && providesKey(a.Value.Group(), key, inline) {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutProvided returns the default attributes whose key isn't provided by attrs, reusing defaults
+// if they're all kept.
+func withoutProvided(defaults, attrs []slog.Attr, inline bool) []slog.Attr {
+	provided := func(d slog.Attr) bool { return providesKey(attrs, d.Key, inline) }
+	if !slices.ContainsFunc(defaults, provided) {
+		return defaults
+	}
+	return slices.DeleteFunc(slices.Clone(defaults), provided)
+}
+
+// writeDefaults writes the default attributes of the handler whose key is neither provided by the top-level
+// attributes of a record, attrs, nor group, the name of the top-level group of the record, if any.
+func (h *Handler) writeDefaults(evt *zerolog.Event, group string, attrs []slog.Attr) {
+	for _, d := range h.defaults {
+		if d.Key != group && !providesKey(attrs, d.Key, h.opts.StrictSlogCompliance) {
+			mapAttr(evt, h.limits, h.tag(d, originDefault))
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package zeroslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestDefaultAttrs(t *testing.T) {
+	opts := &HandlerOptions{DefaultAttrs: []slog.Attr{slog.String("env", "prod"), slog.String("region", "eu")}}
+	tests := []struct {
+		name   string
+		strict bool
+		log    func(l *slog.Logger)
+		env    any
+		region any
+	}{
+		{"Applied", false, func(l *slog.Logger) { l.Info("msg", "a", 1) }, "prod", "eu"},
+		{"Overridden", false, func(l *slog.Logger) { l.Info("msg", "env", "staging") }, "staging", "eu"},
+		{"Overridden_WithAttrs", false, func(l *slog.Logger) { l.With("env", "dev").Info("msg") }, "dev", "eu"},
+		{"Overridden_GroupName", false, func(l *slog.Logger) { l.Info("msg", slog.Group("env", "name", "dev")) }, map[string]any{"name": "dev"}, "eu"},
+		{"Overridden_WithGroupName", false, func(l *slog.Logger) { l.WithGroup("env").Info("msg", "name", "dev") }, map[string]any{"name": "dev"}, "eu"},
+		{"InGroup", false, func(l *slog.Logger) { l.Info("msg", slog.Group("req", "env", "staging")) }, "prod", "eu"},
+		{"InWithGroup", false, func(l *slog.Logger) { l.WithGroup("req").With("env", "dev").Info("msg", "region", "us") }, "prod", "eu"},
+		{"Strict", true, func(l *slog.Logger) { l.Info("msg", "env", "staging") }, "staging", "eu"},
+		{"Strict_Inline", true, func(l *slog.Logger) { l.Info("msg", slog.Group("", "region", "us")) }, "prod", "us"},
+		{"Strict_InWithGroup", true, func(l *slog.Logger) { l.WithGroup("req").Info("msg", "env", "staging") }, "prod", "eu"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out := bytes.Buffer{}
+			opt := *opts
+			opt.StrictSlogCompliance = test.strict
+			test.log(slog.New(NewJsonHandler(&out, &opt)))
+			var m map[string]any
+			if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+				t.Fatal(err)
+			}
+			if !jsonEqual(m["env"], test.env) || !jsonEqual(m["region"], test.region) {
+				t.Errorf("Expected env %v and region %v, got %s", test.env, test.region, out.String())
+			}
+			if dup := withoutTime(out.String()); bytes.Count([]byte(dup), []byte(`"env"`)) > 1 {
+				t.Errorf("Unexpected duplicate env key in %s", out.String())
+			}
+		})
+	}
+}
+
+// jsonEqual reports whether decoded JSON values a and b are equal.
+func jsonEqual(a, b any) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return bytes.Equal(ja, jb)
+}
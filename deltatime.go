@@ -0,0 +1,35 @@
+package zeroslog
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// DeltaTimeKey is the key of the field holding the milliseconds elapsed since the previous record,
+// written with AddDeltaTime.
+const DeltaTimeKey = "delta_ms"
+
+// deltaClock tracks the time of the latest record written by a handler and the handlers derived from it.
+type deltaClock struct {
+	// last is the latest record time, in Unix nanoseconds, or 0 before the first record.
+	last atomic.Int64
+}
+
+// since returns the milliseconds elapsed between the latest record time and t, and records t if it's later.
+// It returns 0 for the first record, and for records older than the latest one, as when records handled
+// concurrently are written out of order, so that deltas are never negative.
+func (c *deltaClock) since(t time.Time) float64 {
+	now := t.UnixNano()
+	for {
+		last := c.last.Load()
+		if now <= last {
+			return 0
+		}
+		if c.last.CompareAndSwap(last, now) {
+			if last == 0 {
+				return 0
+			}
+			return float64(now-last) / float64(time.Millisecond)
+		}
+	}
+}
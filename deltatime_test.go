@@ -0,0 +1,70 @@
+package zeroslog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAddDeltaTime(t *testing.T) {
+	out := bytes.Buffer{}
+	hdl := NewJsonHandler(&out, &HandlerOptions{AddDeltaTime: true})
+	derived := hdl.WithAttrs([]slog.Attr{slog.Int("a", 1)}).WithGroup("g")
+	start := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	offsets := []time.Duration{0, 1500 * time.Microsecond, 1500 * time.Microsecond, 10 * time.Millisecond, 5 * time.Millisecond}
+	for i, off := range offsets {
+		h := slog.Handler(hdl)
+		if i%2 == 1 {
+			h = derived
+		}
+		rec := slog.NewRecord(start.Add(off), slog.LevelInfo, "msg", 0)
+		rec.AddAttrs(slog.Int("i", i))
+		if err := h.Handle(context.Background(), rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	exp := []float64{0, 1.5, 0, 8.5, 0}
+	scanner := bufio.NewScanner(&out)
+	for i := 0; scanner.Scan(); i++ {
+		var m map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			t.Fatal(err)
+		}
+		if m[DeltaTimeKey] != exp[i] {
+			t.Errorf("Expected delta %v for record %d, got %v", exp[i], i, m[DeltaTimeKey])
+		}
+	}
+}
+
+func TestAddDeltaTime_Concurrent(t *testing.T) {
+	out := &gatedWriter{release: make(chan struct{})}
+	close(out.release)
+	hdl := NewJsonHandler(out, &HandlerOptions{AddDeltaTime: true})
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				// Records are timestamped before being handled, so they may reach the handler out of order.
+				_ = hdl.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0))
+			}
+		}()
+	}
+	wg.Wait()
+	scanner := bufio.NewScanner(&out.buf)
+	for scanner.Scan() {
+		var m map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			t.Fatal(err)
+		}
+		if d, ok := m[DeltaTimeKey].(float64); !ok || d < 0 {
+			t.Fatalf("Expected a non-negative delta, got %s", scanner.Bytes())
+		}
+	}
+}
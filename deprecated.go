@@ -0,0 +1,28 @@
+package zeroslog
+
+import (
+	"io"
+
+	"github.com/rs/zerolog"
+)
+
+// NewZerologHandler creates a new zerolog handler.
+//
+// Deprecated: Use NewHandler, which takes the same arguments.
+func NewZerologHandler(logger zerolog.Logger, opts *HandlerOptions) *Handler {
+	return NewHandler(logger, opts)
+}
+
+// NewZerologJsonHandler creates a new zerolog handler writing JSON records to out.
+//
+// Deprecated: Use NewJsonHandler, which takes the same arguments.
+func NewZerologJsonHandler(out io.Writer, opts *HandlerOptions) *Handler {
+	return NewJsonHandler(out, opts)
+}
+
+// NewZerologConsoleHandler creates a new zerolog handler writing records to out with a zerolog.ConsoleWriter.
+//
+// Deprecated: Use NewConsoleHandler, which takes the same arguments.
+func NewZerologConsoleHandler(out io.Writer, opts *HandlerOptions) *Handler {
+	return NewConsoleHandler(out, opts)
+}
@@ -0,0 +1,52 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestDeprecatedConstructors(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	opts := &HandlerOptions{Level: slog.LevelDebug, AddSource: true}
+	var pcs [1]uintptr
+	runtime.Callers(1, pcs[:])
+	for name, pair := range map[string][2]func(io.Writer) *Handler{
+		"handler": {
+			func(w io.Writer) *Handler { return NewHandler(zerolog.New(w), opts) },
+			func(w io.Writer) *Handler { return NewZerologHandler(zerolog.New(w), opts) },
+		},
+		"json": {
+			func(w io.Writer) *Handler { return NewJsonHandler(w, opts) },
+			func(w io.Writer) *Handler { return NewZerologJsonHandler(w, opts) },
+		},
+		"console": {
+			func(w io.Writer) *Handler { return NewConsoleHandler(w, opts) },
+			func(w io.Writer) *Handler { return NewZerologConsoleHandler(w, opts) },
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var outputs [2]bytes.Buffer
+			for i, newHandler := range pair {
+				var hdl slog.Handler = newHandler(&outputs[i])
+				hdl = hdl.WithAttrs([]slog.Attr{slog.String("service", "api")}).WithGroup("req")
+				for _, lvl := range []slog.Level{slog.LevelDebug - 4, slog.LevelDebug, slog.LevelError} {
+					if hdl.Enabled(context.Background(), lvl) {
+						rec := slog.NewRecord(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), lvl, "hello", pcs[0])
+						rec.AddAttrs(slog.Int("status", 200), slog.Group("user", slog.String("id", "u1")))
+						hdl.Handle(context.Background(), rec)
+					}
+				}
+			}
+			if outputs[0].Len() == 0 || outputs[0].String() != outputs[1].String() {
+				t.Errorf("Outputs differ:\n%s\n%s", outputs[0].String(), outputs[1].String())
+			}
+		})
+	}
+}
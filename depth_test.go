@@ -0,0 +1,215 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// nestedGroup returns a group attribute with depth nested groups, the innermost one holding a "leaf" attribute.
+func nestedGroup(depth int) slog.Attr {
+	a := slog.Int("leaf", 1)
+	for i := 0; i < depth; i++ {
+		a = slog.Attr{Key: "g", Value: slog.GroupValue(a)}
+	}
+	return a
+}
+
+// logJSON writes a record with attrs with a JSON handler with opts, and checks that the output is valid JSON.
+func logJSON(t testing.TB, opts *HandlerOptions, attrs ...slog.Attr) string {
+	t.Helper()
+	out := bytes.Buffer{}
+	slog.New(NewJsonHandler(&out, opts)).LogAttrs(context.Background(), slog.LevelInfo, "msg", attrs...)
+	if !json.Valid(out.Bytes()) {
+		t.Fatalf("Invalid JSON output %.200q", out.String())
+	}
+	return out.String()
+}
+
+func TestMaxAttrDepth(t *testing.T) {
+	for _, test := range []struct {
+		opts  *HandlerOptions
+		depth int
+	}{
+		{nil, DefaultMaxAttrDepth},
+		{&HandlerOptions{MaxAttrDepth: 3}, 3},
+	} {
+		out := logJSON(t, test.opts, nestedGroup(test.depth))
+		if strings.Contains(out, TruncatedValue) || !strings.Contains(out, `"leaf":1`) {
+			t.Errorf("Expected %d nested groups to be written entirely", test.depth)
+		}
+		out = logJSON(t, test.opts, nestedGroup(test.depth+1))
+		exp := strings.Repeat(`{"g":`, test.depth) + `"` + TruncatedValue + `"`
+		if !strings.Contains(out, exp) || strings.Contains(out, "leaf") {
+			t.Errorf("Expected the group nested %d times to be truncated, got %.400s", test.depth+1, out)
+		}
+	}
+}
+
+func TestMaxAttrDepth_Pathological(t *testing.T) {
+	self := []any{1}
+	self = append(self, self)
+	self[1] = self
+
+	wide := make([]slog.Attr, 1_000_000)
+	if testing.Short() {
+		wide = wide[:1000]
+	}
+	for i := range wide {
+		wide[i] = slog.Int("a", i)
+	}
+
+	tests := []struct {
+		name string
+		attr slog.Attr
+	}{
+		{"Deep", nestedGroup(10_000)},
+		{"DeepSlice", slog.Any("s", deepSlice(10_000))},
+		{"DeepMap", slog.Any("m", deepMap(10_000))},
+		{"DeepValues", slog.Any("v", []slog.Value{slog.GroupValue(nestedGroup(10_000))})},
+		{"SelfReferencing", slog.Any("self", self)},
+		{"Wide", slog.Attr{Key: "wide", Value: slog.GroupValue(wide...)}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			logJSON(t, nil, test.attr)
+		})
+	}
+}
+
+func TestMaxAttrDepth_Disabled(t *testing.T) {
+	if out := logJSON(t, &HandlerOptions{MaxAttrDepth: -1}, nestedGroup(200)); strings.Contains(out, TruncatedValue) {
+		t.Errorf("Unexpected truncation without limit")
+	}
+}
+
+func TestMaxAttrWidth(t *testing.T) {
+	opts := &HandlerOptions{MaxAttrWidth: 2}
+	tests := []struct {
+		name string
+		attr slog.Attr
+		exp  string
+	}{
+		{"Group", slog.Group("g", "a", 1, "b", 2, "c", 3, "d", 4), `"g":{"a":1,"b":2,"!TRUNCATED":2}`},
+		{"Slice", slog.Any("s", []any{1, 2, 3}), `"s":[1,2,"!TRUNCATED"]`},
+		{"Values", slog.Any("v", []slog.Value{slog.IntValue(1), slog.IntValue(2), slog.IntValue(3)}), `"v":[1,2,"!TRUNCATED"]`},
+		{"Map", slog.Any("m", map[string]any{"d": 4, "c": 3, "b": 2, "a": 1}), `"m":{"!TRUNCATED":2,"a":1,"b":2}`},
+		{"Nested", slog.Any("n", []any{map[string]any{"a": []any{1, 2, 3}}}), `"n":[{"a":[1,2,"!TRUNCATED"]}]`},
+		{"Small", slog.Group("g", "a", 1, "b", 2), `"g":{"a":1,"b":2}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if out := logJSON(t, opts, test.attr); !strings.Contains(out, test.exp) {
+				t.Errorf("Expected %s, got %s", test.exp, out)
+			}
+		})
+	}
+	wide := make([]any, DefaultMaxAttrWidth+1)
+	if out := logJSON(t, nil, slog.Any("s", wide)); strings.Count(out, "null") != DefaultMaxAttrWidth || !strings.Contains(out, `null,"!TRUNCATED"]`) {
+		t.Errorf("Expected the slice to be truncated to %d elements by default", DefaultMaxAttrWidth)
+	}
+	if out := logJSON(t, &HandlerOptions{MaxAttrWidth: -1}, slog.Any("s", wide)); strings.Contains(out, TruncatedValue) {
+		t.Errorf("Unexpected truncation without limit")
+	}
+}
+
+// deepSlice returns depth nested []any.
+func deepSlice(depth int) any {
+	var v any = 1
+	for i := 0; i < depth; i++ {
+		v = []any{v}
+	}
+	return v
+}
+
+// deepMap returns depth nested map[string]any.
+func deepMap(depth int) any {
+	var v any = 1
+	for i := 0; i < depth; i++ {
+		v = map[string]any{"m": v}
+	}
+	return v
+}
+
+// fuzzValue builds an attribute value from data, so that fuzzing explores nested and mixed containers.
+// Each byte either opens a group, a slice or a map, closes the innermost one, or adds a value to it.
+func fuzzValue(data []byte) slog.Value {
+	type frame struct {
+		kind   byte
+		values []slog.Value
+	}
+	stack := []frame{{kind: 0}}
+	closeFrame := func() {
+		f := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		var v slog.Value
+		switch f.kind {
+		case 1:
+			members := make([]slog.Attr, len(f.values))
+			for i, m := range f.values {
+				members[i] = slog.Attr{Key: string(rune('a' + i%26)), Value: m}
+			}
+			v = slog.GroupValue(members...)
+		case 2:
+			v = slog.AnyValue(f.values)
+		default:
+			m := make(map[string]any, len(f.values))
+			for i, e := range f.values {
+				m[string(rune('a'+i%26))] = e
+			}
+			v = slog.AnyValue(m)
+		}
+		top := &stack[len(stack)-1]
+		top.values = append(top.values, v)
+	}
+	for _, b := range data {
+		top := &stack[len(stack)-1]
+		switch b % 8 {
+		case 0, 1, 2:
+			stack = append(stack, frame{kind: b%8 + 1})
+		case 3:
+			if len(stack) > 1 {
+				closeFrame()
+			}
+		case 4:
+			top.values = append(top.values, slog.StringValue(string(data)))
+		case 5:
+			top.values = append(top.values, slog.Int64Value(int64(b)))
+		case 6:
+			top.values = append(top.values, slog.AnyValue([]byte{b}))
+		default:
+			top.values = append(top.values, slog.AnyValue(nil))
+		}
+	}
+	for len(stack) > 1 {
+		closeFrame()
+	}
+	return slog.AnyValue(stack[0].values)
+}
+
+func FuzzMapAttr(f *testing.F) {
+	f.Add([]byte{0, 0, 4, 3, 5})
+	f.Add(bytes.Repeat([]byte{0}, 300))
+	f.Add(bytes.Repeat([]byte{1, 2}, 150))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		logJSON(t, nil, slog.Any("v", fuzzValue(data)), slog.Attr{Key: "g", Value: slog.GroupValue(slog.Any("v", fuzzValue(data)))})
+	})
+}
+
+func FuzzMapAttrAny(f *testing.F) {
+	f.Add("key", []byte{0, 1, 2, 4, 3, 3})
+	f.Add("", bytes.Repeat([]byte{2}, 300))
+	f.Fuzz(func(t *testing.T, key string, data []byte) {
+		buf := bytes.Buffer{}
+		l := zerolog.New(&buf)
+		mapAttrAny(l.Log(), new(HandlerOptions).attrLimits(), key, fuzzValue(data).Any(), 0).Send()
+		if !json.Valid(buf.Bytes()) {
+			t.Fatalf("Invalid JSON output %.200q", buf.String())
+		}
+	})
+}
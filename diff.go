@@ -0,0 +1,109 @@
+package zeroslog
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Keys of the members of the groups written by Diff for each changed field.
+const (
+	DiffOldKey = "old"
+	DiffNewKey = "new"
+)
+
+// Diff returns a group attribute describing what changed between before and after, like in
+//
+//	logger.Info("user updated", zeroslog.Diff("changes", oldUser, newUser))
+//
+// which is written as {"changes":{"email":{"old":"a@example.com","new":"b@example.com"}}}.
+//
+// The diff is shallow: structs of the same type are compared field by field, and maps with string keys
+// of the same type key by key. Pointers are dereferenced. Fields are named by their json tag when they
+// have one, and unexported fields and fields tagged "-" are ignored. Unchanged fields are omitted, and
+// so is the old (new) value of a key only present in after (before).
+//
+// Values of other or mismatched types are written as a single change holding their string forms.
+// The group is empty, and not written, when nothing changed.
+func Diff(key string, before, after any) slog.Attr {
+	bv, av := diffIndirect(reflect.ValueOf(before)), diffIndirect(reflect.ValueOf(after))
+	if bv.IsValid() && av.IsValid() && bv.Type() == av.Type() {
+		switch {
+		case bv.Kind() == reflect.Struct:
+			return slog.Attr{Key: key, Value: slog.GroupValue(diffStructs(bv, av)...)}
+		case bv.Kind() == reflect.Map && bv.Type().Key().Kind() == reflect.String:
+			return slog.Attr{Key: key, Value: slog.GroupValue(diffMaps(bv, av)...)}
+		}
+	}
+	if reflect.DeepEqual(before, after) {
+		return slog.Attr{Key: key, Value: slog.GroupValue()}
+	}
+	return slog.Group(key, DiffOldKey, diffString(bv), DiffNewKey, diffString(av))
+}
+
+// diffIndirect dereferences the pointers v points to, if any.
+func diffIndirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+// diffString returns the string form of a value which Diff can't compare field by field.
+func diffString(v reflect.Value) string {
+	if !v.IsValid() || (v.Kind() == reflect.Pointer && v.IsNil()) {
+		return "<nil>"
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// diffStructs returns the changes between the fields of the structs before and after, of the same type.
+func diffStructs(before, after reflect.Value) []slog.Attr {
+	var changes []slog.Attr
+	for i, t := 0, before.Type(); i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			tag, _, _ = strings.Cut(tag, ",")
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		b, a := before.Field(i).Interface(), after.Field(i).Interface()
+		if !reflect.DeepEqual(b, a) {
+			changes = append(changes, slog.Group(name, DiffOldKey, b, DiffNewKey, a))
+		}
+	}
+	return changes
+}
+
+// diffMaps returns the changes between the maps before and after, of the same type with string keys,
+// sorted by key.
+func diffMaps(before, after reflect.Value) []slog.Attr {
+	var changes []slog.Attr
+	for _, k := range before.MapKeys() {
+		b, a := before.MapIndex(k), after.MapIndex(k)
+		switch {
+		case !a.IsValid():
+			changes = append(changes, slog.Group(k.String(), DiffOldKey, b.Interface()))
+		case !reflect.DeepEqual(b.Interface(), a.Interface()):
+			changes = append(changes, slog.Group(k.String(), DiffOldKey, b.Interface(), DiffNewKey, a.Interface()))
+		}
+	}
+	for _, k := range after.MapKeys() {
+		if !before.MapIndex(k).IsValid() {
+			changes = append(changes, slog.Group(k.String(), DiffNewKey, after.MapIndex(k).Interface()))
+		}
+	}
+	slices.SortFunc(changes, func(a, b slog.Attr) int { return cmp.Compare(a.Key, b.Key) })
+	return changes
+}
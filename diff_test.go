@@ -0,0 +1,53 @@
+package zeroslog
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type diffUser struct {
+	Name    string
+	Email   string `json:"email,omitempty"`
+	Roles   []string
+	Secret  string `json:"-"`
+	private int
+}
+
+func TestDiff(t *testing.T) {
+	before := diffUser{Name: "bob", Email: "a@example.com", Roles: []string{"admin"}, Secret: "x", private: 1}
+	after := before
+	after.Email, after.Roles, after.Secret, after.private = "b@example.com", []string{"admin", "dev"}, "y", 2
+
+	tests := []struct {
+		name          string
+		before, after any
+		exp           string
+	}{
+		{"Struct", before, after, `{"email":{"old":"a@example.com","new":"b@example.com"},"Roles":{"old":["admin"],"new":["admin","dev"]}}`},
+		{"StructPointers", &before, &after, `{"email":{"old":"a@example.com","new":"b@example.com"},"Roles":{"old":["admin"],"new":["admin","dev"]}}`},
+		{"Map", map[string]any{"a": 1, "b": 2, "c": 3}, map[string]any{"a": 1, "b": 4, "d": 5}, `{"b":{"old":2,"new":4},"c":{"old":3},"d":{"new":5}}`},
+		{"MismatchedTypes", before, map[string]any{"Name": "bob"}, `{"old":"{bob a@example.com [admin] x 1}","new":"map[Name:bob]"}`},
+		{"Nil", nil, &after, `{"old":"<nil>","new":"{bob b@example.com [admin dev] y 2}"}`},
+		{"Scalars", 1, 2, `{"old":"1","new":"2"}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var m map[string]json.RawMessage
+			if err := json.Unmarshal([]byte(logJSON(t, nil, Diff("diff", test.before, test.after))), &m); err != nil {
+				t.Fatal(err)
+			}
+			if got := string(m["diff"]); got != test.exp {
+				t.Errorf("Expected %s, got %s", test.exp, got)
+			}
+		})
+	}
+}
+
+func TestDiff_Unchanged(t *testing.T) {
+	u := diffUser{Name: "bob", Roles: []string{"admin"}}
+	for _, pair := range [][2]any{{u, u}, {map[string]int{"a": 1}, map[string]int{"a": 1}}, {"s", "s"}, {nil, nil}} {
+		if a := Diff("diff", pair[0], pair[1]); len(a.Value.Group()) != 0 {
+			t.Errorf("Expected no change between %v and %v, got %v", pair[0], pair[1], a)
+		}
+	}
+}
@@ -0,0 +1,385 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
+	"math/rand"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// diffKinds are the kinds of attributes whose encoding differs between the handlers, by key path.
+type diffKinds map[string]slog.Kind
+
+// diffRecord builds a handler derivation and a record from data, read as a stream of opcodes and operands,
+// so that fuzzing explores the kinds of attributes, their nesting and their edge values.
+// Keys are unique across the record, for groups with an empty key to be inlined without conflicts.
+type diffRecord struct {
+	data  []byte
+	keys  int
+	kinds diffKinds
+	// ops has a bit set for each opcode read.
+	ops uint32
+	// emptyKeys counts the attributes with an empty key by group path. Several of them in the same group are
+	// only told apart once decoded with StrictSlogCompliance, as groups with an empty key are inlined.
+	emptyKeys map[string]int
+	// record is true once the attributes of the record are generated, after the ones of WithAttrs.
+	record bool
+}
+
+// diffLevels are the levels of the generated records, whose names are the same for both handlers but their case.
+var diffLevels = []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError}
+
+// diffOps is the number of opcodes of diffRecord.
+const diffOps = 19
+
+// diffAlphabet are the characters of the generated strings, including the ones to escape in JSON.
+const diffAlphabet = "ab Z09_-.é€\"\\\n\t<>&/"
+
+func (g *diffRecord) next() byte {
+	if len(g.data) == 0 {
+		return 0
+	}
+	b := g.data[0]
+	g.data = g.data[1:]
+	return b
+}
+
+func (g *diffRecord) uint64() uint64 {
+	var v uint64
+	for i := 0; i < 8; i++ {
+		v = v<<8 | uint64(g.next())
+	}
+	return v
+}
+
+func (g *diffRecord) key() string {
+	g.keys++
+	return "k" + strconv.Itoa(g.keys)
+}
+
+func (g *diffRecord) string() string {
+	runes := []rune(diffAlphabet)
+	var sb strings.Builder
+	for n := g.next() % 8; n > 0; n-- {
+		sb.WriteRune(runes[int(g.next())%len(runes)])
+	}
+	return sb.String()
+}
+
+// attrs returns the attributes at the group path prefix, until the end of data or of the group.
+// depth is the number of groups enclosing them in the attributes of the record or of WithAttrs.
+func (g *diffRecord) attrs(prefix string, depth int) []slog.Attr {
+	var attrs []slog.Attr
+	for len(g.data) > 0 {
+		key := g.key()
+		op := g.next() % diffOps
+		g.ops |= 1 << op
+		switch op {
+		case 0:
+			attrs = append(attrs, slog.String(key, g.string()))
+		case 1:
+			attrs = append(attrs, slog.Int64(key, int64(g.uint64())))
+		case 2:
+			attrs = append(attrs, slog.Uint64(key, g.uint64()))
+		case 3:
+			// Non-finite floats aren't valid JSON, and are written differently.
+			f := math.Float64frombits(g.uint64())
+			if math.IsNaN(f) || math.IsInf(f, 0) {
+				f = float64(int8(g.next())) / 8
+			}
+			attrs = append(attrs, slog.Float64(key, f))
+		case 4:
+			attrs = append(attrs, slog.Bool(key, g.next()%2 == 0))
+		case 5:
+			// Durations are kept below 2^50ns, for their conversion to float milliseconds to be exact.
+			g.kinds[prefix+key] = slog.KindDuration
+			attrs = append(attrs, slog.Duration(key, time.Duration(int64(g.uint64())>>14)))
+		case 6:
+			g.kinds[prefix+key] = slog.KindTime
+			zone := time.FixedZone("", (int(int8(g.next()))%14)*3600)
+			attrs = append(attrs, slog.Time(key, time.Unix(int64(g.uint64()%(1<<35)), int64(g.next())*1e6).In(zone)))
+		case 7, 8:
+			// Groups with an empty key are inlined.
+			if depth >= 3 {
+				continue
+			}
+			name := key
+			if op == 8 {
+				name = ""
+				g.emptyKey(prefix)
+			}
+			members := g.attrs(prefix+name+".", depth+1)
+			if name == "" {
+				members = g.inlined(prefix, members)
+			}
+			attrs = append(attrs, slog.Attr{Key: name, Value: slog.GroupValue(members...)})
+		case 9:
+			return attrs
+		case 10:
+			attrs = append(attrs, slog.Any(key, errors.New(g.string())))
+		case 11:
+			attrs = append(attrs, slog.Any(key, []any{int(int8(g.next())), g.string(), g.next()%2 == 0, nil}))
+		case 12:
+			attrs = append(attrs, slog.Any(key, map[string]any{"a": int(int8(g.next())), "b": g.string()}))
+		case 13:
+			attrs = append(attrs, slog.Any(key, nil))
+		case 14:
+			attrs = append(attrs, slog.Any(key, diffValuer{slog.StringValue(g.string())}))
+		case 15:
+			// slog.JSONHandler writes invalid JSON for groups holding only empty attributes,
+			// and an empty group for WithAttrs called with them.
+			if depth > 0 || !g.record {
+				continue
+			}
+			attrs = append(attrs, slog.Attr{})
+		case 16:
+			attrs = append(attrs, slog.Any(key, net.IPv4(g.next(), g.next(), g.next(), g.next())))
+		case 17:
+			g.emptyKey(prefix)
+			attrs = append(attrs, slog.String("", g.string()))
+		default:
+			attrs = append(attrs, slog.Int(key, int(int8(g.next()))))
+		}
+	}
+	return attrs
+}
+
+// emptyKey counts an attribute with an empty key at the group path prefix.
+func (g *diffRecord) emptyKey(prefix string) {
+	g.emptyKeys[prefix]++
+}
+
+// inlined moves the kinds recorded for members of a group with an empty key to prefix, where they're inlined.
+func (g *diffRecord) inlined(prefix string, members []slog.Attr) []slog.Attr {
+	for path, kind := range g.kinds {
+		if rest, ok := strings.CutPrefix(path, prefix+"."); ok {
+			delete(g.kinds, path)
+			g.kinds[prefix+rest] = kind
+		}
+	}
+	return members
+}
+
+// diffValuer is a slog.LogValuer, resolved by both handlers.
+type diffValuer struct{ v slog.Value }
+
+func (v diffValuer) LogValue() slog.Value { return v.v }
+
+// diffCase is a generated case: the groups and attributes added to the handlers, and the record.
+type diffCase struct {
+	groups [][]slog.Attr
+	names  []string
+	rec    slog.Record
+	kinds  diffKinds
+	ops    uint32
+	// ambiguous is true if the case can only be compared with StrictSlogCompliance.
+	ambiguous bool
+}
+
+// newDiffCase generates a case from data.
+func newDiffCase(data []byte) diffCase {
+	g := &diffRecord{data: data, kinds: diffKinds{}, emptyKeys: map[string]int{}}
+	c := diffCase{}
+	prefix := ""
+	for n := g.next() % 3; n > 0; n-- {
+		name := g.key()
+		c.names = append(c.names, name)
+		prefix += name + "."
+		c.groups = append(c.groups, g.attrs(prefix, 0))
+	}
+	lvl := diffLevels[int(g.next())%len(diffLevels)]
+	c.rec = slog.NewRecord(time.Unix(int64(g.uint64()%(1<<35)), 0), lvl, g.string(), 0)
+	g.record = true
+	c.rec.AddAttrs(g.attrs(prefix, 0)...)
+	c.kinds, c.ops = g.kinds, g.ops
+	for _, n := range g.emptyKeys {
+		c.ambiguous = c.ambiguous || n > 1
+	}
+	return c
+}
+
+// handle writes the record of c with h, derived with the groups of c, and decodes the output.
+func (c diffCase) handle(t *testing.T, h slog.Handler, out *bytes.Buffer) map[string]any {
+	t.Helper()
+	for i, name := range c.names {
+		h = h.WithGroup(name).WithAttrs(c.groups[i])
+	}
+	if err := h.Handle(context.Background(), c.rec.Clone()); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	m := map[string]any{}
+	dec := json.NewDecoder(bytes.NewReader(out.Bytes()))
+	dec.UseNumber()
+	if err := dec.Decode(&m); err != nil {
+		t.Fatalf("Invalid output %q: %s", out.String(), err)
+	}
+	return m
+}
+
+// checkDifferential writes the record generated from data with zeroslog and slog.JSONHandler,
+// and compares the decoded outputs once normalized.
+func checkDifferential(t *testing.T, data []byte) {
+	t.Helper()
+	c := newDiffCase(data)
+	for _, strict := range []bool{false, true} {
+		if c.ambiguous && !strict {
+			continue
+		}
+		zout, sout := bytes.Buffer{}, bytes.Buffer{}
+		got := c.handle(t, NewJsonHandler(&zout, &HandlerOptions{Level: slog.LevelDebug, StrictSlogCompliance: strict}), &zout)
+		exp := c.handle(t, slog.NewJSONHandler(&sout, &slog.HandlerOptions{Level: slog.LevelDebug}), &sout)
+
+		got = normalizeZeroslog(got, c.kinds, "")
+		exp = normalizeSlog(exp, c.kinds, "")
+		if !reflect.DeepEqual(got, exp) {
+			t.Fatalf("Strict %t, data %v: outputs differ\nzeroslog: %s\nslog:     %s\nnormalized:\n%v\n%v", strict, data, zout.String(), sout.String(), got, exp)
+		}
+	}
+}
+
+// normalizeZeroslog normalizes the fields of a zeroslog record at the group path prefix.
+// The documented differences with slog.JSONHandler are:
+//   - the envelope fields are named after zerolog's field names, levels are lower case, and empty messages are omitted,
+//   - record times are written with zerolog.TimeFieldFormat, to the second by default,
+//   - durations are written in zerolog.DurationFieldUnit, milliseconds by default,
+//   - groups with an empty key are only inlined with StrictSlogCompliance.
+func normalizeZeroslog(m map[string]any, kinds diffKinds, prefix string) map[string]any {
+	if prefix == "" {
+		m[slog.LevelKey] = strings.ToUpper(m[zerolog.LevelFieldName].(string))
+		m[slog.MessageKey] = ""
+		if msg, ok := m[zerolog.MessageFieldName]; ok {
+			m[slog.MessageKey] = msg
+			delete(m, zerolog.MessageFieldName)
+		}
+	}
+	for inline, ok := m[""].(map[string]any); ok; inline, ok = m[""].(map[string]any) {
+		delete(m, "")
+		for k, v := range inline {
+			m[k] = v
+		}
+	}
+	for k, v := range m {
+		m[k] = normalizeValue(v, kinds, prefix+k, true)
+	}
+	return m
+}
+
+// normalizeSlog normalizes the fields of a slog.JSONHandler record at the group path prefix.
+func normalizeSlog(m map[string]any, kinds diffKinds, prefix string) map[string]any {
+	for k, v := range m {
+		m[k] = normalizeValue(v, kinds, prefix+k, false)
+	}
+	return m
+}
+
+// normalizeValue returns a comparable form of the value at path, written by zeroslog if zero is true.
+func normalizeValue(v any, kinds diffKinds, path string, zero bool) any {
+	switch v := v.(type) {
+	case map[string]any:
+		if zero {
+			return normalizeZeroslog(v, kinds, path+".")
+		}
+		return normalizeSlog(v, kinds, path+".")
+	case json.Number:
+		if kinds[path] == slog.KindDuration {
+			f, _ := v.Float64()
+			if zero {
+				f *= float64(zerolog.DurationFieldUnit)
+			}
+			return time.Duration(math.Round(f)).String()
+		}
+		return normalizeNumber(v)
+	case string:
+		if kinds[path] == slog.KindTime || path == slog.TimeKey {
+			if ts, err := time.Parse(time.RFC3339Nano, v); err == nil {
+				return ts.Truncate(time.Second).Format(time.RFC3339)
+			}
+		}
+		return v
+	case []any:
+		s := make([]any, len(v))
+		for i, val := range v {
+			s[i] = normalizeValue(val, nil, "", zero)
+		}
+		return s
+	default:
+		return v
+	}
+}
+
+// normalizeNumber returns the value of n, whatever its formatting. Integers are kept exact.
+func normalizeNumber(n json.Number) string {
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil && (i > 1<<53 || i < -1<<53) {
+		return strconv.FormatInt(i, 10)
+	}
+	if u, err := strconv.ParseUint(string(n), 10, 64); err == nil && u > 1<<53 {
+		return strconv.FormatUint(u, 10)
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return string(n)
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// diffSeed returns a seed for a record without groups, at level info, with the message "msg" and the attributes
+// encoded in body.
+func diffSeed(body ...byte) []byte {
+	return append([]byte{0, 1, 0, 0, 0, 0, 0x12, 0x34, 0x56, 0x78, 3, 'm', 's', 'g'}, body...)
+}
+
+// diffCorpus are seeds covering every opcode of diffRecord: kinds, nesting, empty and inlined groups, and edge values.
+var diffCorpus = [][]byte{
+	{},
+	diffSeed(0, 3, 'a', 'b', 'c', 0, 0, 0, 4, 7, 8, 9, 10),
+	diffSeed(1, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 1, 0x80, 0, 0, 0, 0, 0, 0, 0, 18, 0x80),
+	diffSeed(2, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 2, 0, 0x20, 0, 0, 0, 0, 0, 1),
+	diffSeed(3, 0x3f, 0xb9, 0x99, 0x99, 0x99, 0x99, 0x99, 0x9a, 3, 0x7f, 0xf0, 0, 0, 0, 0, 0, 0, 0x11, 3, 0, 0, 0, 0, 0, 0, 0, 1),
+	diffSeed(4, 0, 4, 1),
+	diffSeed(5, 0, 0, 0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 5, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff),
+	diffSeed(6, 2, 0, 0, 0, 0x05, 0x12, 0x34, 0x56, 0x78, 7, 6, 0xf6, 0, 0, 0, 0, 0, 0, 0, 1, 0),
+	diffSeed(7, 0, 2, 'x', 'y', 9, 7, 9, 7, 7, 15, 9, 9, 4, 0),
+	diffSeed(8, 5, 0, 0, 0, 0, 0, 0, 0x10, 0, 8, 6, 0, 0, 0, 0, 0, 0, 0, 0, 0, 9, 9, 18, 1),
+	diffSeed(10, 3, 'e', 'r', 'r', 11, 0xfe, 2, 'a', 'b', 1, 12, 5, 1, 'c', 13, 14, 2, 'v', 'w', 15, 16, 127, 0, 0, 1, 17, 1, 'z'),
+	diffSeed(7, 8, 8, 17, 1, 'a', 9, 9, 9, 7, 7, 7, 7, 4, 0, 9, 9, 9, 9),
+	append([]byte{2, 0, 1, 'a', 7, 15, 9, 9, 15, 9}, diffSeed(18, 4)[1:]...),
+	append([]byte{2, 15, 9, 13, 9}, diffSeed(7, 15, 9)[1:]...),
+	append([]byte{1, 5, 0, 0, 0, 0, 0, 0, 1, 0, 9}, diffSeed()[1:]...),
+}
+
+func TestDifferential_JSONHandler(t *testing.T) {
+	var ops uint32
+	for _, data := range diffCorpus {
+		checkDifferential(t, data)
+		ops |= newDiffCase(data).ops
+	}
+	for op := 0; op < diffOps; op++ {
+		if ops&(1<<op) == 0 {
+			t.Errorf("Opcode %d not covered by the corpus", op)
+		}
+	}
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		data := make([]byte, rnd.Intn(200))
+		rnd.Read(data)
+		checkDifferential(t, data)
+	}
+}
+
+func FuzzDifferential_JSONHandler(f *testing.F) {
+	for _, data := range diffCorpus {
+		f.Add(data)
+	}
+	f.Fuzz(checkDifferential)
+}
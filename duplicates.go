@@ -0,0 +1,121 @@
+package zeroslog
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// DuplicateKeyError is reported to OnError with WarnOnDuplicateKeys, when a record is written with
+// several fields having the same key in the same group.
+type DuplicateKeyError struct {
+	// Key is the duplicate key.
+	Key string
+	// Group is the dot-joined path of the group holding the fields, empty at the top level.
+	Group string
+}
+
+// Error implements error.
+func (e *DuplicateKeyError) Error() string {
+	if e.Group == "" {
+		return fmt.Sprintf("zeroslog: duplicate key %q", e.Key)
+	}
+	return fmt.Sprintf("zeroslog: duplicate key %q in group %q", e.Key, e.Group)
+}
+
+// duplicateKeys finds the duplicate keys of a record, for WarnOnDuplicateKeys.
+type duplicateKeys struct {
+	h *Handler
+	// seen are the keys already written, prefixed with their group path and a NUL separator.
+	seen map[string]struct{}
+}
+
+var duplicateKeysPool = sync.Pool{
+	New: func() any { return &duplicateKeys{seen: make(map[string]struct{})} },
+}
+
+// duplicateKeys returns a duplicateKeys reporting to h, or nil if WarnOnDuplicateKeys is not set.
+// Records written with StrictSlogCompliance or DedupKeys have no duplicate keys.
+func (h *Handler) duplicateKeys() *duplicateKeys {
+	if !h.opts.WarnOnDuplicateKeys || h.opts.mergesAttrs() {
+		return nil
+	}
+	d := duplicateKeysPool.Get().(*duplicateKeys)
+	d.h = h
+	return d
+}
+
+// release puts d back into the pool.
+func (d *duplicateKeys) release() {
+	if d == nil {
+		return
+	}
+	clear(d.seen)
+	d.h = nil
+	duplicateKeysPool.Put(d)
+}
+
+// key records key, written in the group whose dot-joined path is prefix, including the trailing dot.
+func (d *duplicateKeys) key(prefix, key string) {
+	k := prefix + "\x00" + key
+	if _, ok := d.seen[k]; ok {
+		d.h.reportError(&DuplicateKeyError{Key: key, Group: strings.TrimSuffix(prefix, ".")})
+		return
+	}
+	d.seen[k] = struct{}{}
+}
+
+// attr records the key of a, written in the group whose path is prefix, and the keys of its members if it's a group.
+func (d *duplicateKeys) attr(prefix string, a slog.Attr) {
+	if d == nil {
+		return
+	}
+	d.key(prefix, a.Key)
+	if a.Value.Kind() == slog.KindGroup {
+		for _, m := range a.Value.Group() {
+			d.attr(prefix+a.Key+".", m)
+		}
+	}
+}
+
+// context records the keys of the attributes added with WithAttrs to a group whose path is prefix, oldest first.
+func (d *duplicateKeys) context(prefix string, attrs pendingAttrs) {
+	if d == nil {
+		return
+	}
+	d.segment(prefix, attrs.last)
+}
+
+// segment records the keys of the attributes of s and its previous segments, oldest first.
+func (d *duplicateKeys) segment(prefix string, s *attrSegment) {
+	if s == nil {
+		return
+	}
+	d.segment(prefix, s.prev)
+	for _, a := range s.attrs {
+		d.attr(prefix, a)
+	}
+}
+
+// groups records the keys of the attributes added with WithAttrs to h and its parent groups,
+// and the names of the groups, as they are written in the records handled by h.
+func (d *duplicateKeys) groups(h *groupHandler) {
+	if d == nil {
+		return
+	}
+	for {
+		d.context(h.prefix, h.ctxAttrs)
+		switch parent := h.parent.(type) {
+		case *groupHandler:
+			d.key(parent.prefix, h.name)
+			h = parent
+		case *Handler:
+			d.context("", parent.ctxAttrs)
+			d.key("", h.name)
+			return
+		default:
+			return
+		}
+	}
+}
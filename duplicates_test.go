@@ -0,0 +1,107 @@
+package zeroslog
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWarnOnDuplicateKeys(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		log  func(l *slog.Logger)
+		exp  []DuplicateKeyError
+	}{
+		{"none", func(l *slog.Logger) {
+			l.With("a", 1).WithGroup("g").With("a", 2).Info("msg", "b", 3, slog.Group("c", "a", 4))
+		}, nil},
+		{"record", func(l *slog.Logger) {
+			l.Info("msg", "a", 1, "b", 2, "a", 3)
+		}, []DuplicateKeyError{{Key: "a"}}},
+		{"context-record", func(l *slog.Logger) {
+			l.With("a", 1).With("b", 2).Info("msg", "b", 3)
+		}, []DuplicateKeyError{{Key: "b"}}},
+		{"context-context", func(l *slog.Logger) {
+			l.With("a", 1).With("a", 2).Info("msg")
+		}, []DuplicateKeyError{{Key: "a"}}},
+		{"record-group", func(l *slog.Logger) {
+			l.Info("msg", slog.Group("g", "a", 1, slog.Group("h", "b", 2, "b", 3), "a", 4))
+		}, []DuplicateKeyError{{Key: "b", Group: "g.h"}, {Key: "a", Group: "g"}}},
+		{"context-group", func(l *slog.Logger) {
+			l.With("a", 1).WithGroup("g").With("a", 2, "b", 3).WithGroup("h").Info("msg", "b", 4)
+		}, nil},
+		{"handler-group", func(l *slog.Logger) {
+			l.WithGroup("g").With("a", 1).Info("msg", "a", 2, slog.Group("h", "x", 1), slog.Group("h", "y", 2))
+		}, []DuplicateKeyError{{Key: "a", Group: "g"}, {Key: "h", Group: "g"}}},
+		{"group-name", func(l *slog.Logger) {
+			l.With("g", 1).WithGroup("g").WithGroup("h").Info("msg", "x", 1)
+		}, []DuplicateKeyError{{Key: "g"}}},
+		{"nested-group-name", func(l *slog.Logger) {
+			l.WithGroup("g").With("h", 1).WithGroup("h").Info("msg", "x", 1)
+		}, []DuplicateKeyError{{Key: "h", Group: "g"}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var errs []DuplicateKeyError
+			out := bytes.Buffer{}
+			l := slog.New(NewJsonHandler(&out, &HandlerOptions{
+				WarnOnDuplicateKeys: true,
+				OnError: func(err error) {
+					var dup *DuplicateKeyError
+					if !errors.As(err, &dup) {
+						t.Fatalf("Unexpected error: %v", err)
+					}
+					errs = append(errs, *dup)
+				},
+			}))
+			tc.log(l)
+			if !reflect.DeepEqual(errs, tc.exp) {
+				t.Errorf("Reported %v, expected %v", errs, tc.exp)
+			}
+
+			out.Reset()
+			tc.log(slog.New(NewJsonHandler(&out, nil)))
+			expected := out.String()
+			out.Reset()
+			errs = nil
+			tc.log(l)
+			if got := withoutTime(out.String()); got != withoutTime(expected) {
+				t.Errorf("Record changed: %s, expected %s", got, expected)
+			}
+		})
+	}
+}
+
+// withoutTime removes the leading time field of a JSON line.
+func withoutTime(line string) string {
+	if _, rest, ok := strings.Cut(line, `"time":`); ok {
+		_, rest, _ = strings.Cut(rest, ",")
+		return rest
+	}
+	return line
+}
+
+func TestWarnOnDuplicateKeys_Disabled(t *testing.T) {
+	for name, opts := range map[string]*HandlerOptions{
+		"unset":  {},
+		"strict": {WarnOnDuplicateKeys: true, StrictSlogCompliance: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			opts.OnError = func(err error) { t.Errorf("Unexpected error: %v", err) }
+			l := slog.New(NewJsonHandler(&bytes.Buffer{}, opts))
+			l.With("a", 1).Info("msg", "a", 2)
+			l.WithGroup("g").With("a", 1).Info("msg", "a", 2)
+		})
+	}
+}
+
+func TestDuplicateKeyError(t *testing.T) {
+	if msg := (&DuplicateKeyError{Key: "a"}).Error(); !strings.Contains(msg, `"a"`) || strings.Contains(msg, "group") {
+		t.Errorf("Unexpected message %q", msg)
+	}
+	if msg := (&DuplicateKeyError{Key: "a", Group: "g.h"}).Error(); !strings.Contains(msg, `group "g.h"`) {
+		t.Errorf("Unexpected message %q", msg)
+	}
+}
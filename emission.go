@@ -0,0 +1,36 @@
+package zeroslog
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/rs/zerolog"
+)
+
+// ErrNotEmitted is returned by Handle when HandlerOptions.StrictEmission is set
+// and the record would not be written by the zerolog logger.
+var ErrNotEmitted = errors.New("zeroslog: record not emitted")
+
+// emissionError returns an error wrapping ErrNotEmitted and telling why a record at level lvl
+// would not be written, or nil if it would be.
+func (h *Handler) emissionError(lvl slog.Level) error {
+	zlvl := ZerologLevel(lvl)
+	switch {
+	case zlvl < zerolog.GlobalLevel():
+		return fmt.Errorf("%w: level %s is below zerolog's global level %s", ErrNotEmitted, zlvl, zerolog.GlobalLevel())
+	case h.logger.GetLevel() == zerolog.Disabled:
+		return fmt.Errorf("%w: logger is disabled", ErrNotEmitted)
+	case !h.shouldEmit(lvl):
+		return fmt.Errorf("%w: level %s is below the handler level", ErrNotEmitted, zlvl)
+	}
+	// The remaining case is a logger without writer. Levels and sampler are removed from the probe,
+	// so that probing doesn't count as a sample.
+	probe := h.logger.Sample(nil).Level(zerolog.TraceLevel)
+	evt := probe.WithLevel(zlvl)
+	if evt == nil {
+		return fmt.Errorf("%w: logger has no writer", ErrNotEmitted)
+	}
+	evt.Discard()
+	return nil
+}
@@ -0,0 +1,68 @@
+package zeroslog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestStrictEmission(t *testing.T) {
+	for name, tc := range map[string]struct {
+		logger zerolog.Logger
+		setup  func() func()
+		level  slog.Level
+		err    bool
+	}{
+		"emitted": {
+			logger: zerolog.New(io.Discard),
+			level:  slog.LevelInfo,
+		},
+		"global-level": {
+			logger: zerolog.New(io.Discard),
+			setup: func() func() {
+				prev := zerolog.GlobalLevel()
+				zerolog.SetGlobalLevel(zerolog.ErrorLevel)
+				return func() { zerolog.SetGlobalLevel(prev) }
+			},
+			level: slog.LevelInfo,
+			err:   true,
+		},
+		"disabled": {
+			logger: zerolog.New(io.Discard).Level(zerolog.Disabled),
+			level:  slog.LevelError,
+			err:    true,
+		},
+		"below-level": {
+			logger: zerolog.New(io.Discard).Level(zerolog.WarnLevel),
+			level:  slog.LevelInfo,
+			err:    true,
+		},
+		"no-writer": {
+			logger: zerolog.Logger{},
+			level:  slog.LevelInfo,
+			err:    true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if tc.setup != nil {
+				defer tc.setup()()
+			}
+			for _, strict := range []bool{false, true} {
+				var hdl slog.Handler = NewHandler(tc.logger, &HandlerOptions{StrictEmission: strict})
+				for _, h := range []slog.Handler{hdl, hdl.WithGroup("g")} {
+					err := h.Handle(context.Background(), slog.NewRecord(now, tc.level, "foobar", 0))
+					switch {
+					case strict && tc.err && !errors.Is(err, ErrNotEmitted):
+						t.Errorf("Expected ErrNotEmitted, got %v", err)
+					case (!strict || !tc.err) && err != nil:
+						t.Errorf("Unexpected error: %s", err)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package zeroslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestEnvelopeFirst(t *testing.T) {
+	logs := map[string]func(l *slog.Logger){
+		"Record":    func(l *slog.Logger) { l.Info("msg", "attr", 1) },
+		"WithAttrs": func(l *slog.Logger) { l.With("attr", 1).Info("msg", "b", 2) },
+		"WithGroup": func(l *slog.Logger) { l.With("attr", 1).WithGroup("g").Info("msg", "b", 2) },
+	}
+	for name, log := range logs {
+		for _, strict := range []bool{false, true} {
+			outputs := map[bool]string{}
+			for _, first := range []bool{false, true} {
+				out := bytes.Buffer{}
+				opts := &HandlerOptions{EnvelopeFirst: first, AddSource: true, OTELSeverity: true, StrictSlogCompliance: strict}
+				log(slog.New(NewJsonHandler(&out, opts)))
+				line := out.String()
+				timePos, attrPos := strings.Index(line, `"time"`), strings.Index(line, `"attr"`)
+				if timePos < 0 || attrPos < 0 {
+					t.Fatalf("%s: missing time or attr in %s", name, line)
+				}
+				if first != (timePos < attrPos) {
+					t.Errorf("%s: unexpected position of time relative to attr with EnvelopeFirst=%v, got %s", name, first, line)
+				}
+				if sevPos, callerPos := strings.Index(line, `"`+OTELSeverityNumberKey+`"`), strings.Index(line, `"caller"`); first && (sevPos > attrPos || callerPos > attrPos) {
+					t.Errorf("%s: expected the severity and the caller before the attributes, got %s", name, line)
+				}
+				outputs[first] = line
+			}
+			var unordered, ordered map[string]any
+			if err := json.Unmarshal([]byte(outputs[false]), &unordered); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(outputs[true]), &ordered); err != nil {
+				t.Fatal(err)
+			}
+			if !jsonEqual(withoutTimeMap(unordered), withoutTimeMap(ordered)) {
+				t.Errorf("%s: expected the same content in both modes, got\n%s%s", name, outputs[false], outputs[true])
+			}
+		}
+	}
+}
+
+// withoutTimeMap removes the time field of a decoded record.
+func withoutTimeMap(m map[string]any) map[string]any {
+	delete(m, "time")
+	return m
+}
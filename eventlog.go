@@ -0,0 +1,136 @@
+package zeroslog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+)
+
+// eventLogID is the event identifier used for all the reported events.
+const eventLogID = 1
+
+// eventLog is the subset of golang.org/x/sys/windows/svc/eventlog.Log used by the event log handler.
+type eventLog interface {
+	Info(eid uint32, msg string) error
+	Warning(eid uint32, msg string) error
+	Error(eid uint32, msg string) error
+	Close() error
+}
+
+// EventLogHandler is a handler reporting records to the Windows Event Log, created with NewEventLogHandler.
+type EventLogHandler struct {
+	*eventLogHandler
+}
+
+// Close closes the event log. Records handled afterwards, including by the handlers derived from h,
+// are dropped and ErrClosed is returned.
+func (h *EventLogHandler) Close() error {
+	if h.closed.Swap(true) {
+		return nil
+	}
+	return h.log.Close()
+}
+
+// eventLogHandler is an slog.Handler reporting records to the Windows Event Log.
+type eventLogHandler struct {
+	opts     *HandlerOptions
+	pipe     *pipeline
+	log      eventLog
+	closed   *atomic.Bool
+	minLevel slog.Level
+	prefix   string
+	attrs    string
+}
+
+// newEventLogHandler creates a handler reporting records at minLevel or above to log.
+func newEventLogHandler(log eventLog, minLevel slog.Level, opts *HandlerOptions) *eventLogHandler {
+	opt := newConfig(opts)
+	return &eventLogHandler{
+		opts:     opt,
+		pipe:     newPipeline(opt, nil, nil),
+		log:      log,
+		closed:   new(atomic.Bool),
+		minLevel: minLevel,
+	}
+}
+
+// Enabled implements slog.Handler.
+func (h *eventLogHandler) Enabled(_ context.Context, lvl slog.Level) bool {
+	if h.opts.Level != nil && lvl < h.opts.Level.Level() {
+		return false
+	}
+	return lvl >= h.minLevel
+}
+
+// Handle implements slog.Handler.
+// Records below slog.LevelWarn are reported as information events, records below slog.LevelError
+// as warning events, and other records as error events.
+func (h *eventLogHandler) Handle(ctx context.Context, rec slog.Record) error {
+	if !h.Enabled(ctx, rec.Level) {
+		return nil
+	}
+	if h.closed.Load() {
+		return ErrClosed
+	}
+	sb := strings.Builder{}
+	sb.WriteString(rec.Message)
+	sb.WriteString(h.attrs)
+	rec.Attrs(func(a slog.Attr) bool {
+		if a, ok := h.pipe.attr(h.prefix, a); ok {
+			appendEventLogAttr(&sb, h.prefix, a)
+		}
+		return true
+	})
+	if h.opts.AddSource && rec.PC > 0 {
+		frame := recordSource(h.opts, rec.PC).frame
+		fmt.Fprintf(&sb, " source=%s:%d", frame.File, frame.Line)
+	}
+
+	switch msg := sb.String(); {
+	case rec.Level < slog.LevelWarn:
+		return h.log.Info(eventLogID, msg)
+	case rec.Level < slog.LevelError:
+		return h.log.Warning(eventLogID, msg)
+	default:
+		return h.log.Error(eventLogID, msg)
+	}
+}
+
+// WithAttrs implements slog.Handler.
+func (h *eventLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	sb := strings.Builder{}
+	sb.WriteString(h.attrs)
+	for _, a := range h.pipe.attrs(h.prefix, attrs) {
+		appendEventLogAttr(&sb, h.prefix, a)
+	}
+	h2 := *h
+	h2.attrs = sb.String()
+	return &h2
+}
+
+// WithGroup implements slog.Handler.
+func (h *eventLogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
+
+// appendEventLogAttr appends a to sb as " key=value", flattening groups with dots.
+func appendEventLogAttr(sb *strings.Builder, prefix string, a slog.Attr) {
+	value := a.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix = prefix + a.Key + "."
+		}
+		for _, attr := range value.Group() {
+			appendEventLogAttr(sb, prefix, attr)
+		}
+		return
+	}
+	fmt.Fprintf(sb, " %s%s=%q", prefix, a.Key, value.String())
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package zeroslog
+
+import (
+	"errors"
+	"log/slog"
+)
+
+// NewEventLogHandler is only supported on Windows. On other platforms, it always returns an error.
+func NewEventLogHandler(source string, minLevel slog.Level, opts *HandlerOptions) (*EventLogHandler, error) {
+	return nil, errors.New("zeroslog: the Windows Event Log is only available on Windows")
+}
@@ -0,0 +1,106 @@
+package zeroslog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"runtime"
+	"testing"
+)
+
+type event struct {
+	typ string
+	msg string
+}
+
+// fakeEventLog records the reported events.
+type fakeEventLog struct {
+	events []event
+	closed int
+}
+
+func (l *fakeEventLog) Info(_ uint32, msg string) error {
+	l.events = append(l.events, event{"info", msg})
+	return nil
+}
+
+func (l *fakeEventLog) Warning(_ uint32, msg string) error {
+	l.events = append(l.events, event{"warning", msg})
+	return nil
+}
+
+func (l *fakeEventLog) Error(_ uint32, msg string) error {
+	l.events = append(l.events, event{"error", msg})
+	return nil
+}
+
+func (l *fakeEventLog) Close() error {
+	l.closed++
+	return nil
+}
+
+func TestEventLogHandler(t *testing.T) {
+	log := &fakeEventLog{}
+	hdl := slog.Handler(newEventLogHandler(log, slog.LevelInfo, nil))
+	hdl = hdl.WithAttrs([]slog.Attr{slog.String("svc", "api")}).WithGroup("req")
+	for _, lvl := range []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError, slog.LevelError + 4} {
+		if !hdl.Enabled(context.Background(), lvl) {
+			continue
+		}
+		rec := slog.NewRecord(now, lvl, "foobar", 0)
+		rec.AddAttrs(slog.Int("status", 500), slog.Group("user", slog.String("name", "bob smith")))
+		if err := hdl.Handle(context.Background(), rec); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+
+	expected := []event{
+		{"info", `foobar svc="api" req.status="500" req.user.name="bob smith"`},
+		{"warning", `foobar svc="api" req.status="500" req.user.name="bob smith"`},
+		{"error", `foobar svc="api" req.status="500" req.user.name="bob smith"`},
+		{"error", `foobar svc="api" req.status="500" req.user.name="bob smith"`},
+	}
+	if len(log.events) != len(expected) {
+		t.Fatalf("Unexpected events %v", log.events)
+	}
+	for i := range expected {
+		if log.events[i] != expected[i] {
+			t.Errorf("Unexpected event %v, expected %v", log.events[i], expected[i])
+		}
+	}
+}
+
+func TestEventLogHandler_AddSource(t *testing.T) {
+	log := &fakeEventLog{}
+	hdl := newEventLogHandler(log, slog.LevelWarn, &HandlerOptions{AddSource: true})
+	pc, _, _, _ := runtime.Caller(0)
+	hdl.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "filtered", pc))
+	hdl.Handle(context.Background(), slog.NewRecord(now, slog.LevelWarn, "foobar", pc))
+	if len(log.events) != 1 || log.events[0].typ != "warning" {
+		t.Fatalf("Unexpected events %v", log.events)
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if exp := "foobar source=" + frame.File; log.events[0].msg[:len(exp)] != exp {
+		t.Fatalf("Unexpected message %q", log.events[0].msg)
+	}
+}
+
+func TestEventLogHandler_Close(t *testing.T) {
+	log := &fakeEventLog{}
+	hdl := &EventLogHandler{newEventLogHandler(log, slog.LevelInfo, nil)}
+	child := hdl.WithAttrs([]slog.Attr{slog.String("svc", "api")})
+	if err := hdl.Close(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := hdl.Close(); err != nil || log.closed != 1 {
+		t.Fatalf("Expected the event log to be closed once, got %d closes and %v", log.closed, err)
+	}
+	for _, h := range []slog.Handler{hdl, child} {
+		if err := h.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "foobar", 0)); !errors.Is(err, ErrClosed) {
+			t.Errorf("Expected ErrClosed, got %v", err)
+		}
+	}
+	if len(log.events) != 0 {
+		t.Errorf("Unexpected events %v", log.events)
+	}
+}
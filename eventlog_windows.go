@@ -0,0 +1,26 @@
+//go:build windows
+
+package zeroslog
+
+import (
+	"log/slog"
+
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// NewEventLogHandler creates a handler reporting records at minLevel or above to the Windows Event Log,
+// under the given event source. The source must have been registered beforehand,
+// for instance with golang.org/x/sys/windows/svc/eventlog.InstallAsEventCreate.
+//
+// The event text is made of the record message, followed by the attributes as key="value" pairs,
+// with groups flattened with dots. Attribute related options, like AllowKeys or UnitCoercion,
+// apply to the attributes before they are flattened.
+//
+// Close must be called to close the event log.
+func NewEventLogHandler(source string, minLevel slog.Level, opts *HandlerOptions) (*EventLogHandler, error) {
+	log, err := eventlog.Open(source)
+	if err != nil {
+		return nil, err
+	}
+	return &EventLogHandler{newEventLogHandler(log, minLevel, opts)}, nil
+}
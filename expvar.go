@@ -0,0 +1,74 @@
+package zeroslog
+
+import "expvar"
+
+// CounterCollector is implemented by types exposing counters, and can be adapted to
+// metrics libraries such as the Prometheus client without depending on them.
+type CounterCollector interface {
+	// Collect calls fn with the name, description and current value of each counter.
+	Collect(fn func(name, help string, value uint64))
+}
+
+// Collector exposes the counters of a handler. See Handler.Collector.
+type Collector struct {
+	stats *stats
+}
+
+var _ CounterCollector = Collector{}
+
+// counter describes a counter exported by Collector.
+type counter struct {
+	name string
+	help string
+	get  func(*stats) uint64
+}
+
+// counters lists the counters exported by Collector and PublishExpvar.
+var counters = []counter{
+	{"emitted", "Number of records sent to the zerolog logger.", func(s *stats) uint64 { return s.emitted.Load() }},
+	{"dropped", "Number of records dropped after emission started.", func(s *stats) uint64 { return s.dropped.Load() }},
+	{"timed_out", "Number of records dropped because of a write timeout.", func(s *stats) uint64 { return s.timedOut.Load() }},
+	{"suppressed", "Number of repeated records suppressed.", func(s *stats) uint64 { return s.suppressed.Load() }},
+	{"dropped_attrs", "Number of attributes dropped because they are not allowed.", func(s *stats) uint64 { return s.droppedAttrs.Load() }},
+	{"overflow_keys", "Number of attributes rewritten because of too many unique keys.", func(s *stats) uint64 { return s.overflowKeys.Load() }},
+	{"invalid_utf8", "Number of strings with invalid UTF-8 sequences replaced.", func(s *stats) uint64 { return s.invalidUTF8.Load() }},
+	{"errors", "Number of errors reported to OnError.", func(s *stats) uint64 { return s.errors.Load() }},
+}
+
+// Collect implements CounterCollector.
+func (c Collector) Collect(fn func(name, help string, value uint64)) {
+	for _, ctr := range counters {
+		fn(ctr.name, ctr.help, ctr.get(c.stats))
+	}
+}
+
+// Collector returns a CounterCollector exposing the handler counters, which are shared with
+// all the handlers derived from h.
+func (h *Handler) Collector() Collector {
+	return Collector{stats: h.counterStats()}
+}
+
+// PublishExpvar publishes the handler counters as expvar variables named after prefix,
+// such as "<prefix>.emitted" or "<prefix>.errors". Values are read when the variables are.
+// When MeasureLatency is set, the latency histogram is published as "<prefix>.latency", a map
+// from bucket upper bounds (or "+Inf") to counts.
+// Like expvar.Publish, it panics if a variable with the same name is already published, so a prefix
+// can only be published once per process, even by handlers that are no longer used.
+func (h *Handler) PublishExpvar(prefix string) {
+	s := h.counterStats()
+	for _, ctr := range counters {
+		get := ctr.get
+		expvar.Publish(prefix+"."+ctr.name, expvar.Func(func() any { return get(s) }))
+	}
+	if s.latency != nil {
+		expvar.Publish(prefix+".latency", expvar.Func(func() any {
+			hist := s.latencySnapshot()
+			m := make(map[string]uint64, len(hist))
+			for i, bound := range LatencyBuckets {
+				m[bound.String()] = hist[i]
+			}
+			m["+Inf"] = hist[len(LatencyBuckets)]
+			return m
+		}))
+	}
+}
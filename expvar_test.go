@@ -0,0 +1,57 @@
+package zeroslog
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// expvarPrefixes numbers the prefixes returned by expvarPrefix.
+var expvarPrefixes atomic.Int32
+
+// expvarPrefix returns a new expvar prefix starting with name, as published names can't be reused when tests
+// run several times, like with go test -count=2.
+func expvarPrefix(name string) string {
+	return fmt.Sprintf("%s_%d", name, expvarPrefixes.Add(1))
+}
+
+func TestPublishExpvar(t *testing.T) {
+	prefix := expvarPrefix("zeroslog_test_expvar")
+	hdl := NewJsonHandler(io.Discard, &HandlerOptions{SuppressRepeats: time.Minute})
+	hdl.PublishExpvar(prefix)
+	child := hdl.WithAttrs([]slog.Attr{slog.String("foo", "bar")})
+	for i := 0; i < 3; i++ {
+		child.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "foobar", 0))
+	}
+
+	for name, exp := range map[string]uint64{"emitted": 1, "suppressed": 2, "dropped": 0, "errors": 0, "timed_out": 0} {
+		v := expvar.Get(prefix + "." + name)
+		if v == nil {
+			t.Fatalf("Variable %s not published", name)
+		}
+		if got := v.(expvar.Func).Value(); got != exp {
+			t.Errorf("Unexpected value %v for %s, expected %d", got, name, exp)
+		}
+	}
+}
+
+func TestCollector(t *testing.T) {
+	hdl := NewJsonHandler(io.Discard, nil)
+	hdl.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "foobar", 0))
+	var c CounterCollector = hdl.Collector()
+	values := map[string]uint64{}
+	c.Collect(func(name, help string, value uint64) {
+		if help == "" {
+			t.Errorf("Missing help for %s", name)
+		}
+		values[name] = value
+	})
+	if len(values) != len(counters) || values["emitted"] != 1 {
+		t.Fatalf("Unexpected values %v", values)
+	}
+}
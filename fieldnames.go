@@ -0,0 +1,52 @@
+package zeroslog
+
+import "github.com/rs/zerolog"
+
+// FieldNames are the names of the fields written by a handler itself. Empty names fall back to zerolog's
+// global field names, read when records are written.
+//
+// The handler writes errors under the key of their attribute, so zerolog.ErrorFieldName doesn't apply.
+type FieldNames struct {
+	// Level overrides zerolog.LevelFieldName. As zerolog always writes the level of events under
+	// zerolog.LevelFieldName, records are then written like with OmitLevel, as zerolog.NoLevel events,
+	// followed by the level field: hooks, samplers, level writers and OnRecordSize see zerolog.NoLevel.
+	Level string
+	// Time overrides zerolog.TimestampFieldName.
+	Time string
+	// Message overrides zerolog.MessageFieldName.
+	Message string
+	// Caller overrides zerolog.CallerFieldName, for the source written with AddSource.
+	Caller string
+}
+
+// level returns the name of the level field.
+func (n *FieldNames) level() string {
+	if n.Level != "" {
+		return n.Level
+	}
+	return zerolog.LevelFieldName
+}
+
+// time returns the name of the timestamp field.
+func (n *FieldNames) time() string {
+	if n.Time != "" {
+		return n.Time
+	}
+	return zerolog.TimestampFieldName
+}
+
+// message returns the name of the message field.
+func (n *FieldNames) message() string {
+	if n.Message != "" {
+		return n.Message
+	}
+	return zerolog.MessageFieldName
+}
+
+// caller returns the name of the caller field.
+func (n *FieldNames) caller() string {
+	if n.Caller != "" {
+		return n.Caller
+	}
+	return zerolog.CallerFieldName
+}
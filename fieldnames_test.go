@@ -0,0 +1,86 @@
+package zeroslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestFieldNames(t *testing.T) {
+	for name, tc := range map[string]struct {
+		names FieldNames
+		keys  string
+	}{
+		"custom":  {FieldNames{Level: "severity", Time: "ts", Message: "msg", Caller: "src"}, "field_msg,g,message,msg,severity,src,ts"},
+		"other":   {FieldNames{Level: "lvl", Time: "@timestamp", Message: "text", Caller: "at"}, "@timestamp,at,g,lvl,message,msg,text"},
+		"partial": {FieldNames{Message: "text"}, "caller,g,level,message,msg,text,time"},
+		"default": {FieldNames{}, "caller,field_message,g,level,message,msg,time"},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var out syncBuffer
+			logger := slog.New(NewJsonHandler(&out, &HandlerOptions{
+				FieldNames:        tc.names,
+				AddSource:         true,
+				ReservedKeyPolicy: ReservedKeyRename,
+			}))
+			wg := sync.WaitGroup{}
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					logger.WithGroup("g").Warn("hello", "i", i)
+					logger.Warn("hello", "msg", "attr", "message", "attr")
+				}(i)
+			}
+			wg.Wait()
+
+			lines := strings.Split(strings.TrimSpace(out.buf.String()), "\n")
+			if len(lines) != 40 {
+				t.Fatalf("Expected 40 records, got %d", len(lines))
+			}
+			keys := map[string]bool{}
+			for _, line := range lines {
+				var m map[string]any
+				if err := json.Unmarshal([]byte(line), &m); err != nil {
+					t.Fatalf("Invalid record %q: %s", line, err)
+				}
+				for k := range m {
+					keys[k] = true
+				}
+				if lvl := m[tc.names.level()]; lvl != "warn" {
+					t.Errorf("Unexpected level %v in %s", lvl, line)
+				}
+				if msg := m[tc.names.message()]; msg != "hello" {
+					t.Errorf("Unexpected message %v in %s", msg, line)
+				}
+			}
+			got := make([]string, 0, len(keys))
+			for k := range keys {
+				got = append(got, k)
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != tc.keys {
+				t.Errorf("Got keys %v, expected %s", got, tc.keys)
+			}
+		})
+	}
+}
+
+func TestFieldNames_Schema(t *testing.T) {
+	hdl := NewJsonHandler(&bytes.Buffer{}, &HandlerOptions{FieldNames: FieldNames{Level: "severity", Time: "ts", Message: "msg"}})
+	schema := hdl.Schema()
+	for _, key := range []string{"severity", "ts", "msg"} {
+		if _, ok := schema[key]; !ok {
+			t.Errorf("Missing %s in schema %v", key, schema)
+		}
+	}
+	if len(schema) != 3 {
+		t.Errorf("Unexpected schema %v", schema)
+	}
+}
@@ -0,0 +1,223 @@
+package zeroslog
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"time"
+)
+
+// fingerprint is a running FNV-1a 64-bit hash. Since its state is the hash itself,
+// a derived handler can extend the fingerprint of its parent.
+type fingerprint uint64
+
+const (
+	fingerprintOffset fingerprint = 14695981039346656037
+	fingerprintPrime  fingerprint = 1099511628211
+)
+
+// Markers separating the different items hashed into a fingerprint.
+const (
+	fingerprintAttr byte = iota + 1
+	fingerprintGroup
+	fingerprintOptions
+	fingerprintComponent
+)
+
+// byte hashes b.
+func (f fingerprint) byte(b byte) fingerprint {
+	return (f ^ fingerprint(b)) * fingerprintPrime
+}
+
+// uint64 hashes v.
+func (f fingerprint) uint64(v uint64) fingerprint {
+	for i := 0; i < 8; i++ {
+		f = f.byte(byte(v >> (8 * i)))
+	}
+	return f
+}
+
+// bool hashes v.
+func (f fingerprint) bool(v bool) fingerprint {
+	if v {
+		return f.byte(1)
+	}
+	return f.byte(0)
+}
+
+// string hashes s, prefixed with its length so that consecutive strings are unambiguous.
+func (f fingerprint) string(s string) fingerprint {
+	f = f.uint64(uint64(len(s)))
+	for i := 0; i < len(s); i++ {
+		f = f.byte(s[i])
+	}
+	return f
+}
+
+// strings hashes ss, in the given order.
+func (f fingerprint) strings(ss []string) fingerprint {
+	f = f.uint64(uint64(len(ss)))
+	for _, s := range ss {
+		f = f.string(s)
+	}
+	return f
+}
+
+// attrs hashes attributes added with WithAttrs, which must already be resolved.
+func (f fingerprint) attrs(attrs []slog.Attr) fingerprint {
+	for _, a := range attrs {
+		f = f.byte(fingerprintAttr).attr(a)
+	}
+	return f
+}
+
+// attr hashes the key, kind and value of a.
+func (f fingerprint) attr(a slog.Attr) fingerprint {
+	f = f.string(a.Key).byte(byte(a.Value.Kind()))
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		group := a.Value.Group()
+		f = f.uint64(uint64(len(group)))
+		for _, ga := range group {
+			f = f.attr(ga)
+		}
+		return f
+	case slog.KindTime:
+		return f.string(a.Value.Time().Format(time.RFC3339Nano))
+	case slog.KindAny:
+		return f.string(fmt.Sprintf("%T:%+v", a.Value.Any(), a.Value.Any()))
+	default:
+		return f.string(a.Value.String())
+	}
+}
+
+// group hashes the name of a group added with WithGroup.
+func (f fingerprint) group(name string) fingerprint {
+	return f.byte(fingerprintGroup).string(name)
+}
+
+// options hashes the configuration of a handler.
+func (f fingerprint) options(opts *HandlerOptions) fingerprint {
+	var logstash LogstashOptions
+	if opts.LogstashFormat != nil {
+		logstash = *opts.LogstashFormat
+	}
+	f = f.byte(fingerprintOptions).
+		string(opts.WriterLabel).
+		bool(opts.AddSource).
+		strings(opts.SourceSkipPackages).
+		uint64(uint64(opts.SourceFormat)).
+		bool(opts.AddDeltaTime).
+		bool(opts.AddServiceName).
+		string(opts.ServiceName).
+		bool(opts.AddContextInfo).
+		bool(opts.AllowContextMirror).
+		string(opts.ComponentKey).
+		bool(opts.DedupKeys).
+		uint64(uint64(len(opts.DefaultAttrs))).
+		attrs(resolveAttrs(opts.DefaultAttrs)).
+		strings(opts.AllowKeys).
+		strings(opts.AuditKeys).
+		leveler(opts.AuditLevel).
+		bool(opts.DropEmptyKeys).
+		strings(opts.DropMessages).
+		bool(opts.EmitSchemaOnStart).
+		bool(opts.EnvelopeFirst).
+		bool(opts.ExpandMessage).
+		string(opts.FieldNames.Level).
+		string(opts.FieldNames.Time).
+		string(opts.FieldNames.Message).
+		string(opts.FieldNames.Caller).
+		uint64(uint64(opts.FlushRetries)).
+		uint64(uint64(len(opts.Hooks))).
+		uint64(uint64(opts.InternStrings)).
+		uint64(uint64(opts.MaxAttrDepth)).
+		uint64(uint64(opts.MaxAttrWidth)).
+		uint64(uint64(opts.MaxSliceLen)).
+		uint64(uint64(opts.MaxUniqueKeys)).
+		leveler(opts.Level).
+		bool(opts.LogConfigOnFirstUse).
+		bool(opts.LogstashFormat != nil).
+		string(logstash.Dataset).
+		string(logstash.Module).
+		string(logstash.AttrsKey).
+		bool(opts.OmitLevel).
+		strings(opts.OnlyMessages).
+		bool(opts.OTELSeverity).
+		bool(opts.ReplaceAttr != nil).
+		uint64(uint64(opts.ReservedKeyPolicy)).
+		strings(opts.SampleAttrs.Keys).
+		uint64(math.Float64bits(opts.SampleAttrs.Rate)).
+		bool(opts.StrictSlogCompliance).
+		bool(opts.StrictEmission).
+		strings(opts.StringifyValues).
+		bool(opts.TagProvenance).
+		bool(opts.TrustLoggerTimestamps).
+		bool(opts.ValidateUTF8).
+		uint64(uint64(opts.SuppressRepeats)).
+		uint64(uint64(opts.SummaryInterval)).
+		bool(opts.MeasureLatency).
+		bool(opts.OnRecordSize != nil).
+		bool(opts.OnError != nil).
+		bool(opts.OnRecordError != nil).
+		bool(opts.WarnOnDuplicateKeys).
+		uint64(uint64(opts.WriteTimeout))
+	levels := make([]int, 0, len(opts.LevelAttrs))
+	for lvl := range opts.LevelAttrs {
+		levels = append(levels, int(lvl))
+	}
+	slices.Sort(levels)
+	f = f.uint64(uint64(len(levels)))
+	for _, lvl := range levels {
+		f = f.uint64(uint64(lvl))
+	}
+	values := make([]string, 0, len(opts.AttrLevelOverrides.Levels))
+	for v := range opts.AttrLevelOverrides.Levels {
+		values = append(values, v)
+	}
+	slices.Sort(values)
+	f = f.string(opts.AttrLevelOverrides.Key).uint64(uint64(len(values)))
+	for _, v := range values {
+		f = f.string(v).leveler(opts.AttrLevelOverrides.Levels[v])
+	}
+	suffixes := make([]string, 0, len(opts.UnitCoercion))
+	for suffix := range opts.UnitCoercion {
+		suffixes = append(suffixes, suffix)
+	}
+	slices.Sort(suffixes)
+	f = f.uint64(uint64(len(suffixes)))
+	for _, suffix := range suffixes {
+		f = f.string(suffix).uint64(uint64(opts.UnitCoercion[suffix]))
+	}
+	return f
+}
+
+// leveler hashes whether l is set, and its current level.
+func (f fingerprint) leveler(l slog.Leveler) fingerprint {
+	if l == nil {
+		return f.bool(false)
+	}
+	return f.bool(true).uint64(uint64(int64(l.Level())))
+}
+
+// Fingerprint returns a hash of the configuration of the handler, so that handlers built
+// the same way, possibly by different derivation chains, can be deduplicated by caching layers.
+//
+// It covers the options, the level of the wrapped logger, and the attributes and groups added
+// with WithAttrs and WithGroup, in order. Adding attributes in one or several WithAttrs calls gives
+// the same fingerprint. Attribute values of kind slog.KindAny are compared by their type and their
+// formatting with the %+v verb.
+//
+// Outputs can't be compared: the writer is only identified by opts.WriterLabel, and the fields
+// already in the context of the wrapped logger are not covered. Hooks are compared by their number,
+// LevelAttrs by their levels, OnError, OnRecordSize and ReplaceAttr by whether they are set, and levelers by their
+// level at the time of the call.
+func (h *Handler) Fingerprint() uint64 {
+	return uint64(h.chain.options(h.options()).uint64(uint64(int64(h.logger.GetLevel()))))
+}
+
+// Fingerprint returns a hash of the configuration of the handler. See Handler.Fingerprint.
+func (h *groupHandler) Fingerprint() uint64 {
+	return uint64(h.chain.options(h.root.opts).uint64(uint64(int64(h.root.logger.GetLevel()))))
+}
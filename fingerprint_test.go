@@ -0,0 +1,81 @@
+package zeroslog
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func fingerprintOf(t *testing.T, h slog.Handler) uint64 {
+	t.Helper()
+	fp, ok := h.(interface{ Fingerprint() uint64 })
+	if !ok {
+		t.Fatalf("Handler %T has no Fingerprint method", h)
+	}
+	return fp.Fingerprint()
+}
+
+func TestFingerprint_Equal(t *testing.T) {
+	opts := &HandlerOptions{Level: slog.LevelDebug, AllowKeys: []string{"a", "g.*"}, WriterLabel: "stdout"}
+	derive := func(h slog.Handler) slog.Handler {
+		return h.WithAttrs([]slog.Attr{slog.String("tenant", "acme"), slog.Int("a", 1)}).
+			WithGroup("g").
+			WithAttrs([]slog.Attr{slog.Group("sub", slog.Bool("b", true)), slog.Any("err", io.EOF)})
+	}
+	h1 := derive(NewJsonHandler(io.Discard, opts))
+	h2 := derive(NewJsonHandler(io.Discard, opts))
+	if fingerprintOf(t, h1) != fingerprintOf(t, h2) {
+		t.Fatal("Identical chains have different fingerprints")
+	}
+
+	// Using the parent handler before deriving it builds its context, which must not matter.
+	used := NewJsonHandler(io.Discard, opts).WithAttrs([]slog.Attr{slog.String("tenant", "acme")})
+	slog.New(used).Info("warm up")
+	h3 := used.WithAttrs([]slog.Attr{slog.Int("a", 1)}).
+		WithGroup("g").
+		WithAttrs([]slog.Attr{slog.Group("sub", slog.Bool("b", true))}).
+		WithAttrs([]slog.Attr{slog.Any("err", io.EOF)})
+	if fingerprintOf(t, h1) != fingerprintOf(t, h3) {
+		t.Fatal("Equivalent chains have different fingerprints")
+	}
+}
+
+func TestFingerprint_Different(t *testing.T) {
+	base := func(opts *HandlerOptions) slog.Handler {
+		return NewJsonHandler(io.Discard, opts).WithAttrs([]slog.Attr{slog.Int("a", 1)}).WithGroup("g")
+	}
+	ref := fingerprintOf(t, base(nil))
+	for name, h := range map[string]slog.Handler{
+		"attr-value":    NewJsonHandler(io.Discard, nil).WithAttrs([]slog.Attr{slog.Int("a", 2)}).WithGroup("g"),
+		"attr-key":      NewJsonHandler(io.Discard, nil).WithAttrs([]slog.Attr{slog.Int("b", 1)}).WithGroup("g"),
+		"attr-kind":     NewJsonHandler(io.Discard, nil).WithAttrs([]slog.Attr{slog.String("a", "1")}).WithGroup("g"),
+		"group-name":    NewJsonHandler(io.Discard, nil).WithAttrs([]slog.Attr{slog.Int("a", 1)}).WithGroup("h"),
+		"group-order":   NewJsonHandler(io.Discard, nil).WithGroup("g").WithAttrs([]slog.Attr{slog.Int("a", 1)}),
+		"no-group":      NewJsonHandler(io.Discard, nil).WithAttrs([]slog.Attr{slog.Int("a", 1)}),
+		"nested-group":  base(nil).WithGroup("h"),
+		"more-attrs":    base(nil).WithAttrs([]slog.Attr{slog.Int("b", 1)}),
+		"level":         base(&HandlerOptions{Level: slog.LevelDebug}),
+		"add-source":    base(&HandlerOptions{AddSource: true}),
+		"allow-keys":    base(&HandlerOptions{AllowKeys: []string{"a"}}),
+		"unit-coercion": base(&HandlerOptions{UnitCoercion: map[string]Unit{"_ms": UnitMilliseconds}}),
+		"suppress":      base(&HandlerOptions{SuppressRepeats: time.Second}),
+		"writer-label":  base(&HandlerOptions{WriterLabel: "stderr"}),
+		"strict":        base(&HandlerOptions{StrictSlogCompliance: true}),
+		"logger-level":  NewHandler(NewJsonHandler(io.Discard, nil).logger.Level(0), nil).WithAttrs([]slog.Attr{slog.Int("a", 1)}).WithGroup("g"),
+	} {
+		if fingerprintOf(t, h) == ref {
+			t.Errorf("%s: Different handlers have the same fingerprint", name)
+		}
+	}
+}
+
+func TestFingerprint_UnitCoercionOrder(t *testing.T) {
+	units := map[string]Unit{"_ms": UnitMilliseconds, "_s": UnitSeconds, "_bytes": UnitBytes, "_us": UnitMicroseconds}
+	ref := NewJsonHandler(io.Discard, &HandlerOptions{UnitCoercion: units}).Fingerprint()
+	for i := 0; i < 10; i++ {
+		if NewJsonHandler(io.Discard, &HandlerOptions{UnitCoercion: units}).Fingerprint() != ref {
+			t.Fatal("Fingerprint depends on the map iteration order")
+		}
+	}
+}
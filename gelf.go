@@ -0,0 +1,172 @@
+package zeroslog
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// gelfVersion is the version of the GELF specification implemented by GELF handlers.
+const gelfVersion = "1.1"
+
+// gelfHandler is an slog.Handler writing records in Graylog Extended Log Format.
+type gelfHandler struct {
+	opts   *HandlerOptions
+	pipe   *pipeline
+	limits attrLimits
+	logger zerolog.Logger
+	host   string
+	prefix string
+}
+
+// NewGELFHandler creates a handler writing records to out as GELF 1.1 JSON payloads.
+// If host is empty, the name reported by os.Hostname is used.
+//
+// The record message is written as short_message, its time as a float number of seconds in timestamp,
+// and its level as a syslog severity in level. All attributes are written as additional fields:
+// their keys are prefixed with an underscore, and groups are flattened with dots, since GELF only supports
+// flat payloads. Characters GELF doesn't allow in field names, which must match ^[\w\.\-]*$, are replaced with
+// underscores, and an attribute with key "id" is written as "_id_" because "_id" is reserved by GELF.
+// When opts.AddSource is set, the source is written into _file and _line.
+// Attribute related options, like AllowKeys or UnitCoercion, apply to the attributes before they are flattened,
+// except ReservedKeyPolicy, as additional fields never collide.
+//
+// Unless opts.Level is set, records below slog.LevelInfo are discarded.
+func NewGELFHandler(out io.Writer, host string, opts *HandlerOptions) slog.Handler {
+	opt := newConfig(opts)
+	if host == "" {
+		host, _ = os.Hostname()
+	}
+	logger := zerolog.New(out)
+	for _, hook := range opt.Hooks {
+		logger = logger.Hook(hook)
+	}
+	return &gelfHandler{
+		opts:   opt,
+		pipe:   newPipeline(opt, nil, nil),
+		limits: opt.attrLimits(),
+		logger: logger,
+		host:   host,
+	}
+}
+
+// Enabled implements slog.Handler.
+func (h *gelfHandler) Enabled(_ context.Context, lvl slog.Level) bool {
+	minLvl := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLvl = h.opts.Level.Level()
+	}
+	return lvl >= minLvl
+}
+
+// Handle implements slog.Handler.
+func (h *gelfHandler) Handle(ctx context.Context, rec slog.Record) error {
+	evt := h.logger.Log()
+	if evt == nil {
+		return nil
+	}
+	if ctx != nil {
+		evt = evt.Ctx(ctx)
+	}
+	evt.Str("version", gelfVersion).
+		Str("host", h.host).
+		Str("short_message", rec.Message).
+		Int("level", gelfLevel(rec.Level))
+	if !rec.Time.IsZero() {
+		evt.Float64("timestamp", float64(rec.Time.UnixNano())/1e9)
+	}
+	if h.opts.AddSource && rec.PC > 0 {
+		frame := recordSource(h.opts, rec.PC).frame
+		evt.Str("_file", frame.File).Int("_line", frame.Line)
+	}
+	rec.Attrs(func(a slog.Attr) bool {
+		if a, ok := h.pipe.attr(h.prefix, a); ok {
+			mapGELFAttr(evt, h.limits, h.prefix, a)
+		}
+		return true
+	})
+	evt.Send()
+	return nil
+}
+
+// WithAttrs implements slog.Handler.
+func (h *gelfHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	ctx := h.logger.With()
+	for _, a := range h.pipe.attrs(h.prefix, attrs) {
+		ctx = mapGELFAttr(ctx, h.limits, h.prefix, a)
+	}
+	h2 := *h
+	h2.logger = ctx.Logger()
+	return &h2
+}
+
+// WithGroup implements slog.Handler. Group names are normalized as with Handler.WithGroup.
+func (h *gelfHandler) WithGroup(name string) slog.Handler {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
+
+// mapGELFAttr writes a into target as flat GELF additional fields, prefixing keys with prefix.
+// Values are bounded by lim.
+func mapGELFAttr[T zlogWriter[T]](target T, lim attrLimits, prefix string, a slog.Attr) T {
+	value := a.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix = prefix + a.Key + "."
+		}
+		for _, attr := range value.Group() {
+			target = mapGELFAttr(target, lim, prefix, attr)
+		}
+		return target
+	}
+	// Emptiness depends on the key, which must be checked before it's prefixed.
+	if isEmptyAttr(a.Key, value) {
+		return target
+	}
+	return mapAttr(target, lim, slog.Attr{Key: gelfKey(prefix + a.Key), Value: value})
+}
+
+// gelfKey turns an attribute key into a GELF additional field name.
+func gelfKey(key string) string {
+	if key == "id" {
+		return "_id_"
+	}
+	return "_" + strings.Map(gelfKeyRune, key)
+}
+
+// gelfKeyRune returns r if it's allowed in GELF field names, and an underscore otherwise.
+func gelfKeyRune(r rune) rune {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.', r == '-':
+		return r
+	default:
+		return '_'
+	}
+}
+
+// gelfLevel maps slog.Level into a syslog severity.
+func gelfLevel(lvl slog.Level) int {
+	switch {
+	case lvl < slog.LevelInfo:
+		return 7 // Debug
+	case lvl < slog.LevelWarn:
+		return 6 // Informational
+	case lvl < slog.LevelError:
+		return 4 // Warning
+	case lvl < LevelFatal:
+		return 3 // Error
+	case lvl < LevelPanic:
+		return 2 // Critical
+	default:
+		return 0 // Emergency
+	}
+}
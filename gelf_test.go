@@ -0,0 +1,107 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGELFHandler(t *testing.T) {
+	for _, tc := range []struct {
+		lvl    slog.Level
+		syslog float64
+	}{
+		{slog.LevelDebug, 7},
+		{slog.LevelInfo, 6},
+		{slog.LevelWarn, 4},
+		{slog.LevelError, 3},
+		{slog.LevelError + 4, 2},
+	} {
+		t.Run(tc.lvl.String(), func(t *testing.T) {
+			out := bytes.Buffer{}
+			tm := time.Date(2024, 1, 2, 3, 4, 5, 500_000_000, time.UTC)
+			hdl := NewGELFHandler(&out, "myhost", &HandlerOptions{Level: slog.LevelDebug}).
+				WithAttrs([]slog.Attr{slog.String("id", "abc")}).
+				WithGroup(" req ").
+				WithAttrs([]slog.Attr{slog.String("method", "GET")}).
+				WithGroup(" ")
+			rec := slog.NewRecord(tm, tc.lvl, "foobar", 0)
+			rec.AddAttrs(slog.Int("status", 200), slog.Group("user", slog.String("name", "bob"), slog.Group("", slog.Bool("admin", true))))
+			if err := hdl.Handle(context.Background(), rec); err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+
+			expected := map[string]any{
+				"version":         "1.1",
+				"host":            "myhost",
+				"short_message":   "foobar",
+				"timestamp":       1704164645.5,
+				"level":           tc.syslog,
+				"_id_":            "abc",
+				"_req.method":     "GET",
+				"_req.status":     200.0,
+				"_req.user.name":  "bob",
+				"_req.user.admin": true,
+			}
+			m := map[string]any{}
+			if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+				t.Fatalf("Failed to json decode log output: %s", err.Error())
+			}
+			if !reflect.DeepEqual(expected, m) {
+				t.Fatalf("Unexpected fields. Got %v, expected %v", m, expected)
+			}
+		})
+	}
+}
+
+func TestGELFHandler_Level(t *testing.T) {
+	out := bytes.Buffer{}
+	hdl := NewGELFHandler(&out, "", nil)
+	if hdl.Enabled(context.Background(), slog.LevelDebug) || !hdl.Enabled(context.Background(), slog.LevelInfo) {
+		t.Fatalf("Unexpected default level")
+	}
+}
+
+func TestGELFHandler_Keys(t *testing.T) {
+	out := bytes.Buffer{}
+	hdl := NewGELFHandler(&out, "myhost", nil).WithGroup("my group")
+	rec := slog.NewRecord(time.Now(), slog.LevelInfo, "foobar", 0)
+	rec.AddAttrs(
+		slog.Any("", nil),
+		slog.Group("", slog.Any("", nil)),
+		slog.String("user/name", "bob"),
+		slog.String("é-t.é", "summer"),
+		slog.Group("", slog.Int("id", 1)),
+	)
+	if err := hdl.Handle(context.Background(), rec); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	m := map[string]any{}
+	if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+		t.Fatalf("Failed to json decode log output: %s", err.Error())
+	}
+	for _, key := range []string{"version", "host", "short_message", "timestamp", "level"} {
+		delete(m, key)
+	}
+	expected := map[string]any{
+		"_my_group.user_name": "bob",
+		"_my_group._-t._":     "summer",
+		"_my_group.id":        1.0,
+	}
+	if !reflect.DeepEqual(expected, m) {
+		t.Fatalf("Unexpected fields. Got %v, expected %v", m, expected)
+	}
+
+	out.Reset()
+	rec = slog.NewRecord(time.Now(), slog.LevelInfo, "foobar", 0)
+	rec.AddAttrs(slog.Int("id", 1), slog.Any("", nil))
+	NewGELFHandler(&out, "myhost", nil).Handle(context.Background(), rec)
+	if got := out.String(); !strings.Contains(got, `"_id_":1`) || strings.Contains(got, `"_id"`) || strings.Contains(got, `"_":`) {
+		t.Errorf("Expected id to be renamed and the empty attribute to be omitted, got %s", got)
+	}
+}
@@ -0,0 +1,23 @@
+package zeroslog
+
+// GroupPather is implemented by handlers reporting the groups they were derived with, like the handlers
+// returned by Handler.WithGroup, so that wrapping handlers can make decisions based on them.
+type GroupPather interface {
+	// GroupPath returns the names of the groups added with WithGroup, outermost first.
+	GroupPath() []string
+}
+
+var (
+	_ GroupPather = (*Handler)(nil)
+	_ GroupPather = (*groupHandler)(nil)
+)
+
+// GroupPath implements GroupPather. It's always empty, as WithGroup returns a different handler type.
+func (h *Handler) GroupPath() []string {
+	return []string{}
+}
+
+// GroupPath implements GroupPather. The returned slice is a copy which may be modified.
+func (h *groupHandler) GroupPath() []string {
+	return h.groupNames()
+}
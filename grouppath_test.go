@@ -0,0 +1,25 @@
+package zeroslog
+
+import (
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+)
+
+func TestGroupPath(t *testing.T) {
+	var hdl slog.Handler = NewJsonHandler(io.Discard, nil)
+	if path := hdl.(GroupPather).GroupPath(); len(path) != 0 {
+		t.Errorf("Expected an empty path, got %q", path)
+	}
+	hdl = hdl.WithAttrs([]slog.Attr{slog.Int("a", 1)}).WithGroup("sql").WithAttrs([]slog.Attr{slog.Int("b", 2)})
+	hdl = hdl.WithGroup("query").WithGroup("args").WithAttrs([]slog.Attr{slog.Int("c", 3)})
+	path := hdl.(GroupPather).GroupPath()
+	if exp := []string{"sql", "query", "args"}; !slices.Equal(path, exp) {
+		t.Errorf("Expected path %q, got %q", exp, path)
+	}
+	path[0] = "modified"
+	if path := hdl.(GroupPather).GroupPath(); path[0] != "sql" {
+		t.Errorf("Modifying the returned path must not affect the handler, got %q", path)
+	}
+}
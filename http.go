@@ -0,0 +1,77 @@
+package zeroslog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// NewHTTPHandler creates a batching handler which POSTs records to url as
+// newline-delimited JSON, using client or http.DefaultClient if nil.
+//
+// Handle never waits for the network: records are enqueued, and delivered from a background goroutine
+// according to batch. Requests failing with a transport error or a non-2xx status are retried
+// opts.FlushRetries times with batch.Backoff between attempts, after which the batch is dropped,
+// counted in Stats and reported to opts.OnError. A canceled Handle context never aborts delivery,
+// see BatchOptions.MaxPending and BatchOptions.ShutdownTimeout for how contexts and Close are honored.
+func NewHTTPHandler(url string, client *http.Client, batch BatchOptions, opts *HandlerOptions) *BatchingHandler {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	p := &httpPoster{url: url, client: client, headers: batch.Headers.Clone(), gzip: batch.Gzip}
+	return newBatchingHandler(p.post, batch, opts)
+}
+
+// httpPoster sends batches of records in HTTP requests.
+type httpPoster struct {
+	url     string
+	client  *http.Client
+	headers http.Header
+	gzip    bool
+}
+
+// post sends batch in a single NDJSON request body.
+func (p *httpPoster) post(ctx context.Context, batch [][]byte) error {
+	body := bytes.Buffer{}
+	var w io.Writer = &body
+	var zw *gzip.Writer
+	if p.gzip {
+		zw = gzip.NewWriter(&body)
+		w = zw
+	}
+	for _, line := range batch {
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+	}
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, &body)
+	if err != nil {
+		return err
+	}
+	for k, v := range p.headers {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if zw != nil {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("zeroslog: unexpected HTTP status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package zeroslog
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// collector is an HTTP server recording the NDJSON records it receives.
+type collector struct {
+	mu       sync.Mutex
+	failures int
+	requests int
+	bodies   [][]map[string]any
+	headers  []http.Header
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests++
+	if c.failures > 0 {
+		c.failures--
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body = zr
+	}
+	records := []map[string]any{}
+	sc := bufio.NewScanner(body)
+	for sc.Scan() {
+		m := map[string]any{}
+		if err := json.Unmarshal(sc.Bytes(), &m); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		records = append(records, m)
+	}
+	c.bodies = append(c.bodies, records)
+	c.headers = append(c.headers, r.Header.Clone())
+}
+
+func TestHTTPHandler(t *testing.T) {
+	for _, gz := range []bool{false, true} {
+		col := &collector{failures: 2}
+		srv := httptest.NewServer(col)
+		hdl := NewHTTPHandler(srv.URL, srv.Client(), BatchOptions{
+			MaxBatch: 2,
+			Backoff:  time.Millisecond,
+			Headers:  http.Header{"X-Scope-Orgid": {"tenant"}},
+			Gzip:     gz,
+		}, &HandlerOptions{FlushRetries: 3})
+		for _, msg := range []string{"one", "two", "three"} {
+			hdl.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, msg, 0))
+		}
+		if err := hdl.Close(); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		srv.Close()
+
+		if col.requests != 4 {
+			t.Fatalf("Expected 4 requests including 2 retries, got %d", col.requests)
+		}
+		if len(col.bodies) != 2 || len(col.bodies[0]) != 2 || len(col.bodies[1]) != 1 {
+			t.Fatalf("Unexpected bodies %v", col.bodies)
+		}
+		if col.bodies[0][0]["message"] != "one" || col.bodies[1][0]["message"] != "three" {
+			t.Fatalf("Unexpected bodies %v", col.bodies)
+		}
+		for _, h := range col.headers {
+			if h.Get("X-Scope-OrgID") != "tenant" || h.Get("Content-Type") != "application/x-ndjson" {
+				t.Fatalf("Unexpected headers %v", h)
+			}
+		}
+	}
+}
+
+func TestHTTPHandler_Failure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	var reported error
+	hdl := NewHTTPHandler(srv.URL, nil, BatchOptions{MaxBatch: 10}, &HandlerOptions{
+		FlushRetries: 1,
+		OnError:      func(err error) { reported = err },
+	})
+	hdl.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "foobar", 0))
+	hdl.Close()
+	if reported == nil {
+		t.Fatalf("Expected delivery failure to be reported")
+	}
+	if st := hdl.Stats(); st.Dropped != 1 || st.Errors != 1 {
+		t.Fatalf("Unexpected stats %+v", st)
+	}
+}
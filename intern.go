@@ -0,0 +1,78 @@
+package zeroslog
+
+import (
+	"container/list"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+)
+
+// internCache is a bounded cache of strings, evicting the least recently used ones.
+type internCache struct {
+	mu      sync.Mutex
+	max     int
+	lru     *list.List // Most recently used first
+	entries map[string]*list.Element
+}
+
+// newInternCache creates an internCache holding up to max strings, or nil if max is not positive.
+func newInternCache(max int) *internCache {
+	if max <= 0 {
+		return nil
+	}
+	return &internCache{
+		max:     max,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element, max),
+	}
+}
+
+// bytes returns b as a string, reusing the cached string with the same content if any.
+func (c *internCache) bytes(b []byte) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[string(b)]; ok { // No allocation for the map lookup
+		c.lru.MoveToFront(e)
+		return e.Value.(string)
+	}
+	s := string(b)
+	c.entries[s] = c.lru.PushFront(s)
+	if c.lru.Len() > c.max {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(string))
+	}
+	return s
+}
+
+// len returns the number of cached strings.
+func (c *internCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// internValue returns the resolved value v as an interned string value if it would be written
+// as the text returned by its MarshalText method, and v otherwise.
+func (c *internCache) internValue(v slog.Value) slog.Value {
+	if v.Kind() != slog.KindAny {
+		return v
+	}
+	// Types handled before encoding.TextMarshaler by mapAttrAny
+	switch v.Any().(type) {
+	case net.IP, net.IPNet, net.HardwareAddr, error, fmt.Stringer, json.Marshaler:
+		return v
+	}
+	tm, ok := v.Any().(encoding.TextMarshaler)
+	if !ok {
+		return v
+	}
+	txt, err := tm.MarshalText()
+	if err != nil {
+		return v
+	}
+	return slog.StringValue(c.bytes(txt))
+}
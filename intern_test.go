@@ -0,0 +1,85 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// status is an enum written as text.
+type status int
+
+var statusNames = func() [][]byte {
+	names := make([][]byte, 10)
+	for i := range names {
+		names[i] = []byte(fmt.Sprintf("status-%d", i))
+	}
+	return names
+}()
+
+func (s status) MarshalText() ([]byte, error) {
+	return statusNames[s], nil
+}
+
+func TestInternCache(t *testing.T) {
+	c := newInternCache(3)
+	a := c.bytes([]byte("a"))
+	c.bytes([]byte("b"))
+	c.bytes([]byte("c"))
+	if s := c.bytes([]byte("a")); s != a || c.len() != 3 {
+		t.Fatalf("Unexpected cache state: %q, %d entries", s, c.len())
+	}
+	c.bytes([]byte("d")) // Evicts "b", the least recently used
+	if c.len() != 3 {
+		t.Fatalf("Cache must be bounded, got %d entries", c.len())
+	}
+	if _, ok := c.entries["b"]; ok {
+		t.Fatal("Least recently used entry must be evicted")
+	}
+	for _, s := range []string{"a", "c", "d"} {
+		if _, ok := c.entries[s]; !ok {
+			t.Fatalf("Missing entry %q", s)
+		}
+	}
+	if newInternCache(0) != nil {
+		t.Fatal("Cache must be disabled by default")
+	}
+}
+
+func TestInternStrings(t *testing.T) {
+	out := bytes.Buffer{}
+	hdl := NewJsonHandler(&out, &HandlerOptions{InternStrings: 4})
+	for i := 0; i < 10; i++ {
+		out.Reset()
+		rec := slog.NewRecord(now, slog.LevelInfo, "foobar", 0)
+		rec.AddAttrs(slog.Any("status", status(i)), slog.Any("marshaller-err", &marshaller{err: fmt.Errorf("failure")}))
+		hdl.WithGroup("g").Handle(context.Background(), rec)
+		exp := fmt.Sprintf(`"g":{"status":"status-%d","marshaller-err":"!ERROR:failure"}`, i)
+		if !bytes.Contains(out.Bytes(), []byte(exp)) {
+			t.Fatalf("Unexpected output: %s", out.String())
+		}
+	}
+	if n := hdl.pipe.intern.len(); n != 4 {
+		t.Fatalf("Unexpected %d cached strings", n)
+	}
+}
+
+func BenchmarkInternStrings(b *testing.B) {
+	for name, n := range map[string]int{"disabled": 0, "enabled": 16} {
+		b.Run(name, func(b *testing.B) {
+			hdl := NewJsonHandler(io.Discard, &HandlerOptions{InternStrings: n})
+			ctx := context.Background()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "hello", 0)
+				rec.AddAttrs(slog.Any("status", status(i%10)))
+				hdl.Handle(ctx, rec)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+// Package logwrap is a minimal logging wrapper, used to test the source reported
+// for records logged through wrappers.
+package logwrap
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Info logs msg at slog.LevelInfo with logger.
+func Info(logger *slog.Logger, msg string) {
+	logger.Info(msg)
+}
+
+// InfoAsync logs msg at slog.LevelInfo with logger, from another goroutine.
+func InfoAsync(logger *slog.Logger, msg string) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		logger.Log(context.Background(), slog.LevelInfo, msg)
+	}()
+	<-done
+}
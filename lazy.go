@@ -0,0 +1,24 @@
+package zeroslog
+
+import "log/slog"
+
+// lazyValuer is the slog.LogValuer returned by Lazy.
+type lazyValuer func() slog.Value
+
+// LogValue implements slog.LogValuer.
+func (f lazyValuer) LogValue() slog.Value {
+	return f()
+}
+
+// Lazy returns an slog.LogValuer calling f to compute the value of an attribute only when a record
+// is actually written, like in
+//
+//	logger.Debug("request", slog.Any("body", zeroslog.Lazy(func() slog.Value { return slog.StringValue(dump(req)) })))
+//
+// Handlers of this package never call f for records they filter out, and call it once per record
+// written, even when the record is sent to several destinations like with NewDualHandler.
+// f is called again for each record it's attached to, unless it's given to WithAttrs,
+// in which case it's called once by WithAttrs.
+func Lazy(f func() slog.Value) slog.LogValuer {
+	return lazyValuer(f)
+}
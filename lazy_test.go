@@ -0,0 +1,85 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLazy(t *testing.T) {
+	for name, tc := range map[string]struct {
+		handler func(out io.Writer) slog.Handler
+		level   slog.Level
+		calls   int
+		outputs int
+	}{
+		"filtered": {
+			handler: func(out io.Writer) slog.Handler { return NewJsonHandler(out, nil) },
+			level:   slog.LevelDebug,
+		},
+		"emitted": {
+			handler: func(out io.Writer) slog.Handler { return NewJsonHandler(out, nil) },
+			level:   slog.LevelInfo,
+			calls:   1,
+			outputs: 1,
+		},
+		"group-filtered": {
+			handler: func(out io.Writer) slog.Handler { return NewJsonHandler(out, nil).WithGroup("a").WithGroup("b") },
+			level:   slog.LevelDebug,
+		},
+		"group-emitted": {
+			handler: func(out io.Writer) slog.Handler { return NewJsonHandler(out, nil).WithGroup("a").WithGroup("b") },
+			level:   slog.LevelInfo,
+			calls:   1,
+			outputs: 1,
+		},
+		"audit": {
+			handler: func(out io.Writer) slog.Handler {
+				return NewJsonHandler(out, &HandlerOptions{AuditKeys: []string{"actor"}, AuditLevel: slog.LevelInfo}).WithGroup("a")
+			},
+			level:   slog.LevelInfo,
+			calls:   1,
+			outputs: 1,
+		},
+		"multi-filtered": {
+			handler: func(out io.Writer) slog.Handler { return NewDualHandler(out, slog.LevelWarn, out, slog.LevelWarn, nil) },
+			level:   slog.LevelInfo,
+		},
+		"multi-single": {
+			handler: func(out io.Writer) slog.Handler {
+				return NewDualHandler(out, slog.LevelInfo, out, slog.LevelWarn, nil).WithGroup("a")
+			},
+			level:   slog.LevelInfo,
+			calls:   1,
+			outputs: 1,
+		},
+		"multi-both": {
+			handler: func(out io.Writer) slog.Handler {
+				return NewDualHandler(out, slog.LevelInfo, out, slog.LevelInfo, nil).WithGroup("a")
+			},
+			level:   slog.LevelInfo,
+			calls:   1,
+			outputs: 2,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			out := bytes.Buffer{}
+			calls := 0
+			lazy := Lazy(func() slog.Value {
+				calls++
+				return slog.StringValue("expensive")
+			})
+			logger := slog.New(tc.handler(&out))
+			logger.Log(context.Background(), tc.level, "foobar", "lazy", lazy, slog.Group("sub", "nested", lazy))
+			if calls != 2*tc.calls {
+				t.Errorf("Lazy value computed %d times, expected %d", calls, 2*tc.calls)
+			}
+			if n := strings.Count(out.String(), "expensive"); n != 2*tc.outputs {
+				t.Errorf("Lazy value written %d times, expected %d: %s", n, 2*tc.outputs, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package zeroslog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// levelNames are the names of the levels known by this package but not by slog.
+var levelNames = []struct {
+	name string
+	lvl  slog.Level
+}{
+	{"TRACE", LevelTrace},
+	{"FATAL", LevelFatal},
+	{"PANIC", LevelPanic},
+}
+
+// LevelString returns the name of lvl, like slog.Level.String, but also naming
+// LevelTrace, LevelFatal and LevelPanic. For instance, LevelTrace+1 is "TRACE+1".
+func LevelString(lvl slog.Level) string {
+	switch {
+	case lvl >= LevelPanic:
+		return levelName("PANIC", lvl-LevelPanic)
+	case lvl >= LevelFatal:
+		return levelName("FATAL", lvl-LevelFatal)
+	case lvl >= LevelTrace && lvl < slog.LevelDebug:
+		return levelName("TRACE", lvl-LevelTrace)
+	default:
+		return lvl.String()
+	}
+}
+
+// levelName returns name with a signed offset, if not zero.
+func levelName(name string, offset slog.Level) string {
+	if offset == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s%+d", name, offset)
+}
+
+// ParseLevel parses a level name as returned by LevelString, case-insensitively.
+// It also accepts all the names understood by slog.Level.UnmarshalText, like "DEBUG-4" or "info".
+func ParseLevel(s string) (slog.Level, error) {
+	upper := strings.ToUpper(s)
+	for _, ln := range levelNames {
+		offset, ok := strings.CutPrefix(upper, ln.name)
+		if !ok {
+			continue
+		}
+		if offset == "" {
+			return ln.lvl, nil
+		}
+		n, err := strconv.Atoi(offset)
+		if err != nil || (offset[0] != '+' && offset[0] != '-') {
+			return 0, fmt.Errorf("zeroslog: invalid level %q", s)
+		}
+		return ln.lvl + slog.Level(n), nil
+	}
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("zeroslog: invalid level %q", s)
+	}
+	return lvl, nil
+}
+
+// Trace logs a record at LevelTrace with logger, or slog.Default() if logger is nil.
+// args are handled like in slog.Logger.Log. The source of the record is the caller of Trace.
+func Trace(ctx context.Context, logger *slog.Logger, msg string, args ...any) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !logger.Enabled(ctx, LevelTrace) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(2, pcs[:]) // Skip runtime.Callers and Trace
+	rec := slog.NewRecord(time.Now(), LevelTrace, msg, pcs[0])
+	rec.Add(args...)
+	_ = logger.Handler().Handle(ctx, rec)
+}
+
+// TraceEnabled reports whether logger, or slog.Default() if logger is nil, logs records at LevelTrace.
+// It's meant to guard the construction of expensive trace records.
+func TraceEnabled(ctx context.Context, logger *slog.Logger) bool {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return logger.Enabled(ctx, LevelTrace)
+}
+
+// shortcutLevel returns the level of the loggers created by NewJsonHandler and the other shortcut
+// constructors, so that the logger and opts.Level can't disagree: the zerolog level of opts.Level if it's
+// a fixed slog.Level, zerolog.TraceLevel if it's dynamic, like a *slog.LevelVar, leaving the handler alone
+// to filter records, and zerolog.InfoLevel if it's not set.
+func shortcutLevel(opts *HandlerOptions) zerolog.Level {
+	if opts == nil || opts.Level == nil {
+		return zerolog.InfoLevel
+	}
+	if lvl, ok := opts.Level.(slog.Level); ok {
+		return ZerologLevel(lvl)
+	}
+	return zerolog.TraceLevel
+}
+
+// levelThreshold tells which record levels a handler writes. It's computed once per handler,
+// so that Enabled neither converts levels nor, for fixed levels and slog.LevelVar, calls an interface.
+type levelThreshold struct {
+	disabled bool
+	// min is the minimum level, unless levelVar or leveler are set.
+	min slog.Level
+	// levelVar is HandlerOptions.Level if it's a *slog.LevelVar. Its level is an atomic load,
+	// so changes are observed immediately.
+	levelVar *slog.LevelVar
+	// leveler is HandlerOptions.Level if it's neither a slog.Level nor a *slog.LevelVar.
+	leveler slog.Leveler
+}
+
+// newLevelThreshold returns the threshold of a handler wrapping logger with the given level option.
+func newLevelThreshold(logger zerolog.Logger, level slog.Leveler) levelThreshold {
+	if logger.GetLevel() == zerolog.Disabled {
+		return levelThreshold{disabled: true}
+	}
+	switch l := level.(type) {
+	case nil:
+		return levelThreshold{min: minSlogLevel(logger.GetLevel())}
+	case slog.Level:
+		return levelThreshold{min: l}
+	case *slog.LevelVar:
+		return levelThreshold{levelVar: l}
+	default:
+		return levelThreshold{leveler: l}
+	}
+}
+
+// minSlogLevel returns the lowest slog.Level mapped by ZerologLevel to lvl or above.
+// zerolog.NoLevel is treated as zerolog.TraceLevel.
+func minSlogLevel(lvl zerolog.Level) slog.Level {
+	switch {
+	case lvl <= zerolog.TraceLevel || lvl == zerolog.NoLevel:
+		return math.MinInt
+	case lvl >= zerolog.Disabled:
+		return math.MaxInt
+	default:
+		return SlogLevel(lvl)
+	}
+}
+
+// enabled reports whether records at lvl are written.
+func (t *levelThreshold) enabled(lvl slog.Level) bool {
+	switch {
+	case t.disabled:
+		return false
+	case t.levelVar != nil:
+		return lvl >= t.levelVar.Level()
+	case t.leveler != nil:
+		return lvl >= t.leveler.Level()
+	default:
+		return lvl >= t.min
+	}
+}
@@ -0,0 +1,173 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestLevelTrace(t *testing.T) {
+	if lvl := ZerologLevel(LevelTrace); lvl != zerolog.TraceLevel {
+		t.Errorf("Unexpected zerolog level %s for LevelTrace", lvl)
+	}
+	if lvl := SlogLevel(zerolog.TraceLevel); lvl != LevelTrace {
+		t.Errorf("Unexpected slog level %s for zerolog.TraceLevel", lvl)
+	}
+}
+
+func TestLevelString(t *testing.T) {
+	for lvl, name := range map[slog.Level]string{
+		LevelTrace:          "TRACE",
+		LevelTrace + 1:      "TRACE+1",
+		LevelTrace - 2:      "DEBUG-6",
+		slog.LevelDebug:     "DEBUG",
+		slog.LevelInfo + 1:  "INFO+1",
+		slog.LevelError + 2: "ERROR+2",
+		LevelFatal:          "FATAL",
+		LevelFatal + 3:      "FATAL+3",
+		LevelPanic:          "PANIC",
+		LevelPanic + 10:     "PANIC+10",
+	} {
+		if s := LevelString(lvl); s != name {
+			t.Errorf("Unexpected name %q for level %d, expected %q", s, lvl, name)
+		}
+		parsed, err := ParseLevel(name)
+		if err != nil {
+			t.Errorf("Failed to parse %q: %s", name, err)
+		} else if parsed != lvl {
+			t.Errorf("Unexpected level %d parsed from %q, expected %d", parsed, name, lvl)
+		}
+	}
+	for name, lvl := range map[string]slog.Level{"trace": LevelTrace, "Fatal-1": LevelFatal - 1, "debug-4": LevelTrace, "warn": slog.LevelWarn} {
+		if parsed, err := ParseLevel(name); err != nil || parsed != lvl {
+			t.Errorf("Unexpected level %d (err=%v) parsed from %q, expected %d", parsed, err, name, lvl)
+		}
+	}
+	for _, name := range []string{"", "tracer", "trace1", "fatal+x", "verbose"} {
+		if _, err := ParseLevel(name); err == nil {
+			t.Errorf("Expected error parsing %q", name)
+		}
+	}
+}
+
+func TestTrace(t *testing.T) {
+	out := bytes.Buffer{}
+	logger := slog.New(NewHandler(zerolog.New(&out).Level(zerolog.TraceLevel), &HandlerOptions{AddSource: true}))
+	if !TraceEnabled(context.Background(), logger) {
+		t.Fatal("Trace must be enabled")
+	}
+	Trace(context.Background(), logger, "foobar", "foo", "bar")
+	m := map[string]any{}
+	if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+		t.Fatalf("Failed to json decode log output: %s", err.Error())
+	}
+	if m[zerolog.LevelFieldName] != "trace" || m["foo"] != "bar" || m[zerolog.MessageFieldName] != "foobar" {
+		t.Errorf("Unexpected output: %s", out.String())
+	}
+	if caller, _ := m[zerolog.CallerFieldName].(string); !strings.HasPrefix(filepath.Base(caller), "level_test.go:") {
+		t.Errorf("Unexpected caller %q", caller)
+	}
+
+	out.Reset()
+	logger = slog.New(NewJsonHandler(&out, nil))
+	if TraceEnabled(context.Background(), logger) {
+		t.Fatal("Trace must be disabled")
+	}
+	Trace(context.Background(), logger, "foobar")
+	if out.Len() > 0 {
+		t.Errorf("Unexpected output: %s", out.String())
+	}
+}
+
+// funcLeveler is a slog.Leveler which is neither a slog.Level nor a *slog.LevelVar.
+type funcLeveler func() slog.Level
+
+func (f funcLeveler) Level() slog.Level { return f() }
+
+func TestEnabled_LevelVar(t *testing.T) {
+	lvl := &slog.LevelVar{}
+	custom := slog.LevelInfo
+	for name, leveler := range map[string]slog.Leveler{
+		"level-var": lvl,
+		"leveler":   funcLeveler(func() slog.Level { return custom }),
+	} {
+		t.Run(name, func(t *testing.T) {
+			hdl := NewJsonHandler(io.Discard, &HandlerOptions{Level: leveler})
+			group := hdl.WithGroup("g")
+			for _, l := range []slog.Level{slog.LevelInfo, LevelTrace, slog.LevelError, slog.LevelDebug} {
+				lvl.Set(l)
+				custom = l
+				for _, h := range []slog.Handler{hdl, group} {
+					if !h.Enabled(context.Background(), l) || h.Enabled(context.Background(), l-1) {
+						t.Fatalf("Level change to %s not observed", l)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEnabled_LoggerLevel(t *testing.T) {
+	for _, zlvl := range []zerolog.Level{zerolog.TraceLevel, zerolog.DebugLevel, zerolog.InfoLevel, zerolog.WarnLevel,
+		zerolog.ErrorLevel, zerolog.FatalLevel, zerolog.PanicLevel, zerolog.NoLevel, zerolog.Disabled} {
+		hdl := NewHandler(zerolog.New(io.Discard).Level(zlvl), nil)
+		effective := zlvl
+		if zlvl == zerolog.NoLevel {
+			effective = zerolog.TraceLevel
+		}
+		for lvl := LevelTrace - 4; lvl <= LevelPanic+4; lvl++ {
+			exp := zlvl != zerolog.Disabled && ZerologLevel(lvl) >= effective
+			if hdl.Enabled(context.Background(), lvl) != exp {
+				t.Errorf("Unexpected Enabled for level %s with logger level %s, expected %t", lvl, zlvl, exp)
+			}
+		}
+	}
+}
+
+func TestShortcutLevel(t *testing.T) {
+	lvlVar := new(slog.LevelVar)
+	lvlVar.Set(slog.LevelDebug)
+	tests := []struct {
+		name   string
+		level  slog.Leveler
+		logger zerolog.Level
+	}{
+		{"Default", nil, zerolog.InfoLevel},
+		{"Debug", slog.LevelDebug, zerolog.DebugLevel},
+		{"Trace", LevelTrace, zerolog.TraceLevel},
+		{"Custom", slog.LevelInfo + 2, zerolog.InfoLevel},
+		{"LevelVar", lvlVar, zerolog.TraceLevel},
+	}
+	constructors := map[string]func(out io.Writer, opts *HandlerOptions) *Handler{
+		"Json":    NewJsonHandler,
+		"Console": NewConsoleHandler,
+	}
+	for _, test := range tests {
+		for name, newHandler := range constructors {
+			t.Run(name+"_"+test.name, func(t *testing.T) {
+				out := bytes.Buffer{}
+				hdl := newHandler(&out, &HandlerOptions{Level: test.level})
+				if lvl := hdl.logger.GetLevel(); lvl != test.logger {
+					t.Errorf("Expected logger level %s, got %s", test.logger, lvl)
+				}
+				derived := hdl.WithAttrs([]slog.Attr{slog.Int("a", 1)}).WithGroup("g")
+				for lvl := LevelTrace; lvl <= slog.LevelError; lvl++ {
+					out.Reset()
+					logger := slog.New(derived)
+					enabled := logger.Enabled(context.Background(), lvl)
+					logger.Log(context.Background(), lvl, "msg", "b", 2)
+					if written := out.Len() > 0; written != enabled {
+						t.Fatalf("Level %s: Enabled reports %v, but the record is written: %v", LevelString(lvl), enabled, written)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package zeroslog
+
+import (
+	"cmp"
+	"log/slog"
+	"slices"
+)
+
+// levelAttrsFunc is a LevelAttrs callback, with the level from which it applies.
+type levelAttrsFunc struct {
+	level slog.Level
+	attrs func() []slog.Attr
+}
+
+// newLevelAttrs returns the callbacks of m, sorted by increasing level, or nil if m is empty.
+func newLevelAttrs(m map[slog.Level]func() []slog.Attr) []levelAttrsFunc {
+	if len(m) == 0 {
+		return nil
+	}
+	funcs := make([]levelAttrsFunc, 0, len(m))
+	for lvl, f := range m {
+		if f != nil {
+			funcs = append(funcs, levelAttrsFunc{level: lvl, attrs: f})
+		}
+	}
+	slices.SortFunc(funcs, func(a, b levelAttrsFunc) int { return cmp.Compare(a.level, b.level) })
+	return funcs
+}
+
+// appendLevelAttrs calls the LevelAttrs callbacks applying to a record at level lvl, in increasing level order,
+// and appends their resolved attributes to dst. It must only be called for records being written.
+func (h *Handler) appendLevelAttrs(dst []slog.Attr, lvl slog.Level) []slog.Attr {
+	for _, f := range h.levelAttrs {
+		if lvl < f.level {
+			break
+		}
+		for _, a := range f.attrs() {
+			dst = append(dst, resolveAttr(a))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,96 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLevelAttrs(t *testing.T) {
+	calls := map[string]int{}
+	counting := func(name string, attrs ...slog.Attr) func() []slog.Attr {
+		return func() []slog.Attr {
+			calls[name]++
+			return attrs
+		}
+	}
+	out := bytes.Buffer{}
+	logger := slog.New(NewJsonHandler(&out, &HandlerOptions{
+		Level:        slog.LevelInfo,
+		AllowKeys:    []string{"goroutines", "heap", "req.*"},
+		UnitCoercion: map[string]Unit{"heap": UnitBytes},
+		LevelAttrs: map[slog.Level]func() []slog.Attr{
+			slog.LevelDebug: counting("debug", slog.Int("debug", 1)),
+			slog.LevelWarn:  counting("warn", slog.Int("goroutines", 12)),
+			slog.LevelError: counting("error", slog.Float64("heap", 1.6), slog.String("secret", "x")),
+		},
+	})).WithGroup("req")
+
+	for _, tc := range []struct {
+		lvl    slog.Level
+		calls  map[string]int
+		fields map[string]any
+	}{
+		{slog.LevelDebug, map[string]int{}, nil},
+		{slog.LevelInfo, map[string]int{"debug": 1}, map[string]any{}},
+		{slog.LevelWarn, map[string]int{"debug": 2, "warn": 1}, map[string]any{"goroutines": 12.0}},
+		{slog.LevelError + 1, map[string]int{"debug": 3, "warn": 2, "error": 1}, map[string]any{"goroutines": 12.0, "heap": 2.0}},
+	} {
+		out.Reset()
+		logger.Log(context.Background(), tc.lvl, "hello", "id", 1)
+		if len(calls) != len(tc.calls) {
+			t.Fatalf("%s: unexpected calls %v", tc.lvl, calls)
+		}
+		for name, n := range tc.calls {
+			if calls[name] != n {
+				t.Fatalf("%s: unexpected calls %v", tc.lvl, calls)
+			}
+		}
+		if tc.fields == nil {
+			if out.Len() != 0 {
+				t.Fatalf("%s: unexpected output %s", tc.lvl, out.String())
+			}
+			continue
+		}
+		m := map[string]any{}
+		if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+			t.Fatalf("Failed to json decode log output: %s", err.Error())
+		}
+		for _, key := range []string{"level", "time", "message", "req"} {
+			delete(m, key)
+		}
+		if len(m) != len(tc.fields) {
+			t.Fatalf("%s: unexpected output %s", tc.lvl, out.String())
+		}
+		for key, val := range tc.fields {
+			if m[key] != val {
+				t.Fatalf("%s: unexpected output %s", tc.lvl, out.String())
+			}
+		}
+		if !strings.Contains(out.String(), `"req":{"id":1}`) {
+			t.Fatalf("%s: unexpected output %s", tc.lvl, out.String())
+		}
+	}
+}
+
+func TestLevelAttrs_Strict(t *testing.T) {
+	out := bytes.Buffer{}
+	logger := slog.New(NewJsonHandler(&out, &HandlerOptions{
+		StrictSlogCompliance: true,
+		LevelAttrs: map[slog.Level]func() []slog.Attr{
+			slog.LevelError: func() []slog.Attr { return []slog.Attr{slog.Int("a", 2), slog.Int("req", 0)} },
+		},
+	})).With("a", 1)
+	logger.Error("hello", "b", 1)
+	logger.WithGroup("req").Error("hello", "b", 1)
+	exp := []string{`"b":1,"a":2,"req":0,`, `"a":2,"req":{"b":1}`}
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	for i, line := range lines {
+		if strings.Count(line, `"a":`) != 1 || !strings.Contains(line, exp[i]) {
+			t.Fatalf("Unexpected output %s", out.String())
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package zeroslog
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// ErrWriterLoop is returned by the LevelWriter of a handler when the records written to it come back to it,
+// like when the handler writes to its own LevelWriter, directly or through other handlers.
+var ErrWriterLoop = errors.New("zeroslog: records written to a LevelWriter come back to it")
+
+// maxLevelWriterDepth is the number of nested writes to LevelWriters from which they're considered a loop.
+const maxLevelWriterDepth = 4
+
+// levelWriteFunc is the name of levelWriter.WriteLevel, looked for in the stack to detect loops.
+var levelWriteFunc string
+
+func init() {
+	levelWriteFunc = runtime.FuncForPC(reflect.ValueOf((*levelWriter).WriteLevel).Pointer()).Name()
+}
+
+// levelWriter is the zerolog.LevelWriter returned by Handler.LevelWriter.
+type levelWriter struct {
+	h *Handler
+	// active is the number of writes in progress, so that the stack is only inspected for loops
+	// when the writer is re-entered.
+	active atomic.Int32
+}
+
+// LevelWriter returns a zerolog.LevelWriter handling the JSON records written by a zerolog.Logger with h,
+// so that zerolog and slog code share the same options and outputs, like in
+//
+//	logger := zerolog.New(h.LevelWriter()).With().Timestamp().Logger()
+//
+// Records are parsed with ParseEvent, and get the level of the zerolog event, if any, and the current time
+// if they have none. Records below the level of h are discarded. Invalid records and write failures are
+// returned to the zerolog.Logger, which reports them to zerolog.ErrorHandler, and to opts.OnError.
+//
+// Records coming back to the writer, like when h writes to its own LevelWriter, are dropped and
+// ErrWriterLoop is returned, once writes to LevelWriters are nested 4 times.
+func (h *Handler) LevelWriter() zerolog.LevelWriter {
+	return &levelWriter{h: h}
+}
+
+// Write implements io.Writer.
+func (w *levelWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel implements zerolog.LevelWriter.
+func (w *levelWriter) WriteLevel(lvl zerolog.Level, p []byte) (int, error) {
+	defer w.active.Add(-1)
+	if w.active.Add(1) > 1 && levelWriteDepth() >= maxLevelWriterDepth {
+		w.h.reportError(ErrWriterLoop)
+		return 0, ErrWriterLoop
+	}
+	rec, _, err := ParseEvent(p)
+	if err != nil {
+		w.h.reportError(err)
+		return 0, err
+	}
+	if lvl != zerolog.NoLevel {
+		rec.Level = SlogLevel(lvl)
+	}
+	if rec.Time.IsZero() {
+		rec.Time = time.Now()
+	}
+	ctx := context.Background()
+	if !w.h.Enabled(ctx, rec.Level) {
+		return len(p), nil
+	}
+	if err := w.h.Handle(ctx, rec); err != nil {
+		w.h.reportError(err)
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// levelWriteDepth returns the number of levelWriter.WriteLevel calls in the stack of the calling goroutine.
+func levelWriteDepth() int {
+	var pcs [1024]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs[:])])
+	depth := 0
+	for {
+		f, more := frames.Next()
+		if f.Function == levelWriteFunc {
+			depth++
+		}
+		if !more {
+			return depth
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package zeroslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestLevelWriter(t *testing.T) {
+	out := bytes.Buffer{}
+	hdl := NewJsonHandler(&out, &HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == "password" {
+				a.Value = slog.StringValue("REDACTED")
+			}
+			return a
+		},
+		ComponentKey: "comp",
+	})
+	logger := zerolog.New(hdl.Named("legacy").LevelWriter())
+
+	logger.Debug().Str("user", "bob").Str("password", "hunter2").Dict("req", zerolog.Dict().Int("id", 12)).Msg("login")
+	logger.Trace().Msg("filtered")
+
+	var m map[string]any
+	if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+		t.Fatalf("Expected a single JSON record, got %s", out.String())
+	}
+	if _, ok := m["time"]; !ok {
+		t.Errorf("Expected a time field, got %s", out.String())
+	}
+	delete(m, "time")
+	exp := map[string]any{"level": "debug", "message": "login", "user": "bob", "password": "REDACTED", "req": map[string]any{"id": 12.0}, "comp": "legacy"}
+	if !jsonEqual(m, exp) {
+		t.Errorf("Expected %v, got %s", exp, out.String())
+	}
+}
+
+func TestLevelWriter_Invalid(t *testing.T) {
+	var errs []error
+	hdl := NewJsonHandler(&bytes.Buffer{}, &HandlerOptions{OnError: func(err error) { errs = append(errs, err) }})
+	if _, err := hdl.LevelWriter().Write([]byte("not json\n")); err == nil || len(errs) != 1 {
+		t.Errorf("Expected an error for an invalid record, got %v and %v", err, errs)
+	}
+}
+
+func TestLevelWriter_Loop(t *testing.T) {
+	if !strings.HasSuffix(levelWriteFunc, ".(*levelWriter).WriteLevel") {
+		t.Fatalf("Unexpected function name %q", levelWriteFunc)
+	}
+	lw := &switchWriter{}
+	var errs []error
+	hdl := NewJsonHandler(lw, &HandlerOptions{OnError: func(err error) { errs = append(errs, err) }})
+	lw.out = hdl.LevelWriter()
+
+	slog.New(hdl).Info("msg", "a", 1)
+	if len(errs) == 0 || !errors.Is(errs[0], ErrWriterLoop) {
+		t.Errorf("Expected ErrWriterLoop to be reported, got %v", errs)
+	}
+}
+
+// switchWriter forwards writes to out, which is set once the writer is used by a handler.
+type switchWriter struct {
+	out zerolog.LevelWriter
+}
+
+func (w *switchWriter) Write(p []byte) (int, error) {
+	return w.out.Write(p)
+}
@@ -0,0 +1,297 @@
+package zeroslog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog"
+)
+
+// logfmtHandler is an slog.Handler writing records as logfmt lines.
+type logfmtHandler struct {
+	opts   *HandlerOptions
+	pipe   *pipeline
+	limits attrLimits
+	mu     *sync.Mutex
+	out    io.Writer
+	// attrs are the encoded attributes added with WithAttrs.
+	attrs  []byte
+	prefix string
+}
+
+// NewLogfmtHandler creates a handler writing records to out as single logfmt lines, like
+//
+//	time=2024-01-02T15:04:05Z level=info message="hello world" user.id=12 latency=1.5
+//
+// The time, level, caller and message fields are named after zerolog's field names. Attribute values
+// are written like NewJsonHandler does, for instance errors as their message and durations according to
+// zerolog.DurationFieldUnit, and are quoted when needed. Groups are flattened with dots.
+//
+// Of opts, only Level, AddSource, the source related options, and the attribute related options AllowKeys,
+// InternStrings, MaxAttrDepth, MaxAttrWidth, MaxSliceLen, MaxUniqueKeys, ReplaceAttr, ReservedKeyPolicy, StringifyValues, UnitCoercion and ValidateUTF8 are used. Unless opts.Level is set, records below
+// slog.LevelInfo are discarded.
+func NewLogfmtHandler(out io.Writer, opts *HandlerOptions) slog.Handler {
+	opt := newConfig(opts)
+	return &logfmtHandler{
+		opts:   opt,
+		pipe:   newPipeline(opt, nil, logfmtReservedKey(opt)),
+		limits: opt.attrLimits(),
+		mu:     new(sync.Mutex),
+		out:    out,
+	}
+}
+
+// Enabled implements slog.Handler.
+func (h *logfmtHandler) Enabled(_ context.Context, lvl slog.Level) bool {
+	minLvl := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLvl = h.opts.Level.Level()
+	}
+	return lvl >= minLvl
+}
+
+// Handle implements slog.Handler.
+func (h *logfmtHandler) Handle(_ context.Context, rec slog.Record) error {
+	enc := &logfmtEncoder{}
+	if !rec.Time.IsZero() {
+		enc.Time(zerolog.TimestampFieldName, rec.Time)
+	}
+	enc.Str(zerolog.LevelFieldName, ZerologLevel(rec.Level).String())
+	if h.opts.AddSource && rec.PC > 0 {
+		enc.Str(zerolog.CallerFieldName, recordSource(h.opts, rec.PC).callerString())
+	}
+	msg := rec.Message
+	if h.opts.ValidateUTF8 {
+		msg = validUTF8(msg, nil)
+	}
+	enc.Str(zerolog.MessageFieldName, msg)
+	if len(h.attrs) > 0 {
+		enc.buf = append(enc.buf, ' ')
+		enc.buf = append(enc.buf, h.attrs...)
+	}
+	rec.Attrs(func(a slog.Attr) bool {
+		if a, ok := h.pipe.attr(h.prefix, a); ok {
+			mapLogfmtAttr(enc, h.limits, h.prefix, a)
+		}
+		return true
+	})
+	enc.buf = append(enc.buf, '\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.out.Write(enc.buf)
+	return err
+}
+
+// WithAttrs implements slog.Handler.
+func (h *logfmtHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	enc := &logfmtEncoder{buf: h.attrs[:len(h.attrs):len(h.attrs)]}
+	for _, a := range h.pipe.attrs(h.prefix, attrs) {
+		mapLogfmtAttr(enc, h.limits, h.prefix, a)
+	}
+	h2 := *h
+	h2.attrs = enc.buf
+	return &h2
+}
+
+// WithGroup implements slog.Handler.
+func (h *logfmtHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
+
+// logfmtReservedKey returns whether a top-level key is the name of a field written by logfmt handlers
+// configured with opts.
+func logfmtReservedKey(opts *HandlerOptions) func(key string) bool {
+	return func(key string) bool {
+		switch key {
+		case zerolog.TimestampFieldName, zerolog.LevelFieldName, zerolog.MessageFieldName:
+			return true
+		case zerolog.CallerFieldName:
+			return opts.AddSource
+		default:
+			return false
+		}
+	}
+}
+
+// mapLogfmtAttr writes a into enc, flattening groups and prefixing keys with prefix.
+func mapLogfmtAttr(enc *logfmtEncoder, lim attrLimits, prefix string, a slog.Attr) {
+	value := a.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix = prefix + a.Key + "."
+		}
+		for _, attr := range value.Group() {
+			mapLogfmtAttr(enc, lim, prefix, attr)
+		}
+		return
+	}
+	mapAttr(enc, lim, slog.Attr{Key: prefix + a.Key, Value: value})
+}
+
+// logfmtEncoder encodes fields as logfmt. It implements zlogWriter, to share the attribute
+// mapping with zerolog handlers.
+type logfmtEncoder struct {
+	buf []byte
+}
+
+var _ zlogWriter[*logfmtEncoder] = (*logfmtEncoder)(nil)
+
+// appendKey appends the separator with the previous field, and key followed by '='.
+// Characters not allowed in logfmt keys are replaced with '_'.
+func (e *logfmtEncoder) appendKey(key string) {
+	if len(e.buf) > 0 {
+		e.buf = append(e.buf, ' ')
+	}
+	if key == "" {
+		key = "_"
+	}
+	for _, r := range key {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || !unicode.IsPrint(r) {
+			r = '_'
+		}
+		e.buf = utf8.AppendRune(e.buf, r)
+	}
+	e.buf = append(e.buf, '=')
+}
+
+// appendValue appends val, quoted and escaped if it's empty or contains spaces, quotes, equal signs,
+// or non printable characters.
+func (e *logfmtEncoder) appendValue(val string) {
+	if val == "" || strings.IndexFunc(val, needsQuote) >= 0 {
+		e.buf = strconv.AppendQuote(e.buf, val)
+		return
+	}
+	e.buf = append(e.buf, val...)
+}
+
+// needsQuote reports whether r requires a logfmt value to be quoted.
+func needsQuote(r rune) bool {
+	return r <= ' ' || r == '=' || r == '"' || r == '\\' || r == utf8.RuneError || !unicode.IsPrint(r)
+}
+
+// Str implements zlogWriter.
+func (e *logfmtEncoder) Str(key string, val string) *logfmtEncoder {
+	e.appendKey(key)
+	e.appendValue(val)
+	return e
+}
+
+// Bool implements zlogWriter.
+func (e *logfmtEncoder) Bool(key string, b bool) *logfmtEncoder {
+	e.appendKey(key)
+	e.buf = strconv.AppendBool(e.buf, b)
+	return e
+}
+
+// Dur implements zlogWriter. Durations are written like zerolog does, according to
+// zerolog.DurationFieldUnit and zerolog.DurationFieldInteger.
+func (e *logfmtEncoder) Dur(key string, d time.Duration) *logfmtEncoder {
+	if zerolog.DurationFieldInteger {
+		return e.Int64(key, int64(d/zerolog.DurationFieldUnit))
+	}
+	return e.Float64(key, float64(d)/float64(zerolog.DurationFieldUnit))
+}
+
+// Float64 implements zlogWriter.
+func (e *logfmtEncoder) Float64(key string, f float64) *logfmtEncoder {
+	e.appendKey(key)
+	e.buf = strconv.AppendFloat(e.buf, f, 'f', -1, 64)
+	return e
+}
+
+// Int64 implements zlogWriter.
+func (e *logfmtEncoder) Int64(key string, i int64) *logfmtEncoder {
+	e.appendKey(key)
+	e.buf = strconv.AppendInt(e.buf, i, 10)
+	return e
+}
+
+// Uint64 implements zlogWriter.
+func (e *logfmtEncoder) Uint64(key string, i uint64) *logfmtEncoder {
+	e.appendKey(key)
+	e.buf = strconv.AppendUint(e.buf, i, 10)
+	return e
+}
+
+// Time implements zlogWriter. Times are formatted with zerolog.TimeFieldFormat.
+func (e *logfmtEncoder) Time(key string, t time.Time) *logfmtEncoder {
+	switch zerolog.TimeFieldFormat {
+	case zerolog.TimeFormatUnix:
+		return e.Int64(key, t.Unix())
+	case zerolog.TimeFormatUnixMs:
+		return e.Int64(key, t.UnixMilli())
+	case zerolog.TimeFormatUnixMicro:
+		return e.Int64(key, t.UnixMicro())
+	case zerolog.TimeFormatUnixNano:
+		return e.Int64(key, t.UnixNano())
+	default:
+		return e.Str(key, t.Format(zerolog.TimeFieldFormat))
+	}
+}
+
+// Dict implements zlogWriter. It's never called, since groups are flattened before being mapped,
+// and the content of dict can't be read back.
+func (e *logfmtEncoder) Dict(key string, dict *zerolog.Event) *logfmtEncoder {
+	return e
+}
+
+// Interface implements zlogWriter. Values are written as their JSON encoding.
+func (e *logfmtEncoder) Interface(key string, i any) *logfmtEncoder {
+	data, err := json.Marshal(i)
+	if err != nil {
+		return e.Str(key, "!ERROR:"+err.Error())
+	}
+	return e.Str(key, string(data))
+}
+
+// AnErr implements zlogWriter.
+func (e *logfmtEncoder) AnErr(key string, err error) *logfmtEncoder {
+	if err == nil {
+		return e
+	}
+	return e.Str(key, err.Error())
+}
+
+// Stringer implements zlogWriter.
+func (e *logfmtEncoder) Stringer(key string, val fmt.Stringer) *logfmtEncoder {
+	if val == nil {
+		return e.Str(key, "null")
+	}
+	return e.Str(key, val.String())
+}
+
+// IPAddr implements zlogWriter.
+func (e *logfmtEncoder) IPAddr(key string, ip net.IP) *logfmtEncoder {
+	return e.Str(key, ip.String())
+}
+
+// IPPrefix implements zlogWriter.
+func (e *logfmtEncoder) IPPrefix(key string, pfx net.IPNet) *logfmtEncoder {
+	return e.Str(key, pfx.String())
+}
+
+// MACAddr implements zlogWriter.
+func (e *logfmtEncoder) MACAddr(key string, ha net.HardwareAddr) *logfmtEncoder {
+	return e.Str(key, ha.String())
+}
+
+// RawJSON implements zlogWriter.
+func (e *logfmtEncoder) RawJSON(key string, b []byte) *logfmtEncoder {
+	return e.Str(key, string(b))
+}
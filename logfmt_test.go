@@ -0,0 +1,75 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogfmtHandler(t *testing.T) {
+	out := bytes.Buffer{}
+	hdl := NewLogfmtHandler(&out, nil).
+		WithAttrs([]slog.Attr{slog.String("service", "api")}).
+		WithGroup("req").
+		WithAttrs([]slog.Attr{slog.Int("id", 12)})
+	rec := slog.NewRecord(time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC), slog.LevelWarn, "hello world", 0)
+	rec.AddAttrs(
+		slog.String("plain", "value"),
+		slog.String("space", "a b"),
+		slog.String("quote", `say "hi"`),
+		slog.String("newline", "a\nb"),
+		slog.String("equal", "a=b"),
+		slog.String("empty", ""),
+		slog.String("bad key", "x"),
+		slog.Duration("latency", 1500*time.Microsecond),
+		slog.Any("err", errors.New("boom")),
+		slog.Any("stringer", stringer{}),
+		slog.Group("user", slog.String("name", "bob"), slog.Group("role", slog.Bool("admin", true))),
+	)
+	if err := hdl.Handle(context.Background(), rec); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := `time=2024-01-02T15:04:05Z level=warn message="hello world" service=api req.id=12 req.plain=value req.space="a b" req.quote="say \"hi\"" req.newline="a\nb" req.equal="a=b" req.empty="" req.bad_key=x req.latency=1.5 req.err=boom req.stringer=stringer req.user.name=bob req.user.role.admin=true` + "\n"
+	if out.String() != expected {
+		t.Errorf("Unexpected output:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
+
+func TestLogfmtHandler_Level(t *testing.T) {
+	out := bytes.Buffer{}
+	logger := slog.New(NewLogfmtHandler(&out, &HandlerOptions{Level: slog.LevelWarn}))
+	logger.Info("foobar")
+	if out.Len() > 0 {
+		t.Fatalf("Unexpected output: %s", out.String())
+	}
+	logger.Error("foobar")
+	if !strings.Contains(out.String(), "level=error message=foobar") {
+		t.Fatalf("Unexpected output: %s", out.String())
+	}
+}
+
+func TestLogfmtHandler_AttrOptions(t *testing.T) {
+	out := bytes.Buffer{}
+	hdl := NewLogfmtHandler(&out, &HandlerOptions{
+		AllowKeys:         []string{"message", "req.*"},
+		ReservedKeyPolicy: ReservedKeyRename,
+		UnitCoercion:      map[string]Unit{"_ms": UnitMilliseconds},
+	})
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "hello", 0)
+	rec.AddAttrs(
+		slog.String("message", "m"),
+		slog.String("secret", "x"),
+		slog.Group("req", slog.Duration("latency_ms", 1500*time.Microsecond)),
+	)
+	if err := hdl.Handle(context.Background(), rec); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := "level=info message=hello field_message=m req.latency_ms=1.5\n"
+	if out.String() != expected {
+		t.Errorf("Unexpected output:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
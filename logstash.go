@@ -0,0 +1,90 @@
+package zeroslog
+
+import (
+	"log/slog"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// LogstashTimestampKey is the name of the time field with LogstashFormat.
+	LogstashTimestampKey = "@timestamp"
+	// LogstashVersionKey is the name of the field holding LogstashVersion with LogstashFormat.
+	LogstashVersionKey = "@version"
+	// LogstashVersion is the version of the Logstash event format written with LogstashFormat.
+	LogstashVersion = "1"
+	// LogstashEventKey is the name of the object holding the dataset and module with LogstashFormat.
+	LogstashEventKey = "event"
+)
+
+// LogstashOptions configure the Logstash event format enabled with HandlerOptions.LogstashFormat.
+type LogstashOptions struct {
+	// Dataset is written as event.dataset, unless empty.
+	Dataset string
+	// Module is written as event.module, unless empty.
+	Module string
+	// AttrsKey, if set, is the key of the top-level object holding the attributes of the records,
+	// including the ones added with WithAttrs, the groups added with WithGroup and LevelAttrs, like if the
+	// handler was derived with WithGroup(AttrsKey). The attributes added with WithAttrs are then written
+	// with the ones of the records instead of being encoded once into the context of the logger.
+	// By default, attributes are written at the top level.
+	AttrsKey string
+}
+
+// attrsKey returns the key of the object holding the attributes, or "" if they're written at the top level.
+func (o *LogstashOptions) attrsKey() string {
+	if o == nil {
+		return ""
+	}
+	return o.AttrsKey
+}
+
+// writeLogstash writes the Logstash version and event fields if LogstashFormat is set.
+func (h *Handler) writeLogstash(evt *zerolog.Event) {
+	o := h.opts.LogstashFormat
+	if o == nil {
+		return
+	}
+	evt.Str(LogstashVersionKey, LogstashVersion)
+	if o.Dataset == "" && o.Module == "" {
+		return
+	}
+	event := zerolog.Dict()
+	if o.Dataset != "" {
+		event.Str("dataset", o.Dataset)
+	}
+	if o.Module != "" {
+		event.Str("module", o.Module)
+	}
+	evt.Dict(LogstashEventKey, event)
+}
+
+// writeFields writes fields, followed by dict under the key group if it's not nil, into evt, or into an object
+// under LogstashOptions.AttrsKey if it's set and they're not empty.
+func (h *Handler) writeFields(evt *zerolog.Event, fields []slog.Attr, group string, dict *zerolog.Event) {
+	key := h.opts.LogstashFormat.attrsKey()
+	if key == "" {
+		mapAttrs(evt, h.limits, fields...)
+		if dict != nil {
+			evt.Dict(group, dict)
+		}
+		return
+	}
+	if dict == nil && isEmptyGroup(fields) {
+		return
+	}
+	attrs := mapAttrs(zerolog.Dict(), h.limits, fields...)
+	if dict != nil {
+		attrs.Dict(group, dict)
+	}
+	evt.Dict(key, attrs)
+}
+
+// attrsPrefix returns the path of the object holding the attributes, including the trailing dot,
+// or "" if they're written at the top level.
+func (h *Handler) attrsPrefix() string {
+	if key := h.opts.LogstashFormat.attrsKey(); key != "" {
+		return key + "."
+	}
+	return ""
+}
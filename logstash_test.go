@@ -0,0 +1,111 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestLogstashFormat(t *testing.T) {
+	for name, tc := range map[string]struct {
+		opts LogstashOptions
+		exp  map[string]any
+	}{
+		"top level": {
+			opts: LogstashOptions{Dataset: "app.log", Module: "app"},
+			exp: map[string]any{
+				LogstashVersionKey: LogstashVersion,
+				LogstashEventKey:   map[string]any{"dataset": "app.log", "module": "app"},
+				"a":                1.0,
+				"g":                map[string]any{"b": 2.0, "c": 3.0},
+			},
+		},
+		"attrs key": {
+			opts: LogstashOptions{Module: "app", AttrsKey: "data"},
+			exp: map[string]any{
+				LogstashVersionKey: LogstashVersion,
+				LogstashEventKey:   map[string]any{"module": "app"},
+				"data":             map[string]any{"a": 1.0, "g": map[string]any{"b": 2.0, "c": 3.0}},
+			},
+		},
+		"no event": {
+			opts: LogstashOptions{},
+			exp: map[string]any{
+				LogstashVersionKey: LogstashVersion,
+				"a":                1.0,
+				"g":                map[string]any{"b": 2.0, "c": 3.0},
+			},
+		},
+	} {
+		out := bytes.Buffer{}
+		opts := tc.opts
+		logger := slog.New(NewJsonHandler(&out, &HandlerOptions{LogstashFormat: &opts, AddSource: true}))
+		logger.With("a", 1).WithGroup("g").With("b", 2).Info("msg", "c", 3)
+
+		m := map[string]any{}
+		if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+			t.Fatalf("%s: failed to json decode log output: %s", name, err)
+		}
+		if ts, _ := m[LogstashTimestampKey].(string); ts == "" {
+			t.Errorf("%s: missing %s in %s", name, LogstashTimestampKey, out.String())
+		}
+		if _, ok := m[zerolog.TimestampFieldName]; ok {
+			t.Errorf("%s: unexpected %s in %s", name, zerolog.TimestampFieldName, out.String())
+		}
+		if caller, _ := m[zerolog.CallerFieldName].(string); caller == "" {
+			t.Errorf("%s: missing top-level source in %s", name, out.String())
+		}
+		for _, k := range []string{LogstashTimestampKey, zerolog.CallerFieldName, zerolog.LevelFieldName, zerolog.MessageFieldName} {
+			delete(m, k)
+		}
+		if !jsonEqual(m, tc.exp) {
+			t.Errorf("%s: unexpected output %s", name, out.String())
+		}
+	}
+}
+
+func TestLogstashFormat_AttrsKey(t *testing.T) {
+	out := bytes.Buffer{}
+	h := NewJsonHandler(&out, &HandlerOptions{LogstashFormat: &LogstashOptions{AttrsKey: "data"}})
+	logger := slog.New(h)
+
+	logger.Info("msg")
+	logger.WithGroup("g").Info("msg")
+	m := map[string]any{}
+	for dec := json.NewDecoder(&out); dec.More(); {
+		if err := dec.Decode(&m); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := m["data"]; ok {
+			t.Errorf("Unexpected empty attributes object in %v", m)
+		}
+	}
+
+	schema := h.WithAttrs([]slog.Attr{slog.Int("a", 1)}).WithGroup("g").WithAttrs([]slog.Attr{slog.String("b", "c")}).(*groupHandler).Schema()
+	for key, typ := range map[string]string{LogstashTimestampKey: schemaString, LogstashVersionKey: schemaString, "data.a": schemaNumber, "data.g.b": schemaString} {
+		if schema[key] != typ {
+			t.Errorf("Expected %s to be a %s in schema %v", key, typ, schema)
+		}
+	}
+}
+
+func TestLogstashFormat_Timestamp(t *testing.T) {
+	out := bytes.Buffer{}
+	now := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	h := NewJsonHandler(&out, &HandlerOptions{LogstashFormat: &LogstashOptions{}, FieldNames: FieldNames{Time: "ts"}})
+	if err := h.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "msg", 0)); err != nil {
+		t.Fatal(err)
+	}
+	m := map[string]any{}
+	if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m[LogstashTimestampKey] != now.Format(time.RFC3339) || m["ts"] != nil {
+		t.Errorf("Expected the time under %s, got %s", LogstashTimestampKey, out.String())
+	}
+}
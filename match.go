@@ -0,0 +1,27 @@
+package zeroslog
+
+import "path"
+
+// keyMatcher matches dot-joined attribute keys against a list of exact keys and path.Match patterns.
+type keyMatcher []string
+
+// newKeyMatcher creates a keyMatcher for patterns, or nil if patterns is empty.
+func newKeyMatcher(patterns []string) keyMatcher {
+	if len(patterns) == 0 {
+		return nil
+	}
+	return append(keyMatcher(nil), patterns...)
+}
+
+// match reports whether key matches any of the patterns.
+func (m keyMatcher) match(key string) bool {
+	for _, pattern := range m {
+		if pattern == key {
+			return true
+		}
+		if ok, err := path.Match(pattern, key); ok && err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestAllowKeys(t *testing.T) {
+	out := bytes.Buffer{}
+	hdl := NewJsonHandler(&out, &HandlerOptions{AllowKeys: []string{"user", "http.*", "req.id", "req.body.size"}})
+	child := hdl.WithAttrs([]slog.Attr{slog.String("user", "bob"), slog.String("secret", "s3cr3t")}).
+		WithGroup("req").
+		WithAttrs([]slog.Attr{slog.String("id", "42"), slog.String("token", "t0k3n")})
+
+	rec := slog.NewRecord(now, slog.LevelInfo, "foobar", 0)
+	rec.AddAttrs(
+		slog.String("user", "not-top-level"),
+		slog.Group("body", slog.Int("size", 12), slog.String("content", "...")),
+		slog.Group("headers", slog.String("auth", "...")),
+	)
+	child.Handle(context.Background(), rec)
+
+	rec = slog.NewRecord(now, slog.LevelInfo, "foobar", 0)
+	rec.AddAttrs(slog.Group("http", slog.String("method", "GET"), slog.Group("sub", slog.Int("a", 1))), slog.String("httpx", "no"))
+	hdl.Handle(context.Background(), rec)
+
+	records := decodeAll(t, &out)
+	expected := []map[string]any{
+		{
+			zerolog.LevelFieldName:     "info",
+			zerolog.MessageFieldName:   "foobar",
+			zerolog.TimestampFieldName: now.Format(time.RFC3339),
+			"user":                     "bob",
+			"req": map[string]any{
+				"id":   "42",
+				"body": map[string]any{"size": 12.0},
+			},
+		},
+		{
+			zerolog.LevelFieldName:     "info",
+			zerolog.MessageFieldName:   "foobar",
+			zerolog.TimestampFieldName: now.Format(time.RFC3339),
+			"http":                     map[string]any{"method": "GET", "sub": map[string]any{"a": 1.0}},
+		},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Fatalf("Unexpected records. Got %v, expected %v", records, expected)
+	}
+	if st := hdl.Stats(); st.DroppedAttrs != 6 {
+		t.Fatalf("Unexpected stats %+v", st)
+	}
+}
+
+func TestAllowKeys_Empty(t *testing.T) {
+	out := bytes.Buffer{}
+	hdl := NewJsonHandler(&out, nil)
+	rec := slog.NewRecord(now, slog.LevelInfo, "foobar", 0)
+	rec.AddAttrs(slog.String("foo", "bar"))
+	hdl.Handle(context.Background(), rec)
+	m := map[string]any{}
+	if err := json.Unmarshal(out.Bytes(), &m); err != nil || m["foo"] != "bar" {
+		t.Fatalf("Unexpected output %q", out.String())
+	}
+}
@@ -0,0 +1,79 @@
+package zeroslog
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// expandMessage returns msg with its {key} placeholders replaced with the string form of the value of
+// the attribute of rec having this key, or this dot-joined group path. Placeholders without matching
+// attribute are left verbatim, and "{{" and "}}" are written as literal braces.
+func expandMessage(msg string, rec *slog.Record) string {
+	if !strings.ContainsAny(msg, "{}") {
+		return msg
+	}
+	var b strings.Builder
+	b.Grow(len(msg))
+	for i := 0; i < len(msg); {
+		switch c := msg[i]; {
+		case (c == '{' || c == '}') && i+1 < len(msg) && msg[i+1] == c:
+			b.WriteByte(c)
+			i += 2
+		case c == '{':
+			end := strings.IndexByte(msg[i+1:], '}')
+			if end < 0 {
+				b.WriteString(msg[i:])
+				return b.String()
+			}
+			placeholder := msg[i : i+end+2]
+			if v, ok := recordValue(rec, placeholder[1:len(placeholder)-1]); ok {
+				b.WriteString(v.String())
+			} else {
+				b.WriteString(placeholder)
+			}
+			i += len(placeholder)
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// recordValue returns the resolved value of the last attribute of rec whose dot-joined group path is key.
+func recordValue(rec *slog.Record, key string) (v slog.Value, found bool) {
+	if key == "" {
+		return v, false
+	}
+	rec.Attrs(func(a slog.Attr) bool {
+		if val, ok := attrValue(a, key); ok {
+			v, found = val, true
+		}
+		return true
+	})
+	return v, found
+}
+
+// attrValue returns the resolved value of a, or of its member whose dot-joined group path relative to a is
+// the rest of key, if a's key is a prefix of key.
+func attrValue(a slog.Attr, key string) (slog.Value, bool) {
+	if a.Key == key {
+		return a.Value.Resolve(), true
+	}
+	rest, ok := strings.CutPrefix(key, a.Key+".")
+	if !ok {
+		return slog.Value{}, false
+	}
+	value := a.Value.Resolve()
+	if value.Kind() != slog.KindGroup {
+		return slog.Value{}, false
+	}
+	var v slog.Value
+	var found bool
+	for _, m := range value.Group() {
+		if val, ok := attrValue(m, rest); ok {
+			v, found = val, true
+		}
+	}
+	return v, found
+}
@@ -0,0 +1,61 @@
+package zeroslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestExpandMessage(t *testing.T) {
+	for _, tc := range []struct {
+		msg  string
+		args []any
+		exp  string
+	}{
+		{"user {user} failed login", []any{"user", "bob"}, "user bob failed login"},
+		{"{n} of {total} done in {took}", []any{"n", 3, "total", 10, "took", 1500 * time.Millisecond}, "3 of 10 done in 1.5s"},
+		{"user {user.id} from {user.ip}", []any{slog.Group("user", "id", 42, "ip", "10.0.0.1")}, "user 42 from 10.0.0.1"},
+		{"missing {nope} and {user.nope} and {}", []any{"user", slog.GroupValue(slog.Int("id", 1))}, "missing {nope} and {user.nope} and {}"},
+		{"last {a} wins", []any{"a", 1, "a", 2}, "last 2 wins"},
+		{"literal {{a}} and }} and {{", []any{"a", 1}, "literal {a} and } and {"},
+		{"nested {{{a}}}", []any{"a", 1}, "nested {1}"},
+		{"unclosed {a", []any{"a", 1}, "unclosed {a"},
+		{"stray } brace", nil, "stray } brace"},
+		{"no placeholder", []any{"a", 1}, "no placeholder"},
+	} {
+		t.Run(tc.msg, func(t *testing.T) {
+			for name, group := range map[string]bool{"root": false, "group": true} {
+				out := bytes.Buffer{}
+				logger := slog.New(NewJsonHandler(&out, &HandlerOptions{ExpandMessage: true}))
+				if group {
+					logger = logger.WithGroup("g")
+				}
+				logger.Info(tc.msg, tc.args...)
+				var m map[string]any
+				if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+					t.Fatal(err)
+				}
+				if m["message"] != tc.exp {
+					t.Errorf("%s: got message %q, expected %q", name, m["message"], tc.exp)
+				}
+				if _, ok := m["a"]; len(tc.args) > 0 && tc.args[0] == "a" && !group && !ok {
+					t.Errorf("%s: attributes must still be written, got %s", name, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestExpandMessage_Disabled(t *testing.T) {
+	out := bytes.Buffer{}
+	slog.New(NewJsonHandler(&out, nil)).Info("user {user}", "user", "bob")
+	var m map[string]any
+	if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["message"] != "user {user}" {
+		t.Errorf("Unexpected message %q", m["message"])
+	}
+}
@@ -0,0 +1,103 @@
+package zeroslog
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// mirrorKey is the context key of the mirror set by ContextWithMirror.
+type mirrorKey struct{}
+
+// mirror is a writer receiving a copy of the records handled with a context.
+type mirror struct {
+	// mu serializes writes from records handled concurrently with the same context.
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// ContextWithMirror returns a copy of ctx carrying w. Handlers created with AllowContextMirror write
+// a copy of the records handled with the returned context, or a context derived from it, to w,
+// in addition to their normal output. It's meant to capture the records of a single request,
+// for instance to return them for debugging purposes.
+//
+// Writes to w are serialized. Their errors, and panics, are ignored.
+func ContextWithMirror(ctx context.Context, w io.Writer) context.Context {
+	return context.WithValue(ctx, mirrorKey{}, &mirror{w: w})
+}
+
+// mirrorFromContext returns the mirror carried by ctx, or nil.
+func mirrorFromContext(ctx context.Context) *mirror {
+	if ctx == nil {
+		return nil
+	}
+	m, _ := ctx.Value(mirrorKey{}).(*mirror)
+	return m
+}
+
+// write writes p to the mirror, ignoring errors and panics so that they never reach the main output.
+func (m *mirror) write(p []byte) {
+	defer func() { _ = recover() }()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, _ = m.w.Write(p)
+}
+
+// teeWriter writes records to the output of a handler, then to a mirror.
+type teeWriter struct {
+	out    io.Writer
+	mirror *mirror
+}
+
+var _ zerolog.LevelWriter = teeWriter{}
+
+// Write implements io.Writer.
+func (w teeWriter) Write(p []byte) (int, error) {
+	n, err := w.out.Write(p)
+	w.mirror.write(p)
+	return n, err
+}
+
+// WriteLevel implements zerolog.LevelWriter, so that a level writer output still receives the level.
+func (w teeWriter) WriteLevel(lvl zerolog.Level, p []byte) (int, error) {
+	lw, ok := w.out.(zerolog.LevelWriter)
+	if !ok {
+		return w.Write(p)
+	}
+	n, err := lw.WriteLevel(lvl, p)
+	w.mirror.write(p)
+	return n, err
+}
+
+// recordOutput returns the writer of the record handled with ctx, writing it to the destinations it's routed to
+// with Route, if any, indenting it if ctx flags it as pretty, writing a copy of it to the mirror carried by ctx,
+// if any and if AllowContextMirror is set, and reporting write failures to the recordReporter carried by ctx,
+// if any. It returns false if the record is written to the output of the logger as is.
+func (h *Handler) recordOutput(ctx context.Context) (io.Writer, bool) {
+	if h.out == nil {
+		return nil, false
+	}
+	out, wrapped := h.out, false
+	// Options are checked first, as looking values up walks the whole context.
+	if h.routes != nil && markersUsed.Load() {
+		if names := routesFromContext(ctx); names != nil {
+			out, wrapped = h.routes.routedWriter(h, out, names), true
+		}
+	}
+	if markersUsed.Load() && prettyFromContext(ctx) {
+		out, wrapped = prettyWriter{out: out}, true
+	}
+	if h.opts.AllowContextMirror {
+		if m := mirrorFromContext(ctx); m != nil {
+			out, wrapped = teeWriter{out: out, mirror: m}, true
+		}
+	}
+	if h.opts.OnRecordError != nil {
+		if r := reporterFromContext(ctx); r != nil {
+			out, wrapped = reportWriter{out: out, reporter: r}, true
+		}
+	}
+	return out, wrapped
+}
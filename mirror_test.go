@@ -0,0 +1,80 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type failingWriter struct{ panics bool }
+
+func (w failingWriter) Write(p []byte) (int, error) {
+	if w.panics {
+		panic("mirror failure")
+	}
+	return 0, errors.New("mirror failure")
+}
+
+func TestContextMirror_Isolation(t *testing.T) {
+	out := syncBuffer{}
+	logger := slog.New(NewJsonHandler(&out, &HandlerOptions{AllowContextMirror: true})).With("service", "api").WithGroup("req")
+	mirrors := [2]bytes.Buffer{}
+	wg := sync.WaitGroup{}
+	for i := range mirrors {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ctx := ContextWithMirror(context.Background(), &mirrors[i])
+			for j := 0; j < 50; j++ {
+				logger.InfoContext(ctx, "request", "id", i, "seq", j)
+			}
+		}(i)
+	}
+	wg.Wait()
+	logger.Info("no mirror")
+
+	if n := strings.Count(out.buf.String(), "\n"); n != 101 {
+		t.Fatalf("Unexpected %d records in the main output", n)
+	}
+	for i := range mirrors {
+		lines := strings.Split(strings.TrimSuffix(mirrors[i].String(), "\n"), "\n")
+		if len(lines) != 50 {
+			t.Fatalf("Unexpected %d records in mirror %d", len(lines), i)
+		}
+		exp := fmt.Sprintf(`"service":"api","req":{"id":%d,`, i)
+		for _, line := range lines {
+			if !strings.Contains(line, exp) || !strings.Contains(out.buf.String(), line) {
+				t.Fatalf("Unexpected record in mirror %d: %s", i, line)
+			}
+		}
+	}
+}
+
+func TestContextMirror_Failures(t *testing.T) {
+	for _, panics := range []bool{false, true} {
+		out := bytes.Buffer{}
+		var reported error
+		hdl := NewJsonHandler(&out, &HandlerOptions{AllowContextMirror: true, OnError: func(err error) { reported = err }})
+		ctx := ContextWithMirror(context.Background(), failingWriter{panics: panics})
+		if err := hdl.Handle(ctx, slog.NewRecord(now, slog.LevelInfo, "hello", 0)); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if !strings.Contains(out.String(), `"message":"hello"`) || reported != nil {
+			t.Fatalf("Mirror failure affected the main output: %q, %v", out.String(), reported)
+		}
+	}
+}
+
+func TestContextMirror_NotAllowed(t *testing.T) {
+	out, mirror := bytes.Buffer{}, bytes.Buffer{}
+	logger := slog.New(NewJsonHandler(&out, nil))
+	logger.InfoContext(ContextWithMirror(context.Background(), &mirror), "hello")
+	if out.Len() == 0 || mirror.Len() != 0 {
+		t.Fatalf("Unexpected output %q and mirror %q", out.String(), mirror.String())
+	}
+}
@@ -0,0 +1,104 @@
+package zeroslog
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// messagePattern matches record messages, either against a regular expression, or a substring if re is nil.
+// An invalid regular expression matches nothing.
+type messagePattern struct {
+	substr  string
+	re      *regexp.Regexp
+	invalid bool
+}
+
+// messageFilter implements DropMessages and OnlyMessages. Patterns are compiled when the first record is
+// filtered, or by Handler.Warmup.
+type messageFilter struct {
+	opts *HandlerOptions
+	// onError reports invalid regular expressions when they're compiled for the first record.
+	onError func(err error)
+
+	once sync.Once
+	drop []messagePattern
+	only []messagePattern
+	err  error
+}
+
+// newMessageFilter returns the filter applying the DropMessages and OnlyMessages patterns of opts,
+// or nil if there's none.
+func newMessageFilter(opts *HandlerOptions, onError func(err error)) *messageFilter {
+	if len(opts.DropMessages) == 0 && len(opts.OnlyMessages) == 0 {
+		return nil
+	}
+	return &messageFilter{opts: opts, onError: onError}
+}
+
+// compile compiles the patterns once, and returns the errors of the invalid regular expressions.
+// If lazy is true, as when the first record is filtered, they're also reported to onError.
+// It is safe to call on a nil messageFilter.
+func (f *messageFilter) compile(lazy bool) error {
+	if f == nil {
+		return nil
+	}
+	f.once.Do(func() {
+		var dropErr, onlyErr error
+		f.drop, dropErr = compileMessagePatterns("DropMessages", f.opts.DropMessages)
+		f.only, onlyErr = compileMessagePatterns("OnlyMessages", f.opts.OnlyMessages)
+		f.err = errors.Join(dropErr, onlyErr)
+		if lazy && f.err != nil {
+			f.onError(f.err)
+		}
+	})
+	return f.err
+}
+
+// compileMessagePatterns compiles the patterns of the named option. Patterns enclosed in slashes are
+// regular expressions, and the other ones substrings.
+func compileMessagePatterns(option string, patterns []string) ([]messagePattern, error) {
+	compiled := make([]messagePattern, len(patterns))
+	var errs []error
+	for i, p := range patterns {
+		if len(p) < 2 || p[0] != '/' || p[len(p)-1] != '/' {
+			compiled[i].substr = p
+			continue
+		}
+		re, err := regexp.Compile(p[1 : len(p)-1])
+		if err != nil {
+			compiled[i].invalid = true
+			errs = append(errs, fmt.Errorf("zeroslog: invalid %s pattern %q: %w", option, p, err))
+			continue
+		}
+		compiled[i].re = re
+	}
+	return compiled, errors.Join(errs...)
+}
+
+// matchMessage reports whether msg matches any of patterns.
+func matchMessage(patterns []messagePattern, msg string) bool {
+	for _, p := range patterns {
+		if p.re != nil && p.re.MatchString(msg) || p.re == nil && !p.invalid && strings.Contains(msg, p.substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// dropped reports whether rec must be dropped, and counts it.
+// It is safe to call on a nil messageFilter, which never drops anything.
+func (f *messageFilter) dropped(s *stats, rec *slog.Record) bool {
+	if f == nil {
+		return false
+	}
+	f.compile(true)
+	if matchMessage(f.drop, rec.Message) || len(f.only) > 0 && !matchMessage(f.only, rec.Message) {
+		s.dropped.Add(1)
+		return true
+	}
+	return false
+}
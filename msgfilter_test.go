@@ -0,0 +1,85 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestMessageFilter(t *testing.T) {
+	messages := []string{"GET /healthz", "GET /users", "Health check", "user created"}
+	tests := []struct {
+		name       string
+		drop, only []string
+		exp        []string
+	}{
+		{"Drop_Substring", []string{"health"}, nil, []string{"GET /users", "Health check", "user created"}},
+		{"Drop_Regexp", []string{"/^GET /"}, nil, []string{"Health check", "user created"}},
+		{"Drop_RegexpCaseInsensitive", []string{"/(?i)health/"}, nil, []string{"GET /users", "user created"}},
+		{"Only_Substring", nil, []string{"user"}, []string{"GET /users", "user created"}},
+		{"Only_Regexp", nil, []string{"/^[A-Z]/"}, []string{"GET /healthz", "GET /users", "Health check"}},
+		{"DropAndOnly", []string{"/healthz$/"}, []string{"GET"}, []string{"GET /users"}},
+		{"Slash", []string{"/"}, nil, []string{"Health check", "user created"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, group := range []bool{false, true} {
+				out := bytes.Buffer{}
+				hdl := NewJsonHandler(&out, &HandlerOptions{DropMessages: test.drop, OnlyMessages: test.only})
+				logger := slog.New(hdl)
+				if group {
+					logger = logger.With("a", 1).WithGroup("g")
+				}
+				for _, msg := range messages {
+					logger.Info(msg, "b", 2)
+				}
+				var got []string
+				for _, msg := range messages {
+					if strings.Contains(out.String(), `"message":"`+msg+`"`) {
+						got = append(got, msg)
+					}
+				}
+				if strings.Join(got, "|") != strings.Join(test.exp, "|") {
+					t.Errorf("Expected %q to be written, got %q (group %v)", test.exp, got, group)
+				}
+				if st := hdl.Stats(); st.Dropped != uint64(len(messages)-len(test.exp)) || st.Emitted != uint64(len(test.exp)) {
+					t.Errorf("Expected %d dropped records, got %+v", len(messages)-len(test.exp), st)
+				}
+			}
+		})
+	}
+}
+
+func TestMessageFilter_InvalidRegexp(t *testing.T) {
+	hdl := NewJsonHandler(nil, &HandlerOptions{DropMessages: []string{"health"}, OnlyMessages: []string{"/(/"}})
+	if err := hdl.Warmup(context.Background()); err == nil || !strings.Contains(err.Error(), "OnlyMessages") {
+		t.Errorf("Expected an error for the invalid pattern, got %v", err)
+	}
+	if err := hdl.Warmup(context.Background()); err == nil {
+		t.Errorf("Expected the error to be returned again")
+	}
+}
+
+func TestMessageFilter_Lazy(t *testing.T) {
+	var errs []error
+	out := bytes.Buffer{}
+	hdl := NewJsonHandler(&out, &HandlerOptions{
+		DropMessages: []string{"/(/", "/^health$/"},
+		OnError:      func(err error) { errs = append(errs, err) },
+	})
+	logger := slog.New(hdl)
+	logger.Info("health")
+	logger.Info("(")
+	logger.Info("hello")
+	if got := strings.Count(out.String(), "\n"); got != 2 {
+		t.Errorf("Expected the invalid pattern to match nothing, got %q", out.String())
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "DropMessages") {
+		t.Errorf("Expected the invalid pattern to be reported once, got %v", errs)
+	}
+	if err := hdl.Warmup(context.Background()); err == nil {
+		t.Errorf("Expected Warmup to return the error after the first record")
+	}
+}
@@ -0,0 +1,91 @@
+package zeroslog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+)
+
+// multiHandler is an slog.Handler sending records to several handlers,
+// each of them filtering records independently.
+type multiHandler struct {
+	handlers []slog.Handler
+}
+
+var _ slog.Handler = (*multiHandler)(nil)
+
+// NewDualHandler creates a handler writing records as JSON to jsonOut, and as pretty console lines
+// to consoleOut, with independent minimum levels. A nil level defaults to opts.Level if set,
+// and to slog.LevelInfo otherwise. All the other options apply to both destinations.
+func NewDualHandler(jsonOut io.Writer, jsonLevel slog.Leveler, consoleOut io.Writer, consoleLevel slog.Leveler, opts *HandlerOptions) slog.Handler {
+	return &multiHandler{handlers: []slog.Handler{
+		NewJsonHandler(jsonOut, optionsWithLevel(opts, jsonLevel)),
+		NewConsoleHandler(consoleOut, optionsWithLevel(opts, consoleLevel)),
+	}}
+}
+
+// optionsWithLevel returns a copy of opts with Level set to lvl if not nil.
+func optionsWithLevel(opts *HandlerOptions, lvl slog.Leveler) *HandlerOptions {
+	var opt HandlerOptions
+	if opts != nil {
+		opt = *opts
+	}
+	if lvl != nil {
+		opt.Level = lvl
+	}
+	return &opt
+}
+
+// Enabled implements slog.Handler. It reports whether any of the handlers is enabled for lvl.
+func (h *multiHandler) Enabled(ctx context.Context, lvl slog.Level) bool {
+	for _, hdl := range h.handlers {
+		if hdl.Enabled(ctx, lvl) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle implements slog.Handler. It sends rec to every handler enabled for its level,
+// and returns their joined errors. Attribute values are resolved once when rec is sent
+// to several handlers.
+func (h *multiHandler) Handle(ctx context.Context, rec slog.Record) error {
+	// The enabled handlers are kept on the stack in the common case of a few handlers, like NewDualHandler's.
+	var buf [4]slog.Handler
+	enabled := buf[:0]
+	for _, hdl := range h.handlers {
+		if hdl.Enabled(ctx, rec.Level) {
+			enabled = append(enabled, hdl)
+		}
+	}
+	if len(enabled) > 1 {
+		rec = resolveRecord(rec)
+	}
+	var errs []error
+	for _, hdl := range enabled {
+		if err := hdl.Handle(ctx, rec); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WithAttrs implements slog.Handler. Attribute values are resolved once for all the handlers.
+func (h *multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	attrs = resolveAttrs(attrs)
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, hdl := range h.handlers {
+		handlers[i] = hdl.WithAttrs(attrs)
+	}
+	return &multiHandler{handlers: handlers}
+}
+
+// WithGroup implements slog.Handler.
+func (h *multiHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, hdl := range h.handlers {
+		handlers[i] = hdl.WithGroup(name)
+	}
+	return &multiHandler{handlers: handlers}
+}
@@ -0,0 +1,67 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestDualHandler(t *testing.T) {
+	jsonOut, consoleOut := bytes.Buffer{}, bytes.Buffer{}
+	hdl := NewDualHandler(&jsonOut, slog.LevelDebug, &consoleOut, slog.LevelWarn, nil).
+		WithAttrs([]slog.Attr{slog.String("svc", "api")}).
+		WithGroup("req")
+
+	if !hdl.Enabled(context.Background(), slog.LevelDebug) || hdl.Enabled(context.Background(), slog.LevelDebug-1) {
+		t.Fatalf("Unexpected enabled levels")
+	}
+	for _, lvl := range []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError} {
+		rec := slog.NewRecord(now, lvl, "msg-"+lvl.String(), 0)
+		rec.AddAttrs(slog.String("id", "42"))
+		if err := hdl.Handle(context.Background(), rec); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+
+	records := decodeAll(t, &jsonOut)
+	if len(records) != 4 {
+		t.Fatalf("Expected 4 JSON records, got %v", records)
+	}
+	for _, m := range records {
+		if m["svc"] != "api" || m["req"].(map[string]any)["id"] != "42" {
+			t.Fatalf("Unexpected JSON record %v", m)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(consoleOut.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 console lines, got %q", consoleOut.String())
+	}
+	for i, lvl := range []string{"WARN", "ERROR"} {
+		if !strings.Contains(lines[i], "msg-"+lvl) || !strings.Contains(lines[i], "api") || !strings.Contains(lines[i], `{"id":"42"}`) {
+			t.Errorf("Unexpected console line %q", lines[i])
+		}
+	}
+}
+
+func TestDualHandler_DefaultLevel(t *testing.T) {
+	jsonOut, consoleOut := bytes.Buffer{}, bytes.Buffer{}
+	hdl := NewDualHandler(&jsonOut, nil, &consoleOut, slog.LevelError, nil)
+	hdl.Handle(context.Background(), slog.NewRecord(now, slog.LevelDebug, "filtered", 0))
+	hdl.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "kept", 0))
+	if records := decodeAll(t, &jsonOut); len(records) != 1 || consoleOut.Len() > 0 {
+		t.Fatalf("Unexpected output %v %q", records, consoleOut.String())
+	}
+}
+
+func TestDualHandler_Allocs(t *testing.T) {
+	hdl := NewDualHandler(io.Discard, slog.LevelDebug, io.Discard, slog.LevelWarn, nil)
+	rec := slog.NewRecord(now, slog.LevelInfo, "hello", 0)
+	rec.AddAttrs(slog.String("id", "42"))
+	if n := testing.AllocsPerRun(100, func() { hdl.Handle(context.Background(), rec) }); n != 0 {
+		t.Errorf("Handle allocates %v times per record", n)
+	}
+}
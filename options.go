@@ -0,0 +1,35 @@
+package zeroslog
+
+import (
+	"maps"
+	"slices"
+)
+
+// newConfig returns the configuration of a handler created with opts, or default options if opts is nil.
+//
+// The configuration is a deep copy of opts: slices and maps are copied, so that the caller modifying
+// opts afterward doesn't affect the handler. It's never modified after construction, and is shared
+// by pointer between the handler and all the handlers derived from it with WithAttrs and WithGroup.
+func newConfig(opts *HandlerOptions) *HandlerOptions {
+	if opts == nil {
+		return new(HandlerOptions)
+	}
+	cfg := *opts
+	cfg.AllowKeys = slices.Clone(opts.AllowKeys)
+	cfg.AttrLevelOverrides.Levels = maps.Clone(opts.AttrLevelOverrides.Levels)
+	cfg.AuditKeys = slices.Clone(opts.AuditKeys)
+	cfg.DefaultAttrs = slices.Clone(opts.DefaultAttrs)
+	cfg.DropMessages = slices.Clone(opts.DropMessages)
+	cfg.Hooks = slices.Clone(opts.Hooks)
+	cfg.LevelAttrs = maps.Clone(opts.LevelAttrs)
+	if opts.LogstashFormat != nil {
+		logstash := *opts.LogstashFormat
+		cfg.LogstashFormat = &logstash
+		cfg.FieldNames.Time = LogstashTimestampKey
+	}
+	cfg.OnlyMessages = slices.Clone(opts.OnlyMessages)
+	cfg.SourceSkipPackages = slices.Clone(opts.SourceSkipPackages)
+	cfg.StringifyValues = slices.Clone(opts.StringifyValues)
+	cfg.UnitCoercion = maps.Clone(opts.UnitCoercion)
+	return &cfg
+}
@@ -0,0 +1,57 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestNewConfig_Isolation(t *testing.T) {
+	hook := &countingHook{}
+	opts := &HandlerOptions{
+		Level:        slog.LevelInfo,
+		AllowKeys:    []string{"kept", "sub.latency_*"},
+		AuditKeys:    []string{"actor"},
+		AuditLevel:   LevelFatal,
+		Hooks:        []zerolog.Hook{hook},
+		UnitCoercion: map[string]Unit{"_ms": UnitMilliseconds},
+	}
+	out := bytes.Buffer{}
+	hdl := NewJsonHandler(&out, opts)
+	derived := hdl.WithAttrs([]slog.Attr{slog.String("kept", "ctx")}).WithGroup("sub")
+
+	// Mutate everything the caller still holds.
+	opts.Level = slog.LevelError
+	opts.AllowKeys[0] = "other"
+	opts.AllowKeys[1] = "sub.*"
+	opts.AuditKeys[0] = "nobody"
+	opts.Hooks[0] = nil
+	opts.UnitCoercion["_ms"] = UnitSeconds
+	opts.UnitCoercion["dropped"] = UnitBytes
+
+	rec := slog.NewRecord(now, LevelFatal, "foobar", 0)
+	rec.AddAttrs(slog.Duration("latency_ms", 2*time.Millisecond), slog.String("dropped", "x"))
+	if err := derived.Handle(context.Background(), rec); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !hdl.Enabled(context.Background(), slog.LevelInfo) {
+		t.Error("Level must not be affected by changes to the options")
+	}
+	s := out.String()
+	for _, exp := range []string{`"kept":"ctx"`, `"sub":{"latency_ms":2}`, `"audit_incomplete":["actor"]`} {
+		if !strings.Contains(s, exp) {
+			t.Errorf("Missing %s in output: %s", exp, s)
+		}
+	}
+	if strings.Contains(s, "dropped") {
+		t.Errorf("Unexpected dropped attribute in output: %s", s)
+	}
+	if hook.count != 1 {
+		t.Errorf("Hook called %d times, expected 1", hook.count)
+	}
+}
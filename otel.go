@@ -0,0 +1,30 @@
+package zeroslog
+
+import (
+	"log/slog"
+	"strconv"
+)
+
+const (
+	// OTELSeverityNumberKey is the key of the field holding the OpenTelemetry severity number written with OTELSeverity.
+	OTELSeverityNumberKey = "severity_number"
+	// OTELSeverityTextKey is the key of the field holding the OpenTelemetry severity text written with OTELSeverity.
+	OTELSeverityTextKey = "severity_text"
+)
+
+// otelSeverityNames are the names of the ranges of 4 severity numbers defined by OpenTelemetry.
+var otelSeverityNames = [...]string{"TRACE", "DEBUG", "INFO", "WARN", "ERROR", "FATAL"}
+
+// OTELSeverity maps lvl to the OpenTelemetry severity number, from 1 to 24, and its short name, like
+// "INFO" or "ERROR2". slog.LevelDebug, slog.LevelInfo, slog.LevelWarn and slog.LevelError are the first numbers
+// of the DEBUG, INFO, WARN and ERROR ranges, like LevelTrace and LevelFatal for the TRACE and FATAL ranges,
+// and intermediate levels map to the following numbers of the ranges. Levels below LevelTrace map to 1,
+// and levels above LevelFatal+3, like LevelPanic, to 24.
+func OTELSeverity(lvl slog.Level) (number int, text string) {
+	number = min(max(int(lvl-LevelTrace)+1, 1), 24)
+	text = otelSeverityNames[(number-1)/4]
+	if offset := (number - 1) % 4; offset > 0 {
+		text += strconv.Itoa(offset + 1)
+	}
+	return number, text
+}
@@ -0,0 +1,66 @@
+package zeroslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestOTELSeverity(t *testing.T) {
+	tests := []struct {
+		lvl    slog.Level
+		number int
+		text   string
+	}{
+		{LevelTrace - 10, 1, "TRACE"},
+		{LevelTrace, 1, "TRACE"},
+		{LevelTrace + 1, 2, "TRACE2"},
+		{LevelTrace + 3, 4, "TRACE4"},
+		{slog.LevelDebug, 5, "DEBUG"},
+		{slog.LevelDebug + 2, 7, "DEBUG3"},
+		{slog.LevelInfo, 9, "INFO"},
+		{slog.LevelInfo + 1, 10, "INFO2"},
+		{slog.LevelWarn, 13, "WARN"},
+		{slog.LevelWarn + 3, 16, "WARN4"},
+		{slog.LevelError, 17, "ERROR"},
+		{slog.LevelError + 2, 19, "ERROR3"},
+		{LevelFatal, 21, "FATAL"},
+		{LevelFatal + 3, 24, "FATAL4"},
+		{LevelPanic, 24, "FATAL4"},
+	}
+	ranges := []struct {
+		name     string
+		min, max int
+	}{{"TRACE", 1, 4}, {"DEBUG", 5, 8}, {"INFO", 9, 12}, {"WARN", 13, 16}, {"ERROR", 17, 20}, {"FATAL", 21, 24}}
+	for _, test := range tests {
+		number, text := OTELSeverity(test.lvl)
+		if number != test.number || text != test.text {
+			t.Errorf("Level %v: expected %d %q, got %d %q", test.lvl, test.number, test.text, number, text)
+		}
+		for _, r := range ranges {
+			if strings.HasPrefix(text, r.name) && (number < r.min || number > r.max) {
+				t.Errorf("Level %v: number %d is outside of the %s range", test.lvl, number, r.name)
+			}
+		}
+	}
+}
+
+func TestOTELSeverity_Handler(t *testing.T) {
+	for _, omitLevel := range []bool{false, true} {
+		out := bytes.Buffer{}
+		logger := slog.New(NewJsonHandler(&out, &HandlerOptions{OTELSeverity: true, OmitLevel: omitLevel}))
+		logger.WithGroup("g").Warn("msg", "a", 1)
+		var m map[string]any
+		if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+			t.Fatal(err)
+		}
+		if m[OTELSeverityNumberKey] != 13.0 || m[OTELSeverityTextKey] != "WARN" {
+			t.Errorf("Expected the WARN severity, got %s", out.String())
+		}
+		if _, hasLevel := m["level"]; hasLevel == omitLevel {
+			t.Errorf("Unexpected level field with OmitLevel %v: %s", omitLevel, out.String())
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package zeroslog
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// AttrLevelOverrides selects the minimum level of records according to the value of one of their
+// attributes, like a tenant identifier. See HandlerOptions.AttrLevelOverrides.
+type AttrLevelOverrides struct {
+	// Key is the dot-joined key of the attribute, including the groups of the handler, like "tenant"
+	// or "req.tenant" for an attribute added to a handler derived with WithGroup("req").
+	Key string
+	// Levels maps the string form of attribute values to the minimum level of the records having them.
+	Levels map[string]slog.Leveler
+}
+
+// enabled reports whether overrides are configured.
+func (o *AttrLevelOverrides) enabled() bool {
+	return o.Key != "" && len(o.Levels) > 0
+}
+
+// floor returns the lowest level of the overrides, and false if overrides aren't configured.
+func (o *AttrLevelOverrides) floor() (slog.Level, bool) {
+	if !o.enabled() {
+		return 0, false
+	}
+	first := true
+	var lowest slog.Level
+	for _, l := range o.Levels {
+		if lvl := l.Level(); first || lvl < lowest {
+			lowest, first = lvl, false
+		}
+	}
+	return lowest, true
+}
+
+// lookup returns the override selected by the last of attrs, whose group path is prefix, having the key,
+// or nil if its value has no override, and false if none of attrs has the key.
+func (o *AttrLevelOverrides) lookup(prefix string, attrs []slog.Attr) (slog.Leveler, bool) {
+	var level slog.Leveler
+	found := false
+	for _, a := range attrs {
+		if l, ok := o.match(prefix, a); ok {
+			level, found = l, true
+		}
+	}
+	return level, found
+}
+
+// match returns the override selected by a, whose group path is prefix, and false if a doesn't have the key.
+func (o *AttrLevelOverrides) match(prefix string, a slog.Attr) (slog.Leveler, bool) {
+	if len(prefix)+len(a.Key) != len(o.Key) || o.Key[:len(prefix)] != prefix || o.Key[len(prefix):] != a.Key {
+		return nil, false
+	}
+	return o.Levels[a.Value.Resolve().String()], true
+}
+
+// overrideEmits reports whether an override lets records at lvl be written, ignoring the other levels.
+func (h *Handler) overrideEmits(lvl slog.Level) bool {
+	floor, ok := h.opts.AttrLevelOverrides.floor()
+	return ok && ZerologLevel(lvl) >= ZerologLevel(floor)
+}
+
+// overrideDrop reports whether rec, whose attributes have the group path prefix, is below the level selected
+// by AttrLevelOverrides, with an error wrapping ErrNotEmitted if StrictEmission is set. ctxLevel is the
+// override selected by the attributes added with WithAttrs, if any. Dropped records are counted, as Enabled
+// reports their level as enabled.
+func (h *Handler) overrideDrop(ctxLevel slog.Leveler, prefix string, rec *slog.Record) (bool, error) {
+	o := &h.opts.AttrLevelOverrides
+	level := ctxLevel
+	rec.Attrs(func(a slog.Attr) bool {
+		if l, ok := o.match(prefix, a); ok {
+			level = l
+		}
+		return true
+	})
+	if level != nil && rec.Level >= level.Level() || level == nil && h.level.enabled(rec.Level) {
+		return false, nil
+	}
+	h.stats.dropped.Add(1)
+	if !h.opts.StrictEmission {
+		return true, nil
+	}
+	return true, fmt.Errorf("%w: level %s is below the level selected by %s", ErrNotEmitted, LevelString(rec.Level), o.Key)
+}
@@ -0,0 +1,90 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestAttrLevelOverrides(t *testing.T) {
+	out := bytes.Buffer{}
+	hdl := NewJsonHandler(&out, &HandlerOptions{
+		AttrLevelOverrides: AttrLevelOverrides{Key: "tenant", Levels: map[string]slog.Leveler{
+			"A": slog.LevelDebug,
+			"C": slog.LevelError,
+		}},
+	})
+	logger := slog.New(hdl)
+	tests := []struct {
+		name    string
+		log     func()
+		written bool
+	}{
+		{"A_Debug", func() { logger.Debug("msg", "tenant", "A") }, true},
+		{"A_Debug_WithAttrs", func() { logger.With("tenant", "A").Debug("msg") }, true},
+		{"A_Debug_WithGroup", func() { logger.With("tenant", "A").WithGroup("g").Debug("msg", "b", 1) }, true},
+		{"A_Trace", func() { logger.Log(context.Background(), LevelTrace, "msg", "tenant", "A") }, false},
+		{"B_Debug", func() { logger.Debug("msg", "tenant", "B") }, false},
+		{"B_Info", func() { logger.Info("msg", "tenant", "B") }, true},
+		{"C_Warn", func() { logger.Warn("msg", "tenant", "C") }, false},
+		{"C_Error", func() { logger.Error("msg", "tenant", "C") }, true},
+		{"NoKey_Debug", func() { logger.Debug("msg") }, false},
+		{"NoKey_Info", func() { logger.Info("msg") }, true},
+		{"RecordOverridesContext", func() { logger.With("tenant", "B").Debug("msg", "tenant", "A") }, true},
+		{"ContextRedefined", func() { logger.With("tenant", "A").With("tenant", "B").Debug("msg") }, false},
+		{"InGroup", func() { logger.Debug("msg", slog.Group("req", "tenant", "A")) }, false},
+		{"GroupedKey", func() { logger.WithGroup("req").Debug("msg", "tenant", "A") }, false},
+	}
+	dropped := uint64(0)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out.Reset()
+			test.log()
+			if written := out.Len() > 0; written != test.written {
+				t.Errorf("Expected written %v, got %s", test.written, out.String())
+			}
+			if !test.written && test.name != "A_Trace" {
+				// Records below all the overrides are filtered by Enabled, and not counted.
+				dropped++
+			}
+			if st := hdl.Stats(); st.Dropped != dropped {
+				t.Errorf("Expected %d dropped records, got %d", dropped, st.Dropped)
+			}
+		})
+	}
+
+	if !hdl.Enabled(context.Background(), slog.LevelDebug) || hdl.Enabled(context.Background(), LevelTrace) {
+		t.Errorf("Expected Enabled to report the lowest override level")
+	}
+	if lvl := hdl.MinLevel(); lvl != slog.LevelDebug {
+		t.Errorf("Expected minimum level DEBUG, got %s", lvl)
+	}
+}
+
+func TestAttrLevelOverrides_GroupKey(t *testing.T) {
+	out := bytes.Buffer{}
+	logger := slog.New(NewJsonHandler(&out, &HandlerOptions{
+		AttrLevelOverrides: AttrLevelOverrides{Key: "req.tenant", Levels: map[string]slog.Leveler{"A": slog.LevelDebug}},
+	}))
+	logger.WithGroup("req").Debug("record", "tenant", "A")
+	logger.WithGroup("req").With("tenant", "A").WithGroup("sub").Debug("context", "b", 1)
+	logger.Debug("top", "tenant", "A")
+	if got := out.String(); !strings.Contains(got, "record") || !strings.Contains(got, "context") || strings.Contains(got, "top") {
+		t.Errorf("Expected the grouped key to select the override, got %s", got)
+	}
+}
+
+func TestAttrLevelOverrides_StrictEmission(t *testing.T) {
+	hdl := NewJsonHandler(&bytes.Buffer{}, &HandlerOptions{
+		StrictEmission:     true,
+		AttrLevelOverrides: AttrLevelOverrides{Key: "tenant", Levels: map[string]slog.Leveler{"A": slog.LevelDebug}},
+	})
+	rec := slog.NewRecord(now, slog.LevelDebug, "msg", 0)
+	rec.AddAttrs(slog.String("tenant", "B"))
+	if err := hdl.Handle(context.Background(), rec); !errors.Is(err, ErrNotEmitted) {
+		t.Errorf("Expected ErrNotEmitted, got %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package zeroslog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// maxPanicFrames is the maximum number of stack frames reported by LogPanic.
+const maxPanicFrames = 64
+
+// LogPanic logs a record at LevelPanic for the recovered panic value, through h.
+// The record has a "panic" attribute holding the value, and a "stack" attribute holding the stack of the
+// panicking goroutine as an array of "function file:line" strings, starting at the function which panicked.
+// It does nothing if recovered is nil or LevelPanic is not enabled.
+func LogPanic(ctx context.Context, h slog.Handler, recovered any) {
+	if recovered == nil {
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !h.Enabled(ctx, LevelPanic) {
+		return
+	}
+	pcs := make([]uintptr, maxPanicFrames)
+	pcs = pcs[:runtime.Callers(2, pcs)]
+	pcs = panickingFrames(pcs)
+
+	stack := make([]string, 0, len(pcs))
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, fmt.Sprintf("%s %s:%d", frame.Function, frame.File, frame.Line))
+		if !more {
+			break
+		}
+	}
+	var pc uintptr
+	if len(pcs) > 0 {
+		pc = pcs[0]
+	}
+	rec := slog.NewRecord(time.Now(), LevelPanic, "panic recovered", pc)
+	rec.AddAttrs(slog.Any("panic", recovered), slog.Any("stack", stack))
+	_ = h.Handle(ctx, rec)
+}
+
+// RecoverAndLog recovers from a panic and logs it with LogPanic. It must be called directly with defer:
+//
+//	defer zeroslog.RecoverAndLog(ctx, handler)
+func RecoverAndLog(ctx context.Context, h slog.Handler) {
+	if r := recover(); r != nil {
+		LogPanic(ctx, h, r)
+	}
+}
+
+// panickingFrames trims the frames of the runtime panic machinery and the recovering functions from pcs,
+// so that it starts at the function which panicked. pcs is returned unchanged if not called during a panic.
+func panickingFrames(pcs []uintptr) []uintptr {
+	inPanic := false
+	for i, pc := range pcs {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		switch {
+		case frame.Function == "runtime.gopanic":
+			inPanic = true
+		case inPanic && !strings.HasPrefix(frame.Function, "runtime."):
+			return pcs[i:]
+		}
+	}
+	return pcs
+}
@@ -0,0 +1,86 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func panickingFunction() {
+	panic(errors.New("boom"))
+}
+
+func TestRecoverAndLog(t *testing.T) {
+	out := bytes.Buffer{}
+	hdl := NewJsonHandler(&out, &HandlerOptions{AddSource: true})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer RecoverAndLog(context.Background(), hdl)
+		panickingFunction()
+	}()
+	<-done
+
+	m := map[string]any{}
+	if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+		t.Fatalf("Failed to json decode log output: %s", err.Error())
+	}
+	if m[zerolog.LevelFieldName] != zerolog.LevelPanicValue || m["panic"] != "boom" {
+		t.Fatalf("Unexpected record %v", m)
+	}
+	stack, ok := m["stack"].([]any)
+	if !ok || len(stack) < 2 {
+		t.Fatalf("Unexpected stack %v", m["stack"])
+	}
+	if !strings.HasPrefix(stack[0].(string), "github.com/phsym/zeroslog.panickingFunction ") {
+		t.Fatalf("Stack must start at the panicking function, got %v", stack)
+	}
+	if !strings.HasPrefix(stack[1].(string), "github.com/phsym/zeroslog.TestRecoverAndLog.func1 ") {
+		t.Fatalf("Unexpected caller frame %v", stack[1])
+	}
+	if !strings.Contains(m[zerolog.CallerFieldName].(string), "panic_test.go:") {
+		t.Fatalf("Unexpected caller %v", m[zerolog.CallerFieldName])
+	}
+}
+
+func TestLogPanic(t *testing.T) {
+	out := bytes.Buffer{}
+	hdl := NewJsonHandler(&out, &HandlerOptions{Level: LevelPanic + 1})
+	LogPanic(context.Background(), hdl, "filtered")
+	hdl = NewJsonHandler(&out, nil)
+	LogPanic(context.Background(), hdl, nil)
+	if out.Len() > 0 {
+		t.Fatalf("Unexpected output %q", out.String())
+	}
+	LogPanic(context.Background(), hdl, "no panic")
+	m := map[string]any{}
+	if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+		t.Fatalf("Failed to json decode log output: %s", err.Error())
+	}
+	if stack := m["stack"].([]any); !strings.HasPrefix(stack[0].(string), "github.com/phsym/zeroslog.TestLogPanic ") {
+		t.Fatalf("Unexpected stack %v", stack)
+	}
+}
+
+func TestLevelFatalPanic(t *testing.T) {
+	for lvl, exp := range map[slog.Level]zerolog.Level{
+		slog.LevelError + 3: zerolog.ErrorLevel,
+		LevelFatal:          zerolog.FatalLevel,
+		LevelPanic - 1:      zerolog.FatalLevel,
+		LevelPanic:          zerolog.PanicLevel,
+		LevelPanic + 10:     zerolog.PanicLevel,
+	} {
+		if got := ZerologLevel(lvl); got != exp {
+			t.Errorf("Unexpected level %s for %s, expected %s", got, lvl, exp)
+		}
+	}
+	if SlogLevel(zerolog.FatalLevel) != LevelFatal || SlogLevel(zerolog.PanicLevel) != LevelPanic {
+		t.Errorf("Fatal and panic levels don't round-trip")
+	}
+}
@@ -0,0 +1,178 @@
+package zeroslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// ParseEvent reconstructs a record from a single JSON object rendered by zerolog.
+//
+// The level, timestamp and message are read from the fields named after zerolog.LevelFieldName,
+// zerolog.TimestampFieldName and zerolog.MessageFieldName. Other fields are returned as attributes,
+// in the order they appear, with nested objects becoming groups and arrays becoming []any.
+// The returned record holds the same attributes.
+//
+// Parsing degrades gracefully: a missing level means slog.LevelInfo, a missing time the zero time,
+// and a level or time that can't be parsed is kept as a regular attribute.
+// An error is only returned if raw is not a valid JSON object.
+func ParseEvent(raw []byte) (slog.Record, []slog.Attr, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil {
+		return slog.Record{}, nil, fmt.Errorf("zeroslog: invalid event: %w", err)
+	} else if tok != json.Delim('{') {
+		return slog.Record{}, nil, errors.New("zeroslog: invalid event: not a JSON object")
+	}
+	fields, err := parseObject(dec)
+	if err != nil {
+		return slog.Record{}, nil, fmt.Errorf("zeroslog: invalid event: %w", err)
+	}
+	if _, err := dec.Token(); err == nil {
+		return slog.Record{}, nil, errors.New("zeroslog: invalid event: trailing data")
+	}
+
+	var tm time.Time
+	lvl := slog.LevelInfo
+	msg := ""
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, f := range fields {
+		switch str, isStr := f.Value.Any().(string); {
+		case f.Key == zerolog.LevelFieldName && isStr:
+			zlvl, err := zerolog.ParseLevel(str)
+			if err != nil || str == "" {
+				attrs = append(attrs, f)
+				continue
+			}
+			lvl = SlogLevel(zlvl)
+		case f.Key == zerolog.MessageFieldName && isStr:
+			msg = str
+		case f.Key == zerolog.TimestampFieldName:
+			t, ok := parseTime(f.Value)
+			if !ok {
+				attrs = append(attrs, f)
+				continue
+			}
+			tm = t
+		default:
+			attrs = append(attrs, f)
+		}
+	}
+	rec := slog.NewRecord(tm, lvl, msg, 0)
+	rec.AddAttrs(attrs...)
+	return rec, attrs, nil
+}
+
+// parseTime parses a timestamp rendered with zerolog.TimeFieldFormat.
+func parseTime(v slog.Value) (time.Time, bool) {
+	switch val := v.Any().(type) {
+	case string:
+		for _, layout := range []string{zerolog.TimeFieldFormat, time.RFC3339Nano} {
+			if t, err := time.Parse(layout, val); err == nil {
+				return t, true
+			}
+		}
+	case int64:
+		n := val
+		switch zerolog.TimeFieldFormat {
+		case zerolog.TimeFormatUnix:
+			return time.Unix(n, 0), true
+		case zerolog.TimeFormatUnixMs:
+			return time.UnixMilli(n), true
+		case zerolog.TimeFormatUnixMicro:
+			return time.UnixMicro(n), true
+		case zerolog.TimeFormatUnixNano:
+			return time.Unix(0, n), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseObject parses the members of a JSON object whose opening brace has already been read, as attributes.
+func parseObject(dec *json.Decoder) ([]slog.Attr, error) {
+	var attrs []slog.Attr
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected token %v", tok)
+		}
+		tok, err = dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		if tok == json.Delim('{') {
+			members, err := parseObject(dec)
+			if err != nil {
+				return nil, err
+			}
+			attrs = append(attrs, slog.Attr{Key: key, Value: slog.GroupValue(members...)})
+			continue
+		}
+		val, err := parseValue(dec, tok)
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, slog.Any(key, val))
+	}
+	_, err := dec.Token() // Closing brace
+	return attrs, err
+}
+
+// parseValue parses the JSON value starting with tok. Objects nested in arrays become map[string]any.
+func parseValue(dec *json.Decoder, tok json.Token) (any, error) {
+	switch tok {
+	case json.Delim('['):
+		values := []any{}
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := parseValue(dec, tok)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		_, err := dec.Token() // Closing bracket
+		return values, err
+	case json.Delim('{'):
+		m := map[string]any{}
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, ok := tok.(string)
+			if !ok {
+				return nil, fmt.Errorf("unexpected token %v", tok)
+			}
+			if tok, err = dec.Token(); err != nil {
+				return nil, err
+			}
+			if m[key], err = parseValue(dec, tok); err != nil {
+				return nil, err
+			}
+		}
+		_, err := dec.Token() // Closing brace
+		return m, err
+	case json.Delim(']'), json.Delim('}'):
+		return nil, fmt.Errorf("unexpected token %v", tok)
+	}
+	if n, ok := tok.(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			return i, nil
+		}
+		return n.Float64()
+	}
+	return tok, nil
+}
@@ -0,0 +1,80 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestParseEvent_RoundTrip(t *testing.T) {
+	out := bytes.Buffer{}
+	hdl := NewJsonHandler(&out, nil)
+	rec := slog.NewRecord(now, slog.LevelWarn, "foobar", 0)
+	rec.AddAttrs(attrs...)
+	hdl.Handle(context.Background(), rec)
+	original := out.Bytes()
+
+	parsed, pattrs, err := ParseEvent(original)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if parsed.Level != slog.LevelWarn || parsed.Message != "foobar" || !parsed.Time.Equal(now.Truncate(time.Second)) {
+		t.Fatalf("Unexpected record %v", parsed)
+	}
+	if parsed.NumAttrs() != len(pattrs) {
+		t.Fatalf("Record and attributes mismatch")
+	}
+	if pattrs[0].Key != "titi" || pattrs[len(pattrs)-1].Key != "json-err" {
+		t.Fatalf("Attributes order not preserved: %v", pattrs)
+	}
+
+	rendered := bytes.Buffer{}
+	NewJsonHandler(&rendered, nil).Handle(context.Background(), parsed)
+	m1, m2 := map[string]any{}, map[string]any{}
+	if err := json.Unmarshal(original, &m1); err != nil {
+		t.Fatalf("Failed to json decode log output: %s", err.Error())
+	}
+	if err := json.Unmarshal(rendered.Bytes(), &m2); err != nil {
+		t.Fatalf("Failed to json decode log output: %s", err.Error())
+	}
+	if !reflect.DeepEqual(m1, m2) {
+		t.Fatalf("Round-trip mismatch. Got %v, expected %v", m2, m1)
+	}
+}
+
+func TestParseEvent(t *testing.T) {
+	rec, attrs, err := ParseEvent([]byte(`{"level":"bogus","time":"yesterday","n":1.5,"arr":[1,"a",{"b":true}],"obj":{"sub":{"x":null}}}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if rec.Level != slog.LevelInfo || !rec.Time.IsZero() || rec.Message != "" {
+		t.Fatalf("Unexpected record %v", rec)
+	}
+	expected := []slog.Attr{
+		slog.String(zerolog.LevelFieldName, "bogus"),
+		slog.String(zerolog.TimestampFieldName, "yesterday"),
+		slog.Float64("n", 1.5),
+		slog.Any("arr", []any{int64(1), "a", map[string]any{"b": true}}),
+		slog.Group("obj", slog.Group("sub", slog.Any("x", nil))),
+	}
+	if len(attrs) != len(expected) {
+		t.Fatalf("Unexpected attributes %v", attrs)
+	}
+	for i := range expected {
+		if attrs[i].String() != expected[i].String() || attrs[i].Value.Kind() != expected[i].Value.Kind() {
+			t.Errorf("Unexpected attribute %v, expected %v", attrs[i], expected[i])
+		}
+	}
+
+	for _, raw := range []string{``, `[1]`, `{"a":}`, `{"a":1} {}`, `"str"`} {
+		if _, _, err := ParseEvent([]byte(raw)); err == nil {
+			t.Errorf("Expected error for %q", raw)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package zeroslog
+
+import (
+	"log/slog"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+// attrSegment holds the attributes given to one WithAttrs call, linked to the ones given before.
+type attrSegment struct {
+	prev  *attrSegment
+	attrs []slog.Attr
+	// ctx caches the context built by pendingAttrs.apply for this segment, and logger its logger.
+	ctx    atomic.Pointer[zerolog.Context]
+	logger atomic.Pointer[zerolog.Logger]
+}
+
+// pendingAttrs are attributes added with WithAttrs, which are only written into a zerolog context
+// the first time a record is handled.
+//
+// Writing attributes into the context at each WithAttrs call copies the whole context of the parent
+// handler, which is quadratic over long chains of derivations, like the ones made by middlewares.
+// Instead, attributes are linked to the ones of the parent, and written once, in order, by apply.
+type pendingAttrs struct {
+	last *attrSegment
+}
+
+// add returns pending attributes extended with attrs, which must already be resolved.
+func (p pendingAttrs) add(attrs []slog.Attr) pendingAttrs {
+	if len(attrs) == 0 {
+		return p
+	}
+	return pendingAttrs{last: &attrSegment{prev: p.last, attrs: attrs}}
+}
+
+// applied returns the context cached by a previous apply call, if any.
+func (p pendingAttrs) applied() (zerolog.Context, bool) {
+	if p.last == nil {
+		return zerolog.Context{}, false
+	}
+	if ctx := p.last.ctx.Load(); ctx != nil {
+		return *ctx, true
+	}
+	return zerolog.Context{}, false
+}
+
+// apply returns the context of base with the pending attributes written into it, bounded by lim. base and
+// lim must be the same at each call, since the result is cached.
+func (p pendingAttrs) apply(base zerolog.Logger, lim attrLimits) zerolog.Context {
+	if p.last == nil {
+		return base.With()
+	}
+	if ctx := p.last.ctx.Load(); ctx != nil {
+		return *ctx
+	}
+	ctx := appendSegments(base.With(), lim, p.last) // base.With copies the context of base, so that it can be appended to.
+	// Concurrent calls compute the same context, keep the first one.
+	p.last.ctx.CompareAndSwap(nil, &ctx)
+	return *p.last.ctx.Load()
+}
+
+// applyLogger returns the logger of the context returned by apply, which must not be modified.
+// There must be pending attributes.
+func (p pendingAttrs) applyLogger(base *zerolog.Logger, lim attrLimits) *zerolog.Logger {
+	if l := p.last.logger.Load(); l != nil {
+		return l
+	}
+	l := p.apply(*base, lim).Logger()
+	p.last.logger.CompareAndSwap(nil, &l)
+	return p.last.logger.Load()
+}
+
+// appendSegments writes the attributes of s and its previous segments into target, oldest first, bounded by lim.
+func appendSegments[T zlogWriter[T]](target T, lim attrLimits, s *attrSegment) T {
+	if s == nil {
+		return target
+	}
+	return mapAttrs(appendSegments(target, lim, s.prev), lim, s.attrs...)
+}
+
+// collect appends the pending attributes to dst, oldest first.
+func (p pendingAttrs) collect(dst []slog.Attr) []slog.Attr {
+	return appendSegmentAttrs(dst, p.last)
+}
+
+// appendSegmentAttrs appends the attributes of s and its previous segments to dst, oldest first.
+func appendSegmentAttrs(dst []slog.Attr, s *attrSegment) []slog.Attr {
+	if s == nil {
+		return dst
+	}
+	return append(appendSegmentAttrs(dst, s.prev), s.attrs...)
+}
@@ -0,0 +1,87 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+)
+
+// chain derives hdl with one WithAttrs call per attribute, optionally opening a group in the middle.
+func chain(hdl slog.Handler, group string, attrs []slog.Attr) slog.Handler {
+	for i, a := range attrs {
+		if group != "" && i == len(attrs)/2 {
+			hdl = hdl.WithGroup(group)
+		}
+		hdl = hdl.WithAttrs([]slog.Attr{a})
+	}
+	return hdl
+}
+
+func TestWithAttrs_DeepChain(t *testing.T) {
+	for _, group := range []string{"", "g"} {
+		t.Run(fmt.Sprintf("group=%q", group), func(t *testing.T) {
+			chained, flat := bytes.Buffer{}, bytes.Buffer{}
+			hdl := chain(NewJsonHandler(&chained, nil), group, attrs)
+			var ref slog.Handler = NewJsonHandler(&flat, nil)
+			if group == "" {
+				ref = ref.WithAttrs(attrs)
+			} else {
+				ref = ref.WithAttrs(attrs[:len(attrs)/2]).WithGroup(group).WithAttrs(attrs[len(attrs)/2:])
+			}
+			for i := 0; i < 2; i++ { // The second record uses the cached context
+				rec := slog.NewRecord(now, slog.LevelInfo, "foobar", 0)
+				rec.AddAttrs(attrs...)
+				hdl.Handle(context.Background(), rec)
+				ref.Handle(context.Background(), rec)
+			}
+			if chained.String() != flat.String() {
+				t.Fatalf("Unexpected output:\n%s\nexpected:\n%s", chained.String(), flat.String())
+			}
+		})
+	}
+}
+
+func TestWithAttrs_Branches(t *testing.T) {
+	out := bytes.Buffer{}
+	base := NewJsonHandler(&out, nil).WithAttrs([]slog.Attr{slog.Int("a", 1)})
+	// Materialize the parent context before deriving, then derive siblings from it.
+	base.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "parent", 0))
+	left := base.WithAttrs([]slog.Attr{slog.Int("left", 2)})
+	right := base.WithAttrs([]slog.Attr{slog.Int("right", 3)})
+	deep := left.WithAttrs([]slog.Attr{slog.Int("deep", 4)})
+	for exp, h := range map[string]slog.Handler{
+		`"a":1,"left":2,`:          left,
+		`"a":1,"right":3,`:         right,
+		`"a":1,"time"`:             base,
+		`"a":1,"left":2,"deep":4,`: deep,
+	} {
+		out.Reset()
+		h.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "foobar", 0))
+		if !bytes.Contains(out.Bytes(), []byte(exp)) {
+			t.Fatalf("Missing %s in output: %s", exp, out.String())
+		}
+	}
+}
+
+func TestWithAttrs_ConcurrentApply(t *testing.T) {
+	out := syncBuffer{}
+	hdl := chain(NewJsonHandler(&out, nil), "g", attrs)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			hdl.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "foobar", 0))
+		}()
+	}
+	wg.Wait()
+	lines := bytes.Split(bytes.TrimSpace(out.buf.Bytes()), []byte("\n"))
+	for _, l := range lines[1:] {
+		if !bytes.Equal(l, lines[0]) {
+			t.Fatalf("Inconsistent records:\n%s\n%s", lines[0], l)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package zeroslog
+
+import "log/slog"
+
+// attrStage is a stage of a pipeline. It receives a resolved attribute whose dot-joined group path
+// is prefix, and returns the attribute to pass to the next stage, or false to drop it.
+type attrStage func(prefix string, a slog.Attr) (slog.Attr, bool)
+
+// pipeline is the record processing core shared by all the handlers of this package, whatever their
+// output format. Records are first normalized into resolved attributes, with appendResolvedAttrs and
+// resolveAttrs, which then go through ordered stages applying the attribute related options.
+// The encoder of the handler comes last, and only sees the attributes returned by the pipeline,
+// so that options have the same effect on all formats.
+//
+// A pipeline is built once from the immutable configuration, and shared by derived handlers.
+type pipeline struct {
+	stages []attrStage
+	// intern is the cache used by the InternStrings stage, if any.
+	intern *internCache
+	// keys is the tracker used by the MaxUniqueKeys stage, if any.
+	keys *keyTracker
+}
+
+// newPipeline creates the pipeline applying opts. Dropped attributes are counted in st, if not nil.
+// reserved reports whether a top-level key collides with a field written by the encoder;
+// if nil, the ReservedKeyPolicy stage is omitted.
+func newPipeline(opts *HandlerOptions, st *stats, reserved func(key string) bool) *pipeline {
+	p := &pipeline{intern: newInternCache(opts.InternStrings)}
+	if allow := newKeyMatcher(opts.AllowKeys); allow != nil {
+		p.stages = append(p.stages, allowStage(allow, st))
+	}
+	if opts.ReplaceAttr != nil {
+		p.stages = append(p.stages, replaceStage(opts.ReplaceAttr))
+	}
+	if opts.DropEmptyKeys {
+		p.stages = append(p.stages, emptyKeyStage())
+	}
+	if p.keys = newKeyTracker(opts.MaxUniqueKeys); p.keys != nil {
+		p.stages = append(p.stages, cardinalityStage(p.keys, st))
+	}
+	if p.intern != nil {
+		p.stages = append(p.stages, leafStage(func(a slog.Attr) slog.Attr {
+			a.Value = p.intern.internValue(a.Value)
+			return a
+		}))
+	}
+	if units := newUnitCoercer(opts.UnitCoercion); units != nil {
+		p.stages = append(p.stages, leafStage(units.coerce))
+	}
+	if opts.MaxSliceLen > 0 {
+		p.stages = append(p.stages, sliceLimitStage(opts.MaxSliceLen))
+	}
+	if keys := newKeyMatcher(opts.StringifyValues); keys != nil {
+		p.stages = append(p.stages, stringifyStage(keys))
+	}
+	if opts.SourceFormat != SourceString {
+		p.stages = append(p.stages, sourceStage(opts.SourceFormat))
+	}
+	if opts.ValidateUTF8 {
+		p.stages = append(p.stages, utf8Stage(st))
+	}
+	if reserved != nil && opts.ReservedKeyPolicy != ReservedKeyAllow {
+		p.stages = append(p.stages, reservedKeyStage(opts.ReservedKeyPolicy, reserved, st))
+	}
+	return p
+}
+
+// empty reports whether the pipeline writes attributes unchanged.
+func (p *pipeline) empty() bool {
+	return len(p.stages) == 0
+}
+
+// attr runs a, whose group path is prefix, through all the stages.
+// It returns the attribute to encode, and false if nothing is left to encode.
+func (p *pipeline) attr(prefix string, a slog.Attr) (slog.Attr, bool) {
+	if p.empty() {
+		return a, true
+	}
+	a.Value = a.Value.Resolve()
+	for _, stage := range p.stages {
+		var ok bool
+		if a, ok = stage(prefix, a); !ok {
+			return a, false
+		}
+	}
+	return a, true
+}
+
+// attrs runs attrs, whose group path is prefix, through all the stages.
+// It returns attrs itself if the pipeline is empty.
+func (p *pipeline) attrs(prefix string, attrs []slog.Attr) []slog.Attr {
+	if p.empty() {
+		return attrs
+	}
+	transformed := make([]slog.Attr, 0, len(attrs))
+	var over overflowAttrs
+	for _, a := range attrs {
+		if a, ok := p.attr(prefix, a); ok && !p.overflow(&over, a) {
+			transformed = append(transformed, a)
+		}
+	}
+	if a, ok := over.attr(); ok {
+		transformed = append(transformed, a)
+	}
+	return transformed
+}
+
+// overflow collects a into o and reports true if it's an OverflowKey group returned by the MaxUniqueKeys stage
+// for a top-level attribute, which must be written with the other ones of its object once o.attr is called.
+// It always reports false without MaxUniqueKeys.
+func (p *pipeline) overflow(o *overflowAttrs, a slog.Attr) bool {
+	return p.keys != nil && o.add(a)
+}
+
+// countDropped counts a dropped attribute in st, if not nil.
+func countDropped(st *stats) {
+	if st != nil {
+		st.droppedAttrs.Add(1)
+	}
+}
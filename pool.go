@@ -0,0 +1,59 @@
+package zeroslog
+
+import (
+	"log/slog"
+	"sync"
+)
+
+// maxPooledAttrs is the capacity above which attribute buffers are not returned to the pool,
+// so that a few records with many attributes don't retain memory forever.
+const maxPooledAttrs = 128
+
+// attrsPool holds scratch buffers used to materialize record attributes.
+var attrsPool = sync.Pool{
+	New: func() any {
+		attrs := make([]slog.Attr, 0, 16)
+		return &attrs
+	},
+}
+
+// getAttrs returns an empty attribute buffer from the pool. It must be given back with putAttrs.
+func getAttrs() *[]slog.Attr {
+	return attrsPool.Get().(*[]slog.Attr)
+}
+
+// putAttrs gives attrs back to the pool. The buffer is cleared so that pooled buffers
+// don't retain attribute values, and must not be used anymore.
+func putAttrs(attrs *[]slog.Attr) {
+	if cap(*attrs) > maxPooledAttrs {
+		return
+	}
+	clear(*attrs)
+	*attrs = (*attrs)[:0]
+	attrsPool.Put(attrs)
+}
+
+// maxPooledBuffer is the capacity above which byte buffers are not returned to the pool.
+const maxPooledBuffer = 1 << 10
+
+// bufferPool holds scratch buffers used to format values.
+var bufferPool = sync.Pool{
+	New: func() any {
+		buf := make([]byte, 0, 256)
+		return &buf
+	},
+}
+
+// getBuffer returns an empty byte buffer from the pool. It must be given back with putBuffer.
+func getBuffer() *[]byte {
+	return bufferPool.Get().(*[]byte)
+}
+
+// putBuffer gives buf back to the pool. It must not be used anymore.
+func putBuffer(buf *[]byte) {
+	if cap(*buf) > maxPooledBuffer {
+		return
+	}
+	*buf = (*buf)[:0]
+	bufferPool.Put(buf)
+}
@@ -0,0 +1,76 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"testing"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func TestGroups_Concurrent(t *testing.T) {
+	out := syncBuffer{}
+	hdl := NewJsonHandler(&out, &HandlerOptions{AuditKeys: []string{"a.b.n"}}).
+		WithGroup("a").WithAttrs([]slog.Attr{slog.String("foo", "bar")}).WithGroup("b")
+	const goroutines, records = 8, 200
+	wg := sync.WaitGroup{}
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < records; i++ {
+				n := g*records + i
+				rec := slog.NewRecord(now, slog.LevelInfo, "foobar", 0)
+				rec.AddAttrs(
+					slog.Int("n", n),
+					slog.Group("sub", slog.Int("n", n), slog.Any("lazy", Lazy(func() slog.Value { return slog.IntValue(n) }))),
+					slog.Bool("audit", true),
+				)
+				if err := hdl.Handle(context.Background(), rec); err != nil {
+					t.Error(err)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	type line struct {
+		A struct {
+			Foo string
+			B   struct {
+				N   int
+				Sub struct{ N, Lazy int }
+			}
+		}
+		Incomplete []string `json:"audit_incomplete"`
+	}
+	dec := json.NewDecoder(&out.buf)
+	seen := map[int]bool{}
+	for dec.More() {
+		var l line
+		if err := dec.Decode(&l); err != nil {
+			t.Fatalf("Failed to json decode log output: %s", err)
+		}
+		b := l.A.B
+		if l.A.Foo != "bar" || b.Sub.N != b.N || b.Sub.Lazy != b.N || len(l.Incomplete) > 0 || seen[b.N] {
+			t.Fatalf("Inconsistent record: %+v", l)
+		}
+		seen[b.N] = true
+	}
+	if len(seen) != goroutines*records {
+		t.Fatalf("Unexpected %d records, expected %d", len(seen), goroutines*records)
+	}
+}
@@ -0,0 +1,95 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+
+	"github.com/rs/zerolog"
+)
+
+// prettyMarker is the value of the attribute returned by Pretty.
+type prettyMarker struct{}
+
+// prettyAttrKey is the key of the attribute returned by Pretty.
+const prettyAttrKey = "zeroslog.pretty"
+
+// prettyKey is the context key flagging the record being handled as pretty.
+type prettyKey struct{}
+
+// Pretty returns a marker attribute making handlers created from an io.Writer write the record it's
+// added to as indented, multi-line JSON, for instance to dump a record while debugging:
+//
+//	logger.Info("state", "config", cfg, zeroslog.Pretty())
+//
+// The marker is never written, and other records are left unchanged. Console handlers print the record
+// as usual. Handlers created with NewHandler, whose output is unknown, only remove the marker.
+func Pretty() slog.Attr {
+	useMarkers()
+	return slog.Any(prettyAttrKey, prettyMarker{})
+}
+
+// isPretty reports whether a is the marker returned by Pretty.
+func isPretty(a slog.Attr) bool {
+	if a.Value.Kind() != slog.KindAny {
+		return false
+	}
+	_, ok := a.Value.Any().(prettyMarker)
+	return ok
+}
+
+// withPretty returns a copy of ctx flagging the record being handled as pretty.
+func withPretty(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, prettyKey{}, true)
+}
+
+// prettyFromContext reports whether ctx flags the record being handled as pretty.
+func prettyFromContext(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	pretty, _ := ctx.Value(prettyKey{}).(bool)
+	return pretty
+}
+
+// prettyWriter indents the JSON records written to out.
+type prettyWriter struct {
+	out io.Writer
+}
+
+var _ zerolog.LevelWriter = prettyWriter{}
+
+// Write implements io.Writer. Records which are not valid JSON are written unchanged.
+func (w prettyWriter) Write(p []byte) (int, error) {
+	if _, err := w.out.Write(indentRecord(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WriteLevel implements zerolog.LevelWriter, so that a level writer output still receives the level.
+func (w prettyWriter) WriteLevel(lvl zerolog.Level, p []byte) (int, error) {
+	lw, ok := w.out.(zerolog.LevelWriter)
+	if !ok {
+		return w.Write(p)
+	}
+	if _, err := lw.WriteLevel(lvl, indentRecord(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// indentRecord returns the JSON record p indented, followed by a newline, or p if it's not valid JSON.
+func indentRecord(p []byte) []byte {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, bytes.TrimRight(p, "\n"), "", "  "); err != nil {
+		return p
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
@@ -0,0 +1,74 @@
+package zeroslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestPretty(t *testing.T) {
+	for name, tc := range map[string]struct {
+		logger func(*bytes.Buffer) *slog.Logger
+		lines  int
+	}{
+		"json": {func(b *bytes.Buffer) *slog.Logger { return slog.New(NewJsonHandler(b, nil)) }, 9},
+		"strict": {func(b *bytes.Buffer) *slog.Logger {
+			return slog.New(NewJsonHandler(b, &HandlerOptions{StrictSlogCompliance: true}))
+		}, 9},
+		"group": {func(b *bytes.Buffer) *slog.Logger {
+			return slog.New(NewJsonHandler(b, nil)).With("svc", "api").WithGroup("g")
+		}, 12},
+		"logger": {func(b *bytes.Buffer) *slog.Logger { return slog.New(NewHandler(zerolog.New(b), nil)) }, 1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			out := bytes.Buffer{}
+			logger := tc.logger(&out)
+			logger.Info("before", "a", 1)
+			logger.Info("dump", "a", 1, Pretty(), slog.Group("cfg", "x", true))
+			logger.Info("after", "a", 1)
+
+			if strings.Contains(out.String(), prettyAttrKey) {
+				t.Errorf("Marker written in %s", out.String())
+			}
+			dec := json.NewDecoder(&out)
+			for _, msg := range []string{"before", "dump", "after"} {
+				var raw json.RawMessage
+				if err := dec.Decode(&raw); err != nil {
+					t.Fatal(err)
+				}
+				lines := 1
+				if msg == "dump" {
+					lines = tc.lines
+				}
+				if n := len(strings.Split(string(raw), "\n")); n != lines {
+					t.Errorf("Expected %q on %d lines, got %d: %s", msg, lines, n, raw)
+				}
+			}
+		})
+	}
+}
+
+func TestPretty_Only(t *testing.T) {
+	out := bytes.Buffer{}
+	slog.New(NewJsonHandler(&out, nil)).WithGroup("g").Info("dump", Pretty())
+	var m map[string]any
+	if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m["g"]; ok || !strings.Contains(out.String(), "\n  ") {
+		t.Errorf("Unexpected record %s", out.String())
+	}
+}
+
+func TestPretty_Console(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	out := bytes.Buffer{}
+	slog.New(NewConsoleHandler(&out, nil)).Info("dump", "a", 1, Pretty())
+	if txt := out.String(); strings.Contains(txt, prettyAttrKey) || !strings.Contains(txt, "INF dump a=1") || strings.Count(txt, "\n") != 1 {
+		t.Errorf("Unexpected console output %q", txt)
+	}
+}
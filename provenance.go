@@ -0,0 +1,40 @@
+package zeroslog
+
+import "log/slog"
+
+// Origins of attributes, appended to their keys with TagProvenance.
+const (
+	originRecord  = "rec"
+	originWith    = "with"
+	originDefault = "default"
+	originLevel   = "level"
+)
+
+// tag appends the marker of origin to the key of a, if TagProvenance is set.
+func (h *Handler) tag(a slog.Attr, origin string) slog.Attr {
+	if h.opts.TagProvenance && a.Key != "" {
+		a.Key += "@" + origin
+	}
+	return a
+}
+
+// tagAll is like tag for all of attrs. attrs is returned unchanged if TagProvenance isn't set.
+func (h *Handler) tagAll(attrs []slog.Attr, origin string) []slog.Attr {
+	if !h.opts.TagProvenance {
+		return attrs
+	}
+	tagged := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		tagged[i] = h.tag(a, origin)
+	}
+	return tagged
+}
+
+// recordOrigin returns the origin of the i-th attribute of a record whose first n attributes are its own,
+// and the following ones come from LevelAttrs.
+func recordOrigin(i, n int) string {
+	if i < n {
+		return originRecord
+	}
+	return originLevel
+}
@@ -0,0 +1,40 @@
+package zeroslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestTagProvenance(t *testing.T) {
+	for _, strict := range []bool{false, true} {
+		out := bytes.Buffer{}
+		logger := slog.New(NewJsonHandler(&out, &HandlerOptions{
+			TagProvenance:        true,
+			StrictSlogCompliance: strict,
+			DefaultAttrs:         []slog.Attr{slog.String("region", "eu")},
+			LevelAttrs: map[slog.Level]func() []slog.Attr{
+				slog.LevelInfo: func() []slog.Attr { return []slog.Attr{slog.Int("heap", 12)} },
+			},
+		})).With("user", "bob")
+		logger.Info("msg", "req", 1)
+		logger.WithGroup("g").With("user", "alice").Info("msg", "req", 2)
+
+		dec := json.NewDecoder(&out)
+		for _, expected := range []map[string]any{
+			{"user@with": "bob", "region@default": "eu", "heap@level": 12.0, "req@rec": 1.0},
+			{"user@with": "bob", "region@default": "eu", "heap@level": 12.0, "g": map[string]any{"user@with": "alice", "req@rec": 2.0}},
+		} {
+			m := map[string]any{}
+			if err := dec.Decode(&m); err != nil {
+				t.Fatalf("Failed to json decode log output: %s", err.Error())
+			}
+			for k, v := range expected {
+				if !jsonEqual(m[k], v) {
+					t.Errorf("Strict %t: expected %s to be %v, got %v", strict, k, v, m)
+				}
+			}
+		}
+	}
+}
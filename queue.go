@@ -0,0 +1,200 @@
+package zeroslog
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+// QueueOptions configures the queues of handlers created with NewQueuedHandler.
+type QueueOptions struct {
+	// Shards is the number of queues records are spread over, to reduce contention between producers.
+	// It defaults to runtime.GOMAXPROCS.
+	Shards int
+	// ShardSize is the capacity of each queue, in records. It defaults to 1024.
+	ShardSize int
+	// DropWhenFull makes Handle drop records when their queue is full, instead of waiting for room.
+	// Dropped records are counted in Stats and reported to OnError as ErrQueueFull.
+	DropWhenFull bool
+}
+
+// QueuedHandler is a Handler writing serialized records from a background goroutine.
+type QueuedHandler struct {
+	*Handler
+	writer *queueWriter
+}
+
+// NewQueuedHandler creates a handler serializing records as JSON in the calling goroutine, and handing them
+// to a single background goroutine writing them to out, through queues spread over queue.Shards shards.
+// Under contention, it's faster than writing to out directly, as producers don't wait for each other nor
+// for out, at the cost of bounded memory and some latency.
+//
+// Records handled by a goroutine are written in order, while records handled concurrently are written
+// in an approximate order. When a queue is full, Handle waits for room, unless queue.DropWhenFull is set.
+// Write failures are reported to opts.OnError.
+//
+// As with NewJsonHandler, records below zerolog.InfoLevel are discarded unless opts.Level is set.
+// Close must be called to write pending records and release the background goroutine.
+func NewQueuedHandler(out io.Writer, queue QueueOptions, opts *HandlerOptions) *QueuedHandler {
+	h := NewHandler(zerolog.New(nil).Level(shortcutLevel(opts)), opts)
+	w := newQueueWriter(out, queue, h.reportBatchError)
+	h.setOutput(w)
+	return &QueuedHandler{Handler: h, writer: w}
+}
+
+// Close writes the pending records, and stops the background goroutine. Records handled after Close are dropped.
+func (h *QueuedHandler) Close() error {
+	return errors.Join(h.Handler.Close(), h.writer.Close())
+}
+
+// queuedRecord is a serialized record, with its sequence number.
+type queuedRecord struct {
+	seq  uint64
+	line []byte
+}
+
+// queueShard is a queue of a queueWriter. Records are numbered and enqueued with mu held,
+// so that they're queued in the order of their sequence numbers.
+type queueShard struct {
+	mu      sync.Mutex
+	records chan queuedRecord
+}
+
+// queueWriter is an io.Writer spreading records over sharded queues, drained by a single goroutine.
+// Records get increasing sequence numbers, and the goroutine writes the record with the lowest number
+// among the heads of the queues, once it checked that the empty queues are still empty. Since a goroutine
+// enqueues a record before numbering the next one, its records are then written in order.
+type queueWriter struct {
+	out    io.Writer
+	drop   bool
+	shards []*queueShard
+	// onError reports write failures and dropped records.
+	onError func(err error, n int)
+	seq     atomic.Uint64
+	// kick wakes the goroutine up when records are enqueued.
+	kick chan struct{}
+
+	// mu is held for reading while enqueuing, and for writing to close the queues.
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// newQueueWriter creates a queueWriter and starts its goroutine.
+func newQueueWriter(out io.Writer, opts QueueOptions, onError func(err error, n int)) *queueWriter {
+	if opts.Shards <= 0 {
+		opts.Shards = runtime.GOMAXPROCS(0)
+	}
+	if opts.ShardSize <= 0 {
+		opts.ShardSize = 1024
+	}
+	w := &queueWriter{
+		out:     out,
+		drop:    opts.DropWhenFull,
+		shards:  make([]*queueShard, opts.Shards),
+		onError: onError,
+		kick:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	for i := range w.shards {
+		w.shards[i] = &queueShard{records: make(chan queuedRecord, opts.ShardSize)}
+	}
+	w.wg.Add(1)
+	go w.run()
+	return w
+}
+
+// Write implements io.Writer. p is a single record.
+func (w *queueWriter) Write(p []byte) (int, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		w.onError(ErrClosed, 1)
+		return len(p), nil
+	}
+	line := append([]byte(nil), p...) // zerolog reuses p once Write returns.
+	shard := w.shards[rand.Intn(len(w.shards))]
+	shard.mu.Lock()
+	rec := queuedRecord{seq: w.seq.Add(1), line: line}
+	if w.drop {
+		select {
+		case shard.records <- rec:
+		default:
+			shard.mu.Unlock()
+			w.onError(ErrQueueFull, 1)
+			return len(p), nil
+		}
+	} else {
+		shard.records <- rec
+	}
+	shard.mu.Unlock()
+	select {
+	case w.kick <- struct{}{}:
+	default:
+	}
+	return len(p), nil
+}
+
+// run writes queued records until the writer is closed and the queues are empty.
+func (w *queueWriter) run() {
+	defer w.wg.Done()
+	heads := make([]*queuedRecord, len(w.shards))
+	heldHeads := make([]queuedRecord, len(w.shards))
+	closing := false
+	for {
+		received := false
+		for i, shard := range w.shards {
+			// The goroutine is the only receiver, so receiving from a non-empty queue doesn't block.
+			if heads[i] == nil && len(shard.records) > 0 {
+				heldHeads[i] = <-shard.records
+				heads[i] = &heldHeads[i]
+				received = true
+			}
+		}
+		if received {
+			// Records enqueued before the new heads were numbered may now be visible.
+			continue
+		}
+		next := -1
+		for i, head := range heads {
+			if head != nil && (next < 0 || head.seq < heads[next].seq) {
+				next = i
+			}
+		}
+		if next >= 0 {
+			if _, err := w.out.Write(heads[next].line); err != nil {
+				w.onError(err, 1)
+			}
+			heads[next] = nil
+			continue
+		}
+		if closing {
+			return
+		}
+		select {
+		case <-w.kick:
+		case <-w.done:
+			closing = true
+		}
+	}
+}
+
+// Close writes the pending records, and stops the goroutine.
+func (w *queueWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+	close(w.done)
+	w.wg.Wait()
+	return nil
+}
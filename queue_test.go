@@ -0,0 +1,98 @@
+package zeroslog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+)
+
+// gatedWriter buffers records, blocking writes until release is closed.
+type gatedWriter struct {
+	release chan struct{}
+	mu      sync.Mutex
+	buf     bytes.Buffer
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	<-w.release
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func TestQueuedHandler_Order(t *testing.T) {
+	out := &gatedWriter{release: make(chan struct{})}
+	close(out.release)
+	hdl := NewQueuedHandler(out, QueueOptions{Shards: 8, ShardSize: 4}, nil)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			logger := slog.New(hdl).With("g", g)
+			for i := 0; i < 500; i++ {
+				logger.Info("msg", "i", i)
+			}
+		}(g)
+	}
+	wg.Wait()
+	if err := hdl.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	last := map[float64]float64{}
+	n := 0
+	scanner := bufio.NewScanner(&out.buf)
+	for scanner.Scan() {
+		var m map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			t.Fatal(err)
+		}
+		g, i := m["g"].(float64), m["i"].(float64)
+		if prev, ok := last[g]; ok && i != prev+1 {
+			t.Fatalf("Record %v of goroutine %v written after record %v", i, g, prev)
+		}
+		last[g] = i
+		n++
+	}
+	if n != 4000 {
+		t.Errorf("Expected 4000 records, got %d", n)
+	}
+}
+
+func TestQueuedHandler_Drop(t *testing.T) {
+	out := &gatedWriter{release: make(chan struct{})}
+	var errs []error
+	hdl := NewQueuedHandler(out, QueueOptions{Shards: 2, ShardSize: 2, DropWhenFull: true}, &HandlerOptions{
+		OnError: func(err error) { errs = append(errs, err) },
+	})
+	logger := slog.New(hdl)
+	for i := 0; i < 20; i++ {
+		logger.Info("msg", "i", i)
+	}
+	// The writer goroutine holds at most one record per shard, and blocks writing one of them.
+	if st := hdl.Stats(); st.Dropped < 20-3*2 || st.Dropped != uint64(len(errs)) {
+		t.Errorf("Expected at least 14 dropped records, reported as errors, got %d and %d errors", st.Dropped, len(errs))
+	}
+	for _, err := range errs {
+		if !errors.Is(err, ErrQueueFull) {
+			t.Errorf("Unexpected error %v", err)
+		}
+	}
+	close(out.release)
+	if err := hdl.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if lines, st := bytes.Count(out.buf.Bytes(), []byte("\n")), hdl.Stats(); uint64(lines)+st.Dropped != 20 {
+		t.Errorf("Expected the records not dropped to be written on Close, got %d lines and %d dropped", lines, st.Dropped)
+	}
+
+	logger.Info("after close")
+	if st := hdl.Stats(); bytes.Contains(out.buf.Bytes(), []byte("after close")) || !errors.Is(errs[len(errs)-1], ErrClosed) {
+		t.Errorf("Expected records handled after Close to be dropped, got %d dropped", st.Dropped)
+	}
+}
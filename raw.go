@@ -0,0 +1,120 @@
+package zeroslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/rs/zerolog"
+)
+
+var (
+	// ErrInvalidRaw is returned by HandleRaw when the record is not a single JSON object.
+	ErrInvalidRaw = errors.New("zeroslog: raw record is not a JSON object")
+	// ErrNoOutput is returned by HandleRaw when the handler wraps a logger whose output is unknown.
+	ErrNoOutput = errors.New("zeroslog: handler output is unknown")
+)
+
+// HandleRaw writes raw, an already serialized JSON object, at the given level, without decoding and
+// re-encoding it. It's meant to replay or forward records serialized elsewhere.
+//
+// The level and timestamp fields are added if raw lacks them, the level being omitted with OmitLevel.
+// raw is otherwise written unchanged, followed by a newline, to the output of the handler, with level
+// filtering and Stats counters like records passed to Handle. Attributes added with WithAttrs, hooks,
+// and the other options transforming attributes are not applied.
+//
+// HandleRaw returns ErrInvalidRaw if raw is not a JSON object, and ErrNoOutput if the handler was created
+// with NewHandler, since the output of a zerolog logger can't be retrieved. Records below the handler level
+// are discarded, returning a nil error unless StrictEmission is set.
+func (h *Handler) HandleRaw(level slog.Level, raw []byte) error {
+	if h.isZero() {
+		return nil
+	}
+	raw = bytes.TrimSpace(raw)
+	hasLevel, hasTime, err := rawEnvelope(raw, &h.opts.FieldNames)
+	if err != nil {
+		return err
+	}
+	if h.out == nil {
+		return ErrNoOutput
+	}
+	if h.opts.StrictEmission {
+		if err := h.emissionError(level); err != nil {
+			return err
+		}
+	}
+	if !h.shouldEmit(level) {
+		return nil
+	}
+
+	buf := make([]byte, 0, len(raw)+64)
+	if hasTime && (hasLevel || h.opts.OmitLevel) {
+		buf = append(buf, raw...)
+	} else {
+		buf = appendRawEnvelope(buf, &h.opts.FieldNames, level, !hasLevel && !h.opts.OmitLevel, !hasTime)
+		if len(bytes.TrimSpace(raw[1:len(raw)-1])) > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, raw[1:]...)
+	}
+	buf = append(buf, '\n')
+
+	if lw, ok := h.out.(zerolog.LevelWriter); ok {
+		_, err = lw.WriteLevel(ZerologLevel(level), buf)
+	} else {
+		_, err = h.out.Write(buf)
+	}
+	if err != nil {
+		h.stats.dropped.Add(1)
+		return fmt.Errorf("zeroslog: failed to write raw record: %w", err)
+	}
+	h.stats.emitted.Add(1)
+	return nil
+}
+
+// rawEnvelope validates that raw is a JSON object, and reports whether it has top-level level
+// and timestamp fields.
+func rawEnvelope(raw []byte, names *FieldNames) (hasLevel, hasTime bool, err error) {
+	if len(raw) == 0 || raw[0] != '{' || !json.Valid(raw) {
+		return false, false, ErrInvalidRaw
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.Token() // Opening brace.
+	levelName, timeName := names.level(), names.time()
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return false, false, ErrInvalidRaw
+		}
+		switch key {
+		case levelName:
+			hasLevel = true
+		case timeName:
+			hasTime = true
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return false, false, ErrInvalidRaw
+		}
+	}
+	return hasLevel, hasTime, nil
+}
+
+// appendRawEnvelope appends the opening brace of a JSON object and the level and timestamp fields,
+// encoded by zerolog, to dst.
+func appendRawEnvelope(dst []byte, names *FieldNames, level slog.Level, withLevel, withTime bool) []byte {
+	var out bytes.Buffer
+	logger := zerolog.New(&out)
+	evt := logger.Log()
+	if withLevel {
+		evt = evt.Str(names.level(), zerolog.LevelFieldMarshalFunc(ZerologLevel(level)))
+	}
+	if withTime {
+		evt = evt.Time(names.time(), zerolog.TimestampFunc())
+	}
+	evt.Msg("")
+	// out holds the envelope as an object, followed by a newline.
+	return append(dst, bytes.TrimSuffix(bytes.TrimSpace(out.Bytes()), []byte("}"))...)
+}
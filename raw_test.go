@@ -0,0 +1,116 @@
+package zeroslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestHandler_HandleRaw(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts *HandlerOptions
+		lvl  slog.Level
+		raw  string
+		exp  map[string]any
+	}{
+		{"complete", nil, slog.LevelWarn, ` {"level":"error","time":"then","message":"hi","a":{"b":1}}` + "\n",
+			map[string]any{"level": "error", "time": "then", "message": "hi", "a": map[string]any{"b": 1.0}}},
+		{"missing-level", nil, slog.LevelWarn, `{"time":"then","message":"hi"}`,
+			map[string]any{"level": "warn", "time": "then", "message": "hi"}},
+		{"missing-time", nil, slog.LevelInfo, `{"level":"debug","nested":{"time":1}}`,
+			map[string]any{"level": "debug", "time": "2024-01-02T03:04:05Z", "nested": map[string]any{"time": 1.0}}},
+		{"empty", nil, slog.LevelError, `{}`,
+			map[string]any{"level": "error", "time": "2024-01-02T03:04:05Z"}},
+		{"omit-level", &HandlerOptions{OmitLevel: true}, slog.LevelInfo, `{"a":1}`,
+			map[string]any{"time": "2024-01-02T03:04:05Z", "a": 1.0}},
+		{"filtered", nil, slog.LevelDebug, `{"a":1}`, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+			defer func(f func() time.Time) { zerolog.TimestampFunc = f }(zerolog.TimestampFunc)
+			zerolog.TimestampFunc = func() time.Time { return now }
+
+			out := bytes.Buffer{}
+			hdl := NewJsonHandler(&out, tc.opts)
+			if err := hdl.HandleRaw(tc.lvl, []byte(tc.raw)); err != nil {
+				t.Fatal(err)
+			}
+			if tc.exp == nil {
+				if out.Len() > 0 || hdl.Stats().Emitted != 0 {
+					t.Errorf("Unexpected record %q", out.String())
+				}
+				return
+			}
+			line := out.Bytes()
+			if bytes.Count(line, []byte("\n")) != 1 || line[len(line)-1] != '\n' {
+				t.Errorf("Expected a single line, got %q", line)
+			}
+			var got map[string]any
+			if err := json.Unmarshal(line, &got); err != nil {
+				t.Fatalf("Invalid JSON %q: %s", line, err)
+			}
+			if !reflect.DeepEqual(got, tc.exp) {
+				t.Errorf("Expected %v, got %v", tc.exp, got)
+			}
+			if st := hdl.Stats(); st.Emitted != 1 {
+				t.Errorf("Expected 1 emitted record, got %d", st.Emitted)
+			}
+		})
+	}
+}
+
+func TestHandler_HandleRaw_Errors(t *testing.T) {
+	hdl := NewJsonHandler(io.Discard, nil)
+	for _, raw := range []string{``, `null`, `[1]`, `"a"`, `{"a":`, `{"a":1}{"b":2}`, `{"a":1} x`} {
+		if err := hdl.HandleRaw(slog.LevelInfo, []byte(raw)); !errors.Is(err, ErrInvalidRaw) {
+			t.Errorf("Expected ErrInvalidRaw for %q, got %v", raw, err)
+		}
+	}
+	if st := hdl.Stats(); st.Emitted != 0 {
+		t.Errorf("Expected no emitted record, got %d", st.Emitted)
+	}
+
+	if err := NewHandler(zerolog.New(io.Discard), nil).HandleRaw(slog.LevelInfo, []byte(`{}`)); !errors.Is(err, ErrNoOutput) {
+		t.Errorf("Expected ErrNoOutput, got %v", err)
+	}
+
+	strict := NewJsonHandler(io.Discard, &HandlerOptions{StrictEmission: true})
+	if err := strict.HandleRaw(slog.LevelDebug, []byte(`{}`)); !errors.Is(err, ErrNotEmitted) {
+		t.Errorf("Expected ErrNotEmitted, got %v", err)
+	}
+
+	failing := NewJsonHandler(errWriter{}, nil)
+	if err := failing.HandleRaw(slog.LevelInfo, []byte(`{}`)); err == nil {
+		t.Error("Expected a write error")
+	}
+	if st := failing.Stats(); st.Dropped != 1 || st.Emitted != 0 {
+		t.Errorf("Unexpected stats %+v", st)
+	}
+}
+
+func TestSharedEncodingHandler_HandleRaw(t *testing.T) {
+	var debug, warn bytes.Buffer
+	hdl := NewSharedEncodingHandler([]Destination{
+		{Writer: &debug, Leveler: slog.LevelDebug},
+		{Writer: &warn, Leveler: slog.LevelWarn},
+	}, nil)
+	for _, lvl := range []slog.Level{slog.LevelDebug, slog.LevelWarn} {
+		if err := hdl.HandleRaw(lvl, []byte(`{"level":"x","time":1}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := bytes.Count(debug.Bytes(), []byte("\n")); n != 2 {
+		t.Errorf("Expected 2 records at debug, got %d", n)
+	}
+	if n := bytes.Count(warn.Bytes(), []byte("\n")); n != 1 {
+		t.Errorf("Expected 1 record at warn, got %d", n)
+	}
+}
@@ -0,0 +1,130 @@
+package zeroslog
+
+import (
+	"log/slog"
+	"slices"
+)
+
+// cloneRecord returns a copy of rec which can safely be retained after Handle returns.
+//
+// Unlike slog.Record.Clone, it also copies the members of group values, so that a caller
+// reusing the slice given to slog.Group doesn't alter the copy. The copy is still shallow:
+// values of kind Any, such as pointers, maps or slices, share their underlying data with rec,
+// and LogValuers are not resolved.
+func cloneRecord(rec slog.Record) slog.Record {
+	clone := slog.NewRecord(rec.Time, rec.Level, rec.Message, rec.PC)
+	attrs := make([]slog.Attr, 0, rec.NumAttrs())
+	rec.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, cloneAttr(a))
+		return true
+	})
+	clone.AddAttrs(attrs...)
+	return clone
+}
+
+// cloneAttr returns a copy of a, recursively copying group members.
+func cloneAttr(a slog.Attr) slog.Attr {
+	if a.Value.Kind() != slog.KindGroup {
+		return a
+	}
+	members := slices.Clone(a.Value.Group())
+	for i, m := range members {
+		members[i] = cloneAttr(m)
+	}
+	return slog.Attr{Key: a.Key, Value: slog.GroupValue(members...)}
+}
+
+// recordEnvelope returns a record with the time, level, message and PC of rec, but no attributes.
+func recordEnvelope(rec slog.Record) slog.Record {
+	return slog.NewRecord(rec.Time, rec.Level, rec.Message, rec.PC)
+}
+
+// resolveRecord returns a copy of rec with all its attribute values resolved, including group members,
+// so that LogValuers are called only once when rec is processed several times.
+func resolveRecord(rec slog.Record) slog.Record {
+	resolved := slog.NewRecord(rec.Time, rec.Level, rec.Message, rec.PC)
+	resolved.AddAttrs(appendResolvedAttrs(make([]slog.Attr, 0, rec.NumAttrs()), &rec)...)
+	return resolved
+}
+
+// resolveAttrs returns attrs with their values resolved, including group members.
+// attrs is copied if any of them needs to be resolved.
+func resolveAttrs(attrs []slog.Attr) []slog.Attr {
+	if !needsResolve(attrs) {
+		return attrs
+	}
+	resolved := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		resolved[i] = resolveAttr(a)
+	}
+	return resolved
+}
+
+// appendResolvedAttrs appends the attributes of rec to attrs, with their values resolved.
+func appendResolvedAttrs(attrs []slog.Attr, rec *slog.Record) []slog.Attr {
+	rec.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, resolveAttr(a))
+		return true
+	})
+	return attrs
+}
+
+// resolveAttr returns a with its value resolved, recursively resolving group members.
+// Group members are only copied when some of them need to be resolved.
+func resolveAttr(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() != slog.KindGroup || !needsResolve(a.Value.Group()) {
+		return a
+	}
+	members := slices.Clone(a.Value.Group())
+	for i, m := range members {
+		members[i] = resolveAttr(m)
+	}
+	return slog.Attr{Key: a.Key, Value: slog.GroupValue(members...)}
+}
+
+// needsResolve reports whether any of attrs, or their members, is a LogValuer.
+func needsResolve(attrs []slog.Attr) bool {
+	for _, a := range attrs {
+		switch a.Value.Kind() {
+		case slog.KindLogValuer:
+			return true
+		case slog.KindGroup:
+			if needsResolve(a.Value.Group()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isEmptyAttr reports whether an attribute with key and the resolved value is empty, like the zero slog.Attr,
+// or a group without any non-empty attribute, and must be ignored.
+func isEmptyAttr(key string, value slog.Value) bool {
+	switch value.Kind() {
+	case slog.KindGroup:
+		return isEmptyGroup(value.Group())
+	case slog.KindAny:
+		return key == "" && value.Any() == nil
+	}
+	return false
+}
+
+// isEmptyGroup reports whether attrs, the attributes of a group, are all empty, so that the group is omitted.
+func isEmptyGroup(attrs []slog.Attr) bool {
+	for _, a := range attrs {
+		if !isEmptyAttr(a.Key, a.Value.Resolve()) {
+			return false
+		}
+	}
+	return true
+}
+
+// withoutEmpty returns resolved attributes without the empty ones, reusing attrs if there's none.
+func withoutEmpty(attrs []slog.Attr) []slog.Attr {
+	empty := func(a slog.Attr) bool { return isEmptyAttr(a.Key, a.Value) }
+	if !slices.ContainsFunc(attrs, empty) {
+		return attrs
+	}
+	return slices.DeleteFunc(slices.Clone(attrs), empty)
+}
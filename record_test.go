@@ -0,0 +1,64 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+// deferredHandler retains records, and only sends them to the wrapped handler on flush.
+type deferredHandler struct {
+	next    slog.Handler
+	records []slog.Record
+}
+
+func (h *deferredHandler) Handle(_ context.Context, rec slog.Record) error {
+	h.records = append(h.records, cloneRecord(rec))
+	return nil
+}
+
+func (h *deferredHandler) flush() {
+	for _, rec := range h.records {
+		h.next.Handle(context.Background(), rec)
+	}
+}
+
+func TestCloneRecord(t *testing.T) {
+	out := bytes.Buffer{}
+	hdl := &deferredHandler{next: NewJsonHandler(&out, nil)}
+
+	members := []slog.Attr{slog.String("a", "original")}
+	ptr := &struct{ Val string }{"original"}
+	attrs := []slog.Attr{slog.Int("1", 1), slog.Int("2", 2), slog.Int("3", 3), slog.Int("4", 4), slog.Int("5", 5), slog.Int("6", 6)}
+	rec := slog.NewRecord(now, slog.LevelInfo, "foobar", 0)
+	rec.AddAttrs(attrs...)
+	rec.AddAttrs(slog.Any("group", slog.GroupValue(members...)), slog.Any("ptr", ptr))
+	hdl.Handle(context.Background(), rec)
+
+	// Mutate everything the caller still has access to.
+	members[0] = slog.String("a", "mutated")
+	attrs[5] = slog.Int("6", 600)
+	rec.AddAttrs(slog.String("late", "attr"))
+	ptr.Val = "mutated"
+
+	hdl.flush()
+	m := map[string]any{}
+	if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+		t.Fatalf("Failed to json decode log output: %s", err.Error())
+	}
+	if m["group"].(map[string]any)["a"] != "original" {
+		t.Errorf("Group members are shared with the caller: %v", m["group"])
+	}
+	if m["6"] != 6.0 {
+		t.Errorf("Attributes are shared with the caller: %v", m["6"])
+	}
+	if _, found := m["late"]; found {
+		t.Errorf("Record is shared with the caller")
+	}
+	// The copy is documented to be shallow: pointed values are shared.
+	if m["ptr"].(map[string]any)["Val"] != "mutated" {
+		t.Errorf("Expected pointed values to be shared, got %v", m["ptr"])
+	}
+}
@@ -0,0 +1,195 @@
+package zeroslog
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"slices"
+
+	"github.com/rs/zerolog"
+)
+
+// MarshalError is reported to OnRecordError when the value of an attribute can't be marshaled.
+// The attribute is written with an error message as value, like without OnRecordError.
+type MarshalError struct {
+	// Key is the key of the attribute.
+	Key string
+	// Err is the marshaling error.
+	Err error
+}
+
+// Error implements error.
+func (e *MarshalError) Error() string {
+	return fmt.Sprintf("zeroslog: failed to marshal %q: %v", e.Key, e.Err)
+}
+
+// Unwrap returns the marshaling error.
+func (e *MarshalError) Unwrap() error {
+	return e.Err
+}
+
+// reporterKey is the context key of the recordReporter of the record being handled.
+type reporterKey struct{}
+
+// recordReporter reports the errors detected while writing a record to OnRecordError.
+type recordReporter struct {
+	h *Handler
+	// ctx is the context passed to Handle.
+	ctx context.Context
+	rec slog.Record
+	// groups are the names of the groups of the handler which handled the record.
+	groups []string
+}
+
+// recordReporter returns a recordReporter for rec, handled by a handler with the given groups, and a
+// copy of ctx carrying it. It returns a nil reporter and ctx unchanged if OnRecordError is not set.
+func (h *Handler) recordReporter(ctx context.Context, groups []string, rec *slog.Record) (*recordReporter, context.Context) {
+	if h.opts.OnRecordError == nil {
+		return nil, ctx
+	}
+	r := &recordReporter{h: h, ctx: ctx, rec: *rec, groups: groups}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return r, context.WithValue(ctx, reporterKey{}, r)
+}
+
+// reporterFromContext returns the recordReporter carried by ctx, or nil.
+func reporterFromContext(ctx context.Context) *recordReporter {
+	if ctx == nil {
+		return nil
+	}
+	r, _ := ctx.Value(reporterKey{}).(*recordReporter)
+	return r
+}
+
+// groupNames returns the names of the groups of h, outermost first.
+func (h *groupHandler) groupNames() []string {
+	var names []string
+	for g := h; g != nil; g, _ = g.parent.(*groupHandler) {
+		names = append(names, g.name)
+	}
+	slices.Reverse(names)
+	return names
+}
+
+// report counts err and passes it to OnRecordError, with the group path of the error.
+func (r *recordReporter) report(path []string, err error) {
+	r.h.stats.errors.Add(1)
+	r.h.opts.OnRecordError(r.ctx, path, r.rec, err)
+}
+
+// attrs checks that the values of attrs, written in the handler groups, can be marshaled.
+// Values which are marshaled are replaced in place by their JSON encoding, or their error message,
+// so that they are not marshaled again when written.
+func (r *recordReporter) attrs(attrs []slog.Attr) {
+	if r == nil {
+		return
+	}
+	for i, a := range attrs {
+		attrs[i] = r.attr(r.groups, a)
+	}
+}
+
+// attr checks that the value of a, written in the group whose path is path, can be marshaled.
+// See attrs.
+func (r *recordReporter) attr(path []string, a slog.Attr) slog.Attr {
+	if r == nil {
+		return a
+	}
+	value := a.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindGroup:
+		path = append(path[:len(path):len(path)], a.Key)
+		members := slices.Clone(value.Group())
+		for i, m := range members {
+			members[i] = r.attr(path, m)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(members...)}
+	case slog.KindAny:
+		v, err := marshalAny(value.Any(), r.h.limits)
+		if err != nil {
+			r.report(path, &MarshalError{Key: a.Key, Err: err})
+		}
+		return slog.Attr{Key: a.Key, Value: v}
+	default:
+		return a
+	}
+}
+
+// marshalAny marshals the values mapAttrAny marshals, and returns the value to write instead: their
+// JSON encoding, or the error message mapAttrAny writes when marshaling fails. Values which are
+// written by mapAttrAny without being marshaled, or whose marshaling failed with zerolog's
+// marshaling function, are returned unchanged.
+func marshalAny(value any, lim attrLimits) (slog.Value, error) {
+	switch v := value.(type) {
+	case []slog.Value, []any, map[string]any:
+		return marshalInterface(jsonValue(v, lim, 0), value)
+	case net.IP, net.IPNet, net.HardwareAddr, error, fmt.Stringer:
+		return slog.AnyValue(value), nil
+	case json.Marshaler:
+		txt, err := v.MarshalJSON()
+		if err != nil {
+			return slog.StringValue("!ERROR:" + err.Error()), err
+		}
+		return slog.AnyValue(json.RawMessage(txt)), nil
+	case encoding.TextMarshaler:
+		txt, err := v.MarshalText()
+		if err != nil {
+			return slog.StringValue("!ERROR:" + err.Error()), err
+		}
+		return slog.StringValue(string(txt)), nil
+	default:
+		if texts, ok := textSlice(value); ok {
+			return marshalInterface(texts, value)
+		}
+		return marshalInterface(value, value)
+	}
+}
+
+// marshalInterface marshals v with zerolog.InterfaceMarshalFunc, and returns its JSON encoding, or
+// orig if it fails.
+func marshalInterface(v, orig any) (slog.Value, error) {
+	data, err := zerolog.InterfaceMarshalFunc(v)
+	if err != nil {
+		return slog.AnyValue(orig), err
+	}
+	return slog.AnyValue(json.RawMessage(data)), nil
+}
+
+// reportWriter reports the write failures of a record to its recordReporter.
+type reportWriter struct {
+	out      io.Writer
+	reporter *recordReporter
+}
+
+var _ zerolog.LevelWriter = reportWriter{}
+
+// Write implements io.Writer.
+func (w reportWriter) Write(p []byte) (int, error) {
+	n, err := w.out.Write(p)
+	w.check(err)
+	return n, err
+}
+
+// WriteLevel implements zerolog.LevelWriter, so that a level writer output still receives the level.
+func (w reportWriter) WriteLevel(lvl zerolog.Level, p []byte) (int, error) {
+	lw, ok := w.out.(zerolog.LevelWriter)
+	if !ok {
+		return w.Write(p)
+	}
+	n, err := lw.WriteLevel(lvl, p)
+	w.check(err)
+	return n, err
+}
+
+// check reports err, if not nil.
+func (w reportWriter) check(err error) {
+	if err != nil {
+		w.reporter.report(w.reporter.groups, fmt.Errorf("zeroslog: failed to write record: %w", err))
+	}
+}
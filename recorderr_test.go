@@ -0,0 +1,90 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"testing"
+)
+
+type failingMarshaler struct{}
+
+func (failingMarshaler) MarshalJSON() ([]byte, error) { return nil, errors.New("marshal failure") }
+
+func TestOnRecordError_Marshal(t *testing.T) {
+	type report struct {
+		path []string
+		msg  string
+		lvl  slog.Level
+		key  string
+		ctx  any
+	}
+	for _, tc := range []struct {
+		name string
+		log  func(l *slog.Logger, ctx context.Context)
+		exp  []report
+	}{
+		{"root", func(l *slog.Logger, ctx context.Context) {
+			l.InfoContext(ctx, "root", "ok", map[string]any{"a": 1}, "bad", failingMarshaler{})
+		}, []report{{nil, "root", slog.LevelInfo, "bad", "v"}}},
+		{"two-groups", func(l *slog.Logger, ctx context.Context) {
+			l.WithGroup("a").With("x", 1).WithGroup("b").WarnContext(ctx, "deep", "bad", failingMarshaler{}, "ok", []int{1})
+		}, []report{{[]string{"a", "b"}, "deep", slog.LevelWarn, "bad", "v"}}},
+		{"record-groups", func(l *slog.Logger, ctx context.Context) {
+			l.WithGroup("a").ErrorContext(ctx, "nested", slog.Group("b", slog.Group("c", slog.Any("fn", func() {}))))
+		}, []report{{[]string{"a", "b", "c"}, "nested", slog.LevelError, "fn", "v"}}},
+		{"none", func(l *slog.Logger, ctx context.Context) {
+			l.WithGroup("a").InfoContext(ctx, "fine", "ip", []byte("x"), "err", errors.New("boom"))
+		}, nil},
+	} {
+		for _, strict := range []bool{false, true} {
+			t.Run(tc.name, func(t *testing.T) {
+				var reports []report
+				out := bytes.Buffer{}
+				logger := slog.New(NewJsonHandler(&out, &HandlerOptions{
+					StrictSlogCompliance: strict,
+					OnRecordError: func(ctx context.Context, groupPath []string, rec slog.Record, err error) {
+						var merr *MarshalError
+						if !errors.As(err, &merr) {
+							t.Fatalf("Unexpected error %v", err)
+						}
+						reports = append(reports, report{groupPath, rec.Message, rec.Level, merr.Key, ctx.Value(ctxKey{})})
+					},
+				}))
+				tc.log(logger, context.WithValue(context.Background(), ctxKey{}, "v"))
+				if !reflect.DeepEqual(reports, tc.exp) {
+					t.Errorf("Reported %+v, expected %+v", reports, tc.exp)
+				}
+
+				expected := bytes.Buffer{}
+				tc.log(slog.New(NewJsonHandler(&expected, &HandlerOptions{StrictSlogCompliance: strict})), context.Background())
+				if withoutTime(out.String()) != withoutTime(expected.String()) {
+					t.Errorf("Record changed: %s, expected %s", out.String(), expected.String())
+				}
+			})
+		}
+	}
+}
+
+func TestOnRecordError_Write(t *testing.T) {
+	var paths [][]string
+	hdl := NewJsonHandler(errWriter{}, &HandlerOptions{
+		OnRecordError: func(_ context.Context, groupPath []string, rec slog.Record, err error) {
+			if rec.Message != "hello" {
+				t.Errorf("Unexpected record %q", rec.Message)
+			}
+			paths = append(paths, groupPath)
+		},
+	})
+	logger := slog.New(hdl)
+	logger.Info("hello")
+	logger.WithGroup("a").WithGroup("b").Info("hello", "n", 1)
+	if exp := [][]string{nil, {"a", "b"}}; !reflect.DeepEqual(paths, exp) {
+		t.Errorf("Reported paths %v, expected %v", paths, exp)
+	}
+	if st := hdl.Stats(); st.Errors != 2 {
+		t.Errorf("Expected 2 errors, got %d", st.Errors)
+	}
+}
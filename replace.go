@@ -0,0 +1,74 @@
+package zeroslog
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// replaceStage returns the pipeline stage implementing ReplaceAttr. replace is called for every attribute
+// which is not a group, with the names of the groups holding it, split from the dot-joined group path.
+// Attributes replaced with an attribute having an empty key are dropped, and so are the groups left empty.
+func replaceStage(replace func(groups []string, a slog.Attr) slog.Attr) attrStage {
+	var apply func(groups []string, a slog.Attr) (slog.Attr, bool)
+	apply = func(groups []string, a slog.Attr) (slog.Attr, bool) {
+		if a.Value.Kind() != slog.KindGroup {
+			a = replace(groups, a)
+			a.Value = a.Value.Resolve()
+			return a, a.Key != ""
+		}
+		if a.Key != "" {
+			groups = append(groups[:len(groups):len(groups)], a.Key)
+		}
+		group := a.Value.Group()
+		members := make([]slog.Attr, 0, len(group))
+		for _, m := range group {
+			m.Value = m.Value.Resolve()
+			if m, ok := apply(groups, m); ok {
+				members = append(members, m)
+			}
+		}
+		if len(members) == 0 && len(group) > 0 {
+			return a, false
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(members...)}, true
+	}
+	return func(prefix string, a slog.Attr) (slog.Attr, bool) {
+		var groups []string
+		if prefix != "" {
+			groups = strings.Split(strings.TrimSuffix(prefix, "."), ".")
+		}
+		return apply(groups, a)
+	}
+}
+
+// keepBuiltin calls ReplaceAttr, which must be set, for a, a field written by the handler itself, with no groups
+// like slog handlers do. It reports whether a is unchanged and must be written as usual. Otherwise, the attribute
+// returned by ReplaceAttr is written with writeReplaced.
+func (h *Handler) keepBuiltin(evt *zerolog.Event, a slog.Attr) bool {
+	r := h.opts.ReplaceAttr(nil, a)
+	if isBuiltin(r, a.Key, a.Value.Any()) {
+		return true
+	}
+	h.writeReplaced(evt, r)
+	return false
+}
+
+// isBuiltin reports whether ReplaceAttr returned a, the field with the given name and value, unchanged.
+func isBuiltin(a slog.Attr, name string, value any) bool {
+	return a.Key == name && a.Value.Equal(slog.AnyValue(value))
+}
+
+// writeReplaced writes a field written by the handler itself, as replaced by ReplaceAttr, like record
+// attributes, unless it has an empty key. Sources are written according to SourceFormat.
+func (h *Handler) writeReplaced(evt *zerolog.Event, a slog.Attr) {
+	if a.Key == "" {
+		return
+	}
+	a.Value = a.Value.Resolve()
+	if src, ok := a.Value.Any().(*slog.Source); ok && src != nil {
+		a.Value = slog.AnyValue(sourceValue{src: src, format: h.opts.SourceFormat})
+	}
+	mapAttr(evt, h.limits, a)
+}
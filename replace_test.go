@@ -0,0 +1,104 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestReplaceAttr(t *testing.T) {
+	out := bytes.Buffer{}
+	var gotGroups [][]string
+	logger := slog.New(NewJsonHandler(&out, &HandlerOptions{ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+		gotGroups = append(gotGroups, groups)
+		if a.Key == "drop" {
+			return slog.Attr{}
+		}
+		return slog.Attr{Key: strings.ToUpper(a.Key), Value: a.Value}
+	}}))
+	logger.WithGroup("req").Info("msg", "id", 1, slog.Group("meta", "drop", true))
+	exp := `"req":{"ID":1}`
+	if !strings.Contains(out.String(), exp) {
+		t.Errorf("Expected %s in %s", exp, out.String())
+	}
+	// The level, time and message fields come last, without groups.
+	if len(gotGroups) != 5 || strings.Join(gotGroups[0], ".") != "req" || strings.Join(gotGroups[1], ".") != "req.meta" ||
+		gotGroups[2] != nil || gotGroups[3] != nil || gotGroups[4] != nil {
+		t.Errorf("Unexpected groups %q", gotGroups)
+	}
+}
+
+func TestReplaceAttr_Builtin(t *testing.T) {
+	out := bytes.Buffer{}
+	var levels []zerolog.Level
+	hdl := NewJsonHandler(&out, &HandlerOptions{
+		AddSource: true,
+		Hooks: []zerolog.Hook{zerolog.HookFunc(func(_ *zerolog.Event, lvl zerolog.Level, _ string) {
+			levels = append(levels, lvl)
+		})},
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			switch {
+			case groups != nil:
+			case a.Key == zerolog.MessageFieldName:
+				a.Key = "msg"
+			case a.Key == zerolog.TimestampFieldName:
+				return slog.Attr{}
+			case a.Key == zerolog.CallerFieldName:
+				src := a.Value.Any().(*slog.Source)
+				a.Value = slog.AnyValue(&slog.Source{File: filepath.Base(src.File), Line: src.Line})
+			case a.Key == zerolog.LevelFieldName && a.Value.Any().(slog.Level) == slog.LevelWarn:
+				a.Value = slog.StringValue("WARNING")
+			}
+			if a.Key == "password" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	logger := slog.New(hdl).With("password", "hunter2", "user", "bob")
+	logger.Info("hello")
+	logger.Warn("careful", "password", "hunter2")
+
+	dec := json.NewDecoder(&out)
+	for _, exp := range []map[string]any{
+		{"level": "info", "msg": "hello", "user": "bob"},
+		{"level": "WARNING", "msg": "careful", "user": "bob"},
+	} {
+		m := map[string]any{}
+		if err := dec.Decode(&m); err != nil {
+			t.Fatalf("Failed to json decode log output: %s", err.Error())
+		}
+		caller, _ := m[zerolog.CallerFieldName].(string)
+		delete(m, zerolog.CallerFieldName)
+		if !jsonEqual(m, exp) || !strings.HasPrefix(caller, "replace_test.go:") {
+			t.Errorf("Expected %v with a short caller, got %v and %q", exp, m, caller)
+		}
+	}
+	// Records keep their level unless the level field is replaced.
+	if len(levels) != 2 || levels[0] != zerolog.InfoLevel || levels[1] != zerolog.NoLevel {
+		t.Errorf("Unexpected hook levels %v", levels)
+	}
+}
+
+func TestReplaceAttr_Disabled(t *testing.T) {
+	out := bytes.Buffer{}
+	calls := 0
+	hdl := NewJsonHandler(&out, &HandlerOptions{ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+		calls++
+		return a
+	}})
+	// Records are handled directly, without the Enabled check of slog.Logger.
+	rec := slog.NewRecord(now, slog.LevelDebug, "hello", 0)
+	hdl.Handle(context.Background(), rec)
+	rec.AddAttrs(slog.String("foo", "bar"))
+	hdl.WithGroup("g").Handle(context.Background(), rec)
+	if calls != 0 || out.Len() != 0 {
+		t.Errorf("Expected ReplaceAttr not to be called for discarded records, got %d calls and %s", calls, out.String())
+	}
+}
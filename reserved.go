@@ -0,0 +1,62 @@
+package zeroslog
+
+import "log/slog"
+
+// ReservedKeyPolicy tells how a handler writes top-level attributes whose key collides with one of
+// the fields written by the handler itself: the level, time, message and caller fields.
+type ReservedKeyPolicy int
+
+const (
+	// ReservedKeyAllow writes colliding attributes as is, leading to duplicate keys in the output.
+	ReservedKeyAllow ReservedKeyPolicy = iota
+	// ReservedKeyRename prefixes the key of colliding attributes with ReservedKeyPrefix.
+	ReservedKeyRename
+	// ReservedKeyDrop drops colliding attributes. Dropped attributes are counted in Stats.
+	ReservedKeyDrop
+)
+
+// ReservedKeyPrefix is the prefix added to colliding attribute keys by ReservedKeyRename.
+const ReservedKeyPrefix = "field_"
+
+// zerologReservedKey returns whether a top-level key is the name of a field written by zerolog handlers
+// configured with opts. Names are read at each call, so that changes to zerolog's field names are taken into account.
+func zerologReservedKey(opts *HandlerOptions) func(key string) bool {
+	names := &opts.FieldNames
+	return func(key string) bool {
+		caller := names.caller()
+		switch key {
+		case names.time(), names.message():
+			return true
+		case names.level():
+			return !opts.OmitLevel
+		case OTELSeverityNumberKey, OTELSeverityTextKey:
+			return opts.OTELSeverity
+		case DeltaTimeKey:
+			return opts.AddDeltaTime
+		case caller:
+			return opts.AddSource && opts.SourceFormat != SourceFlatFields
+		case caller + sourceFileSuffix, caller + sourceLineSuffix, caller + sourceFuncSuffix:
+			return opts.AddSource && opts.SourceFormat == SourceFlatFields
+		default:
+			return false
+		}
+	}
+}
+
+// reservedKeyStage returns the pipeline stage applying policy to top-level attributes whose key is reserved.
+// Dropped attributes are counted in st.
+func reservedKeyStage(policy ReservedKeyPolicy, reserved func(key string) bool, st *stats) attrStage {
+	return func(prefix string, a slog.Attr) (slog.Attr, bool) {
+		if prefix != "" || !reserved(a.Key) {
+			return a, true
+		}
+		switch policy {
+		case ReservedKeyRename:
+			a.Key = ReservedKeyPrefix + a.Key
+		case ReservedKeyDrop:
+			countDropped(st)
+			return a, false
+		}
+		return a, true
+	}
+}
@@ -0,0 +1,96 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestReservedKeyPolicy(t *testing.T) {
+	colliding := []slog.Attr{
+		slog.String("level", "high"),
+		slog.Time("time", now),
+		slog.String("message", "user"),
+		slog.String("caller", "me"),
+	}
+	for name, tc := range map[string]struct {
+		policy  ReservedKeyPolicy
+		present []string
+		absent  []string
+		dropped uint64
+	}{
+		"allow": {
+			policy:  ReservedKeyAllow,
+			present: []string{`"level":"high"`, `"message":"user"`, `"caller":"me"`},
+			absent:  []string{ReservedKeyPrefix},
+		},
+		"rename": {
+			policy:  ReservedKeyRename,
+			present: []string{`"field_level":"high"`, `"field_time":`, `"field_message":"user"`, `"field_caller":"me"`, `"g":{"level":"nested"}`},
+			absent:  []string{`"level":"high"`, `"message":"user"`, `"caller":"me"`},
+		},
+		"drop": {
+			policy:  ReservedKeyDrop,
+			present: []string{`"g":{"level":"nested"}`},
+			absent:  []string{"high", "user", `"me"`, ReservedKeyPrefix},
+			dropped: 8,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			out := bytes.Buffer{}
+			hdl := NewJsonHandler(&out, &HandlerOptions{AddSource: true, ReservedKeyPolicy: tc.policy})
+			pc, _, _, _ := runtime.Caller(0)
+			rec := slog.NewRecord(now, slog.LevelInfo, "foobar", pc)
+			rec.AddAttrs(colliding...)
+			rec.AddAttrs(slog.Group("g", slog.String("level", "nested")))
+			hdl.WithAttrs(colliding).Handle(context.Background(), rec)
+
+			s := out.String()
+			for _, p := range tc.present {
+				if !strings.Contains(s, p) {
+					t.Errorf("Missing %s in output: %s", p, s)
+				}
+			}
+			for _, a := range tc.absent {
+				if strings.Contains(s, a) {
+					t.Errorf("Unexpected %s in output: %s", a, s)
+				}
+			}
+			for _, key := range []string{"level", "time", "message", "caller"} {
+				exp := 1
+				if tc.policy == ReservedKeyAllow {
+					exp = 3
+				}
+				if key == "level" {
+					exp++ // Nested in group g
+				}
+				if n := strings.Count(s, `"`+key+`":`); n != exp {
+					t.Errorf("Unexpected %d occurrences of %s in output, expected %d: %s", n, key, exp, s)
+				}
+			}
+			if st := hdl.Stats(); st.DroppedAttrs != tc.dropped {
+				t.Errorf("Unexpected dropped attrs count %d, expected %d", st.DroppedAttrs, tc.dropped)
+			}
+		})
+	}
+}
+
+func TestReservedKeyPolicy_FieldNames(t *testing.T) {
+	prev := zerolog.MessageFieldName
+	zerolog.MessageFieldName = "msg"
+	defer func() { zerolog.MessageFieldName = prev }()
+
+	out := bytes.Buffer{}
+	hdl := NewJsonHandler(&out, &HandlerOptions{ReservedKeyPolicy: ReservedKeyRename})
+	rec := slog.NewRecord(now, slog.LevelInfo, "foobar", 0)
+	rec.AddAttrs(slog.String("msg", "user"), slog.String("message", "kept"))
+	hdl.Handle(context.Background(), rec)
+	if s := out.String(); !strings.Contains(s, `"field_msg":"user"`) || !strings.Contains(s, `"message":"kept"`) {
+		t.Errorf("Unexpected output: %s", s)
+	}
+}
@@ -0,0 +1,205 @@
+package zeroslog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// backupTimeFormat is the format of the timestamps appended to the names of rotated files.
+// Timestamps are in UTC and sort like the times they represent.
+const backupTimeFormat = "20060102T150405.000000000"
+
+// RotatingFileHandler is a Handler writing records into rotated files.
+type RotatingFileHandler struct {
+	*Handler
+	writer *rotatingWriter
+}
+
+// NewRotatingFileHandler creates a handler appending records as JSON lines to the file path.
+//
+// The file is rotated before writing a record which would make it larger than maxBytes, or once it has been
+// written to for maxAge. Records are never split across files: a record larger than maxBytes is written alone.
+// Rotating closes the file and renames it by appending the time of the rotation, like
+// app.log.20240102T150405.000000000, then creates a new file at path. Only the newest maxBackups rotated files
+// are kept. A maxBytes, maxAge or maxBackups lower than 1 disables the corresponding limit.
+//
+// Rotation failures, like a failed rename or removal of old backups, are reported to opts.OnError, and records
+// are still written to whichever file is open, reopening path if needed.
+//
+// As with NewJsonHandler, records below zerolog.InfoLevel are discarded unless opts.Level is set.
+// Close must be called to sync and close the file.
+func NewRotatingFileHandler(path string, maxBytes int64, maxAge time.Duration, maxBackups int, opts *HandlerOptions) (*RotatingFileHandler, error) {
+	w := &rotatingWriter{path: path, maxBytes: maxBytes, maxAge: maxAge, maxBackups: maxBackups, now: time.Now, rename: os.Rename}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	h := NewHandler(zerolog.New(nil).Level(shortcutLevel(opts)), opts)
+	w.onError = h.reportError
+	h.setOutput(w)
+	return &RotatingFileHandler{Handler: h, writer: w}, nil
+}
+
+// Close syncs and closes the file. Records handled after Close are dropped.
+func (h *RotatingFileHandler) Close() error {
+	return errors.Join(h.Handler.Close(), h.writer.Close())
+}
+
+// rotatingWriter is an io.Writer appending records to a file, rotated by size and age.
+type rotatingWriter struct {
+	path       string
+	maxBytes   int64
+	maxAge     time.Duration
+	maxBackups int
+	// onError is called with rotation failures.
+	onError func(error)
+	// now and rename are replaced by tests.
+	now    func() time.Time
+	rename func(oldpath, newpath string) error
+
+	mu sync.Mutex
+	// file is nil if it couldn't be reopened after being closed for rotation.
+	file   *os.File
+	size   int64
+	opened time.Time
+	closed bool
+}
+
+// Write implements io.Writer. p is a single record.
+func (w *rotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, ErrClosed
+	}
+	if w.size > 0 && w.due(int64(len(p))) {
+		if err := w.rotate(); err != nil {
+			// The record is still written if a file is open, like when only pruning failed.
+			w.onError(err)
+		}
+	}
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// due reports whether the file must be rotated before writing n more bytes.
+func (w *rotatingWriter) due(n int64) bool {
+	if w.maxBytes > 0 && w.size+n > w.maxBytes {
+		return true
+	}
+	return w.maxAge > 0 && w.now().Sub(w.opened) >= w.maxAge
+}
+
+// open opens the file at path for appending, creating it if needed.
+func (w *rotatingWriter) open() error {
+	f, err := os.OpenFile(w.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file = f
+	w.size = info.Size()
+	w.opened = w.now()
+	return nil
+}
+
+// rotate closes and renames the file, opens a new one, and prunes old backups.
+// On failure, w.file is left nil if no file could be opened.
+func (w *rotatingWriter) rotate() error {
+	err := errors.Join(w.file.Sync(), w.file.Close())
+	w.file = nil
+	if err != nil {
+		return errors.Join(err, w.open())
+	}
+	backup, err := w.backupName(w.now())
+	if err == nil {
+		err = w.rename(w.path, backup)
+	}
+	if err != nil {
+		// Keep writing to the current file rather than losing records.
+		return errors.Join(err, w.open())
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+	return w.prune()
+}
+
+// backupName returns the name of a file rotated at t, moved forward if a backup already has this name.
+func (w *rotatingWriter) backupName(t time.Time) (string, error) {
+	for ; ; t = t.Add(time.Nanosecond) {
+		name := w.path + "." + t.UTC().Format(backupTimeFormat)
+		if _, err := os.Lstat(name); errors.Is(err, os.ErrNotExist) {
+			return name, nil
+		} else if err != nil {
+			return "", err
+		}
+	}
+}
+
+// prune removes the oldest backups, keeping maxBackups of them.
+func (w *rotatingWriter) prune() error {
+	if w.maxBackups < 1 {
+		return nil
+	}
+	backups, err := w.backups()
+	if err != nil || len(backups) <= w.maxBackups {
+		return err
+	}
+	var errs []error
+	for _, name := range backups[:len(backups)-w.maxBackups] {
+		errs = append(errs, os.Remove(name))
+	}
+	return errors.Join(errs...)
+}
+
+// backups returns the names of the rotated files, from the oldest to the newest.
+func (w *rotatingWriter) backups() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Dir(w.path))
+	if err != nil {
+		return nil, err
+	}
+	prefix := filepath.Base(w.path) + "."
+	var backups []string
+	for _, e := range entries {
+		stamp, ok := strings.CutPrefix(e.Name(), prefix)
+		if !ok || e.IsDir() {
+			continue
+		}
+		if _, err := time.Parse(backupTimeFormat, stamp); err == nil {
+			backups = append(backups, filepath.Join(filepath.Dir(w.path), e.Name()))
+		}
+	}
+	slices.Sort(backups)
+	return backups, nil
+}
+
+// Close syncs and closes the file.
+func (w *rotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if w.file == nil {
+		return nil
+	}
+	return errors.Join(w.file.Sync(), w.file.Close())
+}
@@ -0,0 +1,196 @@
+package zeroslog
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// setRotationClock makes h read the time from the returned pointer, as if its file was opened at this time.
+func setRotationClock(h *RotatingFileHandler) *time.Time {
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	h.writer.now = func() time.Time { return now }
+	h.writer.opened = now
+	return &now
+}
+
+// rotatedFiles returns the lines of path and its backups by file name, checking that each one is a JSON object.
+func rotatedFiles(t *testing.T, path string) map[string][]string {
+	t.Helper()
+	names, err := filepath.Glob(path + "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string][]string, len(names))
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var lines []string
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if !json.Valid(scanner.Bytes()) {
+				t.Errorf("Invalid record in %s: %q", name, scanner.Text())
+			}
+			lines = append(lines, scanner.Text())
+		}
+		f.Close()
+		files[filepath.Base(name)] = lines
+	}
+	return files
+}
+
+func TestRotatingFileHandler_Size(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	h, err := NewRotatingFileHandler(path, 300, 0, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := setRotationClock(h)
+	l := slog.New(h)
+	for i := 0; i < 10; i++ {
+		l.Info("hello", "i", i, "padding", strings.Repeat("x", 50))
+		*now = now.Add(time.Second)
+	}
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	files := rotatedFiles(t, path)
+	total := 0
+	for name, lines := range files {
+		total += len(lines)
+		if size := len(strings.Join(lines, "\n")) + 1; size > 300 {
+			t.Errorf("Expected %s to be at most 300 bytes, got %d", name, size)
+		}
+	}
+	if total != 10 || len(files) < 3 {
+		t.Errorf("Expected 10 records in several files, got %v", files)
+	}
+}
+
+func TestRotatingFileHandler_Age(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	h, err := NewRotatingFileHandler(path, 0, time.Hour, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := setRotationClock(h)
+	l := slog.New(h)
+	l.Info("first")
+	*now = now.Add(59 * time.Minute)
+	l.Info("second")
+	*now = now.Add(time.Minute)
+	l.Info("third")
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	files := rotatedFiles(t, path)
+	backup := "app.log.20240102T160405.000000000"
+	if len(files) != 2 || len(files[backup]) != 2 || len(files["app.log"]) != 1 {
+		t.Errorf("Expected 2 records in %s and 1 in app.log, got %v", backup, files)
+	}
+}
+
+func TestRotatingFileHandler_Backups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path+".unrelated", nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	h, err := NewRotatingFileHandler(path, 1, 0, 2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := setRotationClock(h)
+	l := slog.New(h)
+	for i := 0; i < 5; i++ {
+		l.Info("hello", "i", i)
+		*now = now.Add(time.Second)
+	}
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	files := rotatedFiles(t, path)
+	if len(files) != 4 {
+		t.Fatalf("Expected app.log, 2 backups and the unrelated file, got %v", files)
+	}
+	for name, want := range map[string]string{
+		"app.log.20240102T150408.000000000": `"i":2`,
+		"app.log.20240102T150409.000000000": `"i":3`,
+		"app.log":                           `"i":4`,
+	} {
+		if lines := files[name]; len(lines) != 1 || !strings.Contains(lines[0], want) {
+			t.Errorf("Expected %s to hold %s, got %q", name, want, lines)
+		}
+	}
+}
+
+func TestRotatingFileHandler_RenameFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	var reported []error
+	h, err := NewRotatingFileHandler(path, 1, 0, 0, &HandlerOptions{OnError: func(err error) { reported = append(reported, err) }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := setRotationClock(h)
+	errRename := errors.New("rename failed")
+	h.writer.rename = func(string, string) error { return errRename }
+	l := slog.New(h)
+	l.Info("first")
+	*now = now.Add(time.Second)
+	l.Info("second")
+	h.writer.rename = os.Rename
+	*now = now.Add(time.Second)
+	l.Info("third")
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(reported) != 1 || !errors.Is(reported[0], errRename) {
+		t.Errorf("Expected the rename failure to be reported once, got %v", reported)
+	}
+	if n := h.Stats().Errors; n != 1 {
+		t.Errorf("Expected 1 error in Stats, got %d", n)
+	}
+	files := rotatedFiles(t, path)
+	backup := "app.log.20240102T150407.000000000"
+	if len(files) != 2 || len(files[backup]) != 2 || len(files["app.log"]) != 1 {
+		t.Errorf("Expected the first 2 records in %s and the third in app.log, got %v", backup, files)
+	}
+}
+
+func TestRotatingFileHandler_Concurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	h, err := NewRotatingFileHandler(path, 4096, 0, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := slog.New(h)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				l.Info("hello", "goroutine", g, "i", i)
+			}
+		}(g)
+	}
+	wg.Wait()
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	total := 0
+	for _, lines := range rotatedFiles(t, path) {
+		total += len(lines)
+	}
+	if total != 800 {
+		t.Errorf("Expected 800 records, got %d", total)
+	}
+}
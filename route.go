@@ -0,0 +1,223 @@
+package zeroslog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+// ErrUnknownRoute is reported when a record is routed with Route to a destination name
+// which is not the Name of any destination of the handler.
+var ErrUnknownRoute = errors.New("zeroslog: unknown route")
+
+// routeMarker is the value of the attribute returned by Route.
+type routeMarker []string
+
+// routeAttrKey is the key of the attribute returned by Route.
+const routeAttrKey = "zeroslog.route"
+
+// routesKey is the context key of the destination names the record being handled is routed to.
+type routesKey struct{}
+
+// Route returns a marker attribute making handlers created with NewSharedEncodingHandler or NewFanoutHandler
+// write the record it's added to into the destinations with the given names, whatever their level, in addition
+// to the destinations admitting the record level, like to send a record to the audit stream:
+//
+//	logger.Info("role granted", "user", id, zeroslog.Route("audit"))
+//
+// The marker is never written. Names which are not the Name of any destination are counted in Stats and
+// reported to OnError as ErrUnknownRoute. Other handlers only remove the marker. Records must still be enabled
+// by the handler, whose level is the lowest of the destinations.
+func Route(names ...string) slog.Attr {
+	useMarkers()
+	return slog.Any(routeAttrKey, routeMarker(names))
+}
+
+// routeNames returns the destination names of a if it's a marker returned by Route.
+func routeNames(a slog.Attr) ([]string, bool) {
+	if a.Value.Kind() != slog.KindAny {
+		return nil, false
+	}
+	names, ok := a.Value.Any().(routeMarker)
+	return names, ok
+}
+
+// isRoute reports whether a is a marker returned by Route.
+func isRoute(a slog.Attr) bool {
+	_, ok := routeNames(a)
+	return ok
+}
+
+// markersUsed is set by the first call to Pretty or Route. Markers can't be created otherwise, so that records
+// aren't searched for them until then.
+var markersUsed atomic.Bool
+
+// useMarkers sets markersUsed, without writing it again once set.
+func useMarkers() {
+	if !markersUsed.Load() {
+		markersUsed.Store(true)
+	}
+}
+
+// isMarker reports whether a is a marker returned by Pretty or Route.
+func isMarker(a slog.Attr) bool {
+	return isPretty(a) || isRoute(a)
+}
+
+// isOnlyMarkers reports whether all the attributes of rec are markers returned by Pretty or Route.
+func isOnlyMarkers(rec *slog.Record) bool {
+	if !markersUsed.Load() {
+		return false
+	}
+	only := true
+	rec.Attrs(func(a slog.Attr) bool {
+		only = isMarker(a)
+		return only
+	})
+	return rec.NumAttrs() > 0 && only
+}
+
+// markers collects the markers returned by Pretty and Route held by a record.
+type markers struct {
+	pretty bool
+	// routes are the destination names of the Route markers, nil if there's none.
+	routes []string
+}
+
+// add records a if it's a marker, and reports whether it is.
+func (m *markers) add(a slog.Attr) bool {
+	if names, ok := routeNames(a); ok {
+		m.routes = append(m.routes, names...)
+		return true
+	}
+	if isPretty(a) {
+		m.pretty = true
+		return true
+	}
+	return false
+}
+
+// found reports whether some markers were collected.
+func (m markers) found() bool {
+	return m.pretty || m.routes != nil
+}
+
+// context returns a copy of ctx flagging the record being handled as pretty, and carrying the destination
+// names it's routed to, according to the collected markers, or ctx itself without marker.
+func (m markers) context(ctx context.Context) context.Context {
+	if m.pretty {
+		ctx = withPretty(ctx)
+	}
+	if m.routes != nil {
+		ctx = withRoutes(ctx, m.routes)
+	}
+	return ctx
+}
+
+// recordMarkers collects the markers held by rec, in a single walk of its attributes, skipped until
+// markers are created.
+func recordMarkers(rec *slog.Record) markers {
+	var m markers
+	if markersUsed.Load() {
+		rec.Attrs(func(a slog.Attr) bool {
+			m.add(a)
+			return true
+		})
+	}
+	return m
+}
+
+// removeMarkers collects the markers held by attrs, and removes them from attrs.
+func removeMarkers(attrs []slog.Attr) ([]slog.Attr, markers) {
+	var m markers
+	if !markersUsed.Load() {
+		return attrs, m
+	}
+	return slices.DeleteFunc(attrs, m.add), m
+}
+
+// withRoutes returns a copy of ctx carrying the destination names the record being handled is routed to.
+func withRoutes(ctx context.Context, names []string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, routesKey{}, names)
+}
+
+// routesFromContext returns the destination names carried by ctx, or nil.
+func routesFromContext(ctx context.Context) []string {
+	if ctx == nil {
+		return nil
+	}
+	names, _ := ctx.Value(routesKey{}).([]string)
+	return names
+}
+
+// fanoutRoutes are the named destinations of a handler created with NewSharedEncodingHandler.
+type fanoutRoutes struct {
+	destinations fanoutWriter
+	// known are the names of all the destinations, including the ones of other handlers created
+	// by NewFanoutHandler, and report is true for the handler reporting unknown names.
+	known  map[string]bool
+	report bool
+}
+
+// routedWriter returns the writer writing records to out, then to the destinations named by names which
+// don't admit the record level, reporting the unknown names to h.
+func (r *fanoutRoutes) routedWriter(h *Handler, out io.Writer, names []string) io.Writer {
+	if r.report {
+		for _, name := range names {
+			if !r.known[name] {
+				h.reportError(fmt.Errorf("%w: %q", ErrUnknownRoute, name))
+			}
+		}
+	}
+	var forced fanoutWriter
+	for _, d := range r.destinations {
+		if d.name != "" && slices.Contains(names, d.name) {
+			forced = append(forced, d)
+		}
+	}
+	if len(forced) == 0 {
+		return out
+	}
+	lw, ok := out.(zerolog.LevelWriter)
+	if !ok {
+		lw = zerolog.LevelWriterAdapter{Writer: out}
+	}
+	return routeWriter{out: lw, forced: forced}
+}
+
+// routeWriter writes records to out, then to the forced destinations which don't admit their level.
+type routeWriter struct {
+	out    zerolog.LevelWriter
+	forced fanoutWriter
+}
+
+var _ zerolog.LevelWriter = routeWriter{}
+
+// Write implements io.Writer.
+func (w routeWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel implements zerolog.LevelWriter.
+func (w routeWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	_, err := w.out.WriteLevel(level, p)
+	errs := []error{err}
+	for _, d := range w.forced {
+		if level == zerolog.NoLevel || SlogLevel(level) >= d.level.Level() {
+			continue // Already written by out.
+		}
+		if _, err := d.out.WriteLevel(level, p); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return len(p), errors.Join(errs...)
+}
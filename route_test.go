@@ -0,0 +1,92 @@
+package zeroslog
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRoute(t *testing.T) {
+	for _, fanout := range []bool{false, true} {
+		app, audit, redacted := bytes.Buffer{}, bytes.Buffer{}, bytes.Buffer{}
+		var errs []error
+		opts := &HandlerOptions{OnError: func(err error) { errs = append(errs, err) }}
+		destinations := []Destination{
+			{Writer: &app, Leveler: slog.LevelInfo},
+			{Name: "audit", Writer: &audit, Leveler: slog.LevelError},
+		}
+		var hdl slog.Handler
+		if fanout {
+			destinations = append(destinations, Destination{Name: "audit", Writer: &redacted, Leveler: slog.LevelError, ReplaceAttr: redact})
+			hdl = NewFanoutHandler(destinations, opts)
+		} else {
+			hdl = NewSharedEncodingHandler(destinations, opts)
+		}
+		logger := slog.New(hdl)
+
+		logger.Info("plain")
+		logger.Info("granted", "password", "hunter2", Route("audit"))
+		logger.WithGroup("req").Info("routed", Route("audit"))
+		logger.Error("failed", Route("audit"))
+		logger.Info("lost", Route("nowhere"))
+
+		if got := strings.Count(app.String(), "\n"); got != 5 || strings.Contains(app.String(), "zeroslog.route") {
+			t.Errorf("Fanout %t: expected all the records without marker in app, got %s", fanout, app.String())
+		}
+		exp := []string{"granted", "routed", "failed"}
+		lines := strings.Split(strings.TrimSpace(audit.String()), "\n")
+		if len(lines) != len(exp) {
+			t.Fatalf("Fanout %t: expected %d records in audit, got %s", fanout, len(exp), audit.String())
+		}
+		for i, msg := range exp {
+			if !strings.Contains(lines[i], `"message":"`+msg+`"`) || strings.Contains(lines[i], "zeroslog.route") {
+				t.Errorf("Fanout %t: expected record %q without marker, got %s", fanout, msg, lines[i])
+			}
+		}
+		if fanout && (strings.Count(redacted.String(), "\n") != 3 || strings.Contains(redacted.String(), "hunter2")) {
+			t.Errorf("Expected redacted routed records, got %s", redacted.String())
+		}
+		if len(errs) != 1 || !errors.Is(errs[0], ErrUnknownRoute) || !strings.Contains(errs[0].Error(), "nowhere") {
+			t.Errorf("Fanout %t: expected the unknown route to be reported once, got %v", fanout, errs)
+		}
+	}
+}
+
+func TestRoute_OtherHandlers(t *testing.T) {
+	out := bytes.Buffer{}
+	slog.New(NewJsonHandler(&out, nil)).WithGroup("g").Info("msg", Route("audit"))
+	if got := out.String(); strings.Contains(got, "zeroslog.route") || strings.Contains(got, `"g"`) {
+		t.Errorf("Expected the marker to be removed, got %s", got)
+	}
+}
+
+func TestMarkers(t *testing.T) {
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+	rec.AddAttrs(slog.Int("a", 1), Route("audit"), Pretty(), slog.Int("b", 2), Route("x", "y"))
+	m := recordMarkers(&rec)
+	if !m.pretty || !slices.Equal(m.routes, []string{"audit", "x", "y"}) {
+		t.Fatalf("Unexpected record markers %+v", m)
+	}
+	attrs, m := removeMarkers(appendResolvedAttrs(nil, &rec))
+	if !m.pretty || !slices.Equal(m.routes, []string{"audit", "x", "y"}) || len(attrs) != 2 || attrs[0].Key != "a" || attrs[1].Key != "b" {
+		t.Fatalf("Unexpected markers %+v removed from %v", m, attrs)
+	}
+
+	used := markersUsed.Load()
+	defer markersUsed.Store(used)
+	markersUsed.Store(false)
+	if m := recordMarkers(&rec); m.found() || isOnlyMarkers(&rec) {
+		t.Fatal("Expected records not to be searched for markers before Pretty or Route is called")
+	}
+	if attrs, m := removeMarkers(appendResolvedAttrs(nil, &rec)); m.found() || len(attrs) != 5 {
+		t.Fatal("Expected attributes not to be searched for markers before Pretty or Route is called")
+	}
+	Route("audit")
+	if !markersUsed.Load() || !recordMarkers(&rec).found() {
+		t.Fatal("Expected records to be searched for markers once Route is called")
+	}
+}
@@ -0,0 +1,92 @@
+package zeroslog
+
+import (
+	"log/slog"
+	"math"
+)
+
+// SampledOutValue is written instead of the values of the attributes sampled out by SampleAttrs.
+const SampledOutValue = "<sampled out>"
+
+// SampleAttrs configures the sampling of attribute values, for bulky attributes only needed on some records.
+type SampleAttrs struct {
+	// Keys are the keys or patterns, like AllowKeys, of the attributes to sample. A group whose path
+	// matches is sampled as a whole.
+	Keys []string
+	// Rate is the fraction of records, between 0 and 1, keeping the values of the sampled attributes.
+	Rate float64
+}
+
+// attrSampler implements SampleAttrs.
+type attrSampler struct {
+	keys keyMatcher
+	// threshold is the highest record hash keeping the values.
+	threshold uint64
+}
+
+// newAttrSampler returns the sampler implementing s, or nil if no value is ever sampled out.
+func newAttrSampler(s SampleAttrs) *attrSampler {
+	keys := newKeyMatcher(s.Keys)
+	if keys == nil || s.Rate >= 1 {
+		return nil
+	}
+	sampler := &attrSampler{keys: keys}
+	if s.Rate > 0 {
+		sampler.threshold = uint64(s.Rate * math.MaxUint64)
+	}
+	return sampler
+}
+
+// keep reports whether the sampled attributes of rec keep their values. The decision only depends on the time,
+// level and message of rec, so that handlers sampling the same record at the same rate agree.
+// It is safe to call on a nil sampler.
+func (s *attrSampler) keep(rec *slog.Record) bool {
+	if s == nil {
+		return true
+	}
+	hash := fingerprintOffset.uint64(uint64(rec.Time.UnixNano())).uint64(uint64(int64(rec.Level))).string(rec.Message)
+	// FNV-1a spreads poorly over the high bits, so the hash is mixed before being compared.
+	return s.threshold > 0 && mix64(uint64(hash)) <= s.threshold
+}
+
+// sample replaces the values of the attributes of attrs matching the sampled keys with SampledOutValue,
+// unless rec keeps them. prefix is the dot-joined group path of attrs.
+// It is safe to call on a nil sampler.
+func (s *attrSampler) sample(prefix string, rec *slog.Record, attrs []slog.Attr) {
+	if s.keep(rec) {
+		return
+	}
+	for i, a := range attrs {
+		attrs[i] = s.sampleAttr(prefix, a)
+	}
+}
+
+// sampleAttr returns a, with the values of itself or its members matching the sampled keys replaced.
+func (s *attrSampler) sampleAttr(prefix string, a slog.Attr) slog.Attr {
+	key := prefix + a.Key
+	if a.Key != "" && s.keys.match(key) {
+		return slog.String(a.Key, SampledOutValue)
+	}
+	if a.Value.Kind() != slog.KindGroup {
+		return a
+	}
+	if a.Key != "" {
+		prefix = key + "."
+	}
+	group := a.Value.Group()
+	members := make([]slog.Attr, len(group))
+	for i, m := range group {
+		members[i] = s.sampleAttr(prefix, m)
+	}
+	return slog.Attr{Key: a.Key, Value: slog.GroupValue(members...)}
+}
+
+// mix64 is the finalizer of SplitMix64, spreading the bits of x.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
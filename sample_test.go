@@ -0,0 +1,74 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestSampleAttrs(t *testing.T) {
+	out := bytes.Buffer{}
+	other := bytes.Buffer{}
+	opts := &HandlerOptions{SampleAttrs: SampleAttrs{Keys: []string{"sql", "req.body"}, Rate: 0.25}}
+	hdl := NewJsonHandler(&out, opts)
+	mirror := NewJsonHandler(&other, opts)
+
+	const n = 2000
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		rec := slog.NewRecord(start.Add(time.Duration(i)*time.Millisecond), slog.LevelInfo, "query", 0)
+		rec.AddAttrs(slog.String("sql", "SELECT 1"), slog.Int("rows", i), slog.Group("req", slog.String("body", "{}"), slog.String("id", "a")))
+		if err := hdl.Handle(context.Background(), rec); err != nil {
+			t.Fatal(err)
+		}
+		if err := mirror.Handle(context.Background(), rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dec := json.NewDecoder(&out)
+	mirrorDec := json.NewDecoder(&other)
+	kept := 0
+	for i := 0; i < n; i++ {
+		m := map[string]any{}
+		if err := dec.Decode(&m); err != nil {
+			t.Fatalf("Failed to json decode log output: %s", err.Error())
+		}
+		mm := map[string]any{}
+		if err := mirrorDec.Decode(&mm); err != nil {
+			t.Fatalf("Failed to json decode log output: %s", err.Error())
+		}
+		req := m["req"].(map[string]any)
+		if m["rows"] != float64(i) || req["id"] != "a" {
+			t.Fatalf("Expected attributes not listed to be kept, got %v", m)
+		}
+		switch {
+		case m["sql"] == "SELECT 1" && req["body"] == "{}":
+			kept++
+		case m["sql"] != SampledOutValue || req["body"] != SampledOutValue:
+			t.Fatalf("Expected sampled attributes to be kept or sampled out together, got %v", m)
+		}
+		if mm["sql"] != m["sql"] {
+			t.Fatalf("Expected handlers sampling the same record to agree, got %v and %v", m, mm)
+		}
+	}
+	if kept < n/5 || kept > n*3/10 {
+		t.Errorf("Expected about %d records to keep sampled attributes, got %d", n/4, kept)
+	}
+}
+
+func TestSampleAttrs_Rates(t *testing.T) {
+	rec := slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0)
+	if s := newAttrSampler(SampleAttrs{Keys: []string{"sql"}, Rate: 1}); s != nil || !s.keep(&rec) {
+		t.Errorf("Expected a rate of 1 to keep all the values")
+	}
+	if s := newAttrSampler(SampleAttrs{Rate: 0}); s != nil {
+		t.Errorf("Expected no sampler without keys")
+	}
+	if s := newAttrSampler(SampleAttrs{Keys: []string{"sql"}, Rate: 0}); s.keep(&rec) {
+		t.Errorf("Expected a rate of 0 to sample all the values out")
+	}
+}
@@ -0,0 +1,56 @@
+package zeroslog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrNotJSON is returned by ScanRecords for output which is not made of JSON objects, like the one of a
+// handler writing to a zerolog.ConsoleWriter.
+var ErrNotJSON = errors.New("zeroslog: record is not a JSON object, the output may be in console format")
+
+// ScanRecords returns a function calling yield with each JSON record read from r, in order, until r is
+// exhausted or yield returns false. It reads the output of the JSON handlers of this package, like in
+//
+//	ScanRecords(&buf)(func(rec map[string]any, err error) bool {
+//		...
+//		return true
+//	})
+//
+// The returned function has the type of an iter.Seq2, so it can be ranged over with versions of Go supporting
+// range-over-func iterators.
+//
+// Records are framed by their JSON syntax rather than by lines, so that multi-line records, like the ones written
+// by HandleRaw with indented JSON, are read as a whole. Values are decoded like with json.Unmarshal into a map[string]any.
+//
+// Reading stops at the first error, which is passed to yield with a nil record: io.ErrUnexpectedEOF when r ends
+// in the middle of a record, ErrNotJSON when a record is not a JSON object, or the error of r. Errors are wrapped with
+// the offset of the record in r.
+func ScanRecords(r io.Reader) func(yield func(map[string]any, error) bool) {
+	return func(yield func(map[string]any, error) bool) {
+		dec := json.NewDecoder(r)
+		for {
+			offset := dec.InputOffset()
+			var value any
+			err := dec.Decode(&value)
+			if err == io.EOF {
+				return
+			}
+			rec, ok := value.(map[string]any)
+			var syntaxErr *json.SyntaxError
+			switch {
+			case errors.As(err, &syntaxErr), err == nil && !ok:
+				err = ErrNotJSON
+			case err == nil:
+				if !yield(rec, nil) {
+					return
+				}
+				continue
+			}
+			yield(nil, fmt.Errorf("zeroslog: record at offset %d: %w", offset, err))
+			return
+		}
+	}
+}
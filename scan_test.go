@@ -0,0 +1,88 @@
+package zeroslog
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// scanAll returns the records and the error read by ScanRecords from s.
+func scanAll(s string) ([]map[string]any, error) {
+	var recs []map[string]any
+	var scanErr error
+	ScanRecords(strings.NewReader(s))(func(rec map[string]any, err error) bool {
+		if err != nil {
+			scanErr = err
+			return false
+		}
+		recs = append(recs, rec)
+		return true
+	})
+	return recs, scanErr
+}
+
+func TestScanRecords(t *testing.T) {
+	out := bytes.Buffer{}
+	hdl := NewJsonHandler(&out, nil)
+	if err := hdl.HandleRaw(slog.LevelInfo, []byte("{\n  \"message\": \"first\",\n  \"raw\": {\"a\": 1}\n}")); err != nil {
+		t.Fatal(err)
+	}
+	slog.New(hdl).Info("second\nline")
+
+	recs, err := scanAll(out.String())
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if len(recs) != 2 || recs[0]["message"] != "first" || recs[1]["message"] != "second\nline" {
+		t.Fatalf("Unexpected records %v", recs)
+	}
+	if !jsonEqual(recs[0]["raw"], map[string]any{"a": 1.0}) {
+		t.Errorf("Unexpected raw value %v", recs[0]["raw"])
+	}
+}
+
+func TestScanRecords_Stop(t *testing.T) {
+	n := 0
+	ScanRecords(strings.NewReader("{\"a\":1}\n{\"a\":2}\n"))(func(map[string]any, error) bool {
+		n++
+		return false
+	})
+	if n != 1 {
+		t.Errorf("Expected scanning to stop after the first record, got %d calls", n)
+	}
+}
+
+func TestScanRecords_Errors(t *testing.T) {
+	console := bytes.Buffer{}
+	slog.New(NewHandler(zerolog.New(zerolog.ConsoleWriter{Out: &console, NoColor: true}), nil)).Info("msg")
+
+	tests := []struct {
+		name  string
+		input string
+		recs  int
+		err   error
+	}{
+		{"Empty", "", 0, nil},
+		{"TrailingNewlines", "{\"a\":1}\n\n", 1, nil},
+		{"Partial", "{\"a\":1}\n{\"a\":", 1, io.ErrUnexpectedEOF},
+		{"Console", console.String(), 0, ErrNotJSON},
+		{"Colored", "\x1b[90m<nil>\x1b[0m INF msg\n", 0, ErrNotJSON},
+		{"NotObject", "{\"a\":1}\n[1]\n", 1, ErrNotJSON},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recs, err := scanAll(test.input)
+			if len(recs) != test.recs {
+				t.Errorf("Expected %d records, got %v", test.recs, recs)
+			}
+			if !errors.Is(err, test.err) || (err == nil) != (test.err == nil) {
+				t.Errorf("Expected error %v, got %v", test.err, err)
+			}
+		})
+	}
+}
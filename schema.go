@@ -0,0 +1,298 @@
+package zeroslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// SchemaMessage is the message of the record written with EmitSchemaOnStart.
+	SchemaMessage = "zeroslog schema"
+	// SchemaKey is the key of the object holding the schema in the record written with EmitSchemaOnStart.
+	SchemaKey = "schema"
+)
+
+// JSON types reported in schemas.
+const (
+	schemaString  = "string"
+	schemaNumber  = "number"
+	schemaBoolean = "boolean"
+	schemaObject  = "object"
+	schemaArray   = "array"
+	schemaNull    = "null"
+)
+
+// Schema returns the fields known to be written in every record by the handler, mapped to their JSON type:
+// "string", "number", "boolean", "object", "array" or "null". Keys of fields inside groups are dot-joined paths,
+// like "req.method".
+//
+// It covers the envelope fields, named after zerolog's field names and according to the options, the fields
+// already in the context of the wrapped logger, and the attributes added with WithAttrs, whose type is the one
+// of their encoding. Record attributes and fields added by hooks at write time are not covered.
+//
+// The fields of the logger's context and zerolog's timestamp and caller hooks are read without writing a record
+// through the logger, so that its hooks don't run.
+func (h *Handler) Schema() map[string]string {
+	if h.isZero() {
+		return map[string]string{}
+	}
+	schema := h.envelopeSchema()
+	h.attrsSchema(schema)
+	return schema
+}
+
+// Schema returns the fields known to be written in every record by the handler. See Handler.Schema.
+func (h *groupHandler) Schema() map[string]string {
+	schema := h.root.envelopeSchema()
+	h.attrsSchema(schema)
+	return schema
+}
+
+// envelopeSchema returns the schema of the fields written by the handler itself.
+func (h *Handler) envelopeSchema() map[string]string {
+	names := &h.opts.FieldNames
+	schema := map[string]string{
+		names.message(): schemaString,
+	}
+	switch zerolog.TimeFieldFormat {
+	case zerolog.TimeFormatUnix, zerolog.TimeFormatUnixMs, zerolog.TimeFormatUnixMicro, zerolog.TimeFormatUnixNano:
+		schema[names.time()] = schemaNumber
+	default:
+		schema[names.time()] = schemaString
+	}
+	if h.opts.AddDeltaTime {
+		schema[DeltaTimeKey] = schemaNumber
+	}
+	if !h.opts.OmitLevel {
+		schema[names.level()] = schemaString
+	}
+	if h.component != "" {
+		schema[h.componentKey()] = schemaString
+	}
+	if o := h.opts.LogstashFormat; o != nil {
+		schema[LogstashVersionKey] = schemaString
+		if o.Dataset != "" {
+			schema[LogstashEventKey+".dataset"] = schemaString
+		}
+		if o.Module != "" {
+			schema[LogstashEventKey+".module"] = schemaString
+		}
+	}
+	if h.opts.OTELSeverity {
+		schema[OTELSeverityNumberKey] = schemaNumber
+		schema[OTELSeverityTextKey] = schemaString
+	}
+	if h.opts.AddSource {
+		caller := names.caller()
+		switch h.opts.SourceFormat {
+		case SourceObject:
+			schema[caller+".function"] = schemaString
+			schema[caller+".file"] = schemaString
+			schema[caller+".line"] = schemaNumber
+		case SourceFlatFields:
+			schema[caller+sourceFileSuffix] = schemaString
+			schema[caller+sourceLineSuffix] = schemaNumber
+			schema[caller+sourceFuncSuffix] = schemaString
+		default:
+			schema[caller] = schemaString
+		}
+	}
+	return schema
+}
+
+// attrsSchema implements zerologHandler.
+func (h *Handler) attrsSchema(schema map[string]string) {
+	var attrs []slog.Attr
+	if h.opts.StrictSlogCompliance {
+		attrs = normalizeAttrs(h.pending.collect(nil))
+	} else if h.opts.DedupKeys {
+		attrs = dedupAttrs(h.pending.collect(nil))
+	} else if h.opts.EnvelopeFirst || h.opts.mergesAttrs() {
+		attrs = h.pending.collect(nil)
+	}
+	if prefix := h.attrsPrefix(); prefix != "" {
+		probeSchema(schema, "", *h.contextLogger(), h.limits, nil)
+		probeSchema(schema, prefix, zerolog.New(nil), h.limits, attrs)
+		return
+	}
+	probeSchema(schema, "", *h.contextLogger(), h.limits, attrs)
+}
+
+// attrsSchema implements zerologHandler.
+func (h *groupHandler) attrsSchema(schema map[string]string) {
+	h.parent.attrsSchema(schema)
+	var attrs []slog.Attr
+	if h.root.opts.StrictSlogCompliance {
+		attrs = normalizeAttrs(h.pending.collect(nil))
+	} else if h.root.opts.DedupKeys {
+		attrs = dedupAttrs(h.pending.collect(nil))
+	} else if h.root.opts.mergesAttrs() {
+		attrs = h.pending.collect(nil)
+	}
+	probeSchema(schema, h.root.attrsPrefix()+h.prefix, h.groupLogger(), h.root.limits, attrs)
+}
+
+// Types of the hooks added by zerolog.Context.Timestamp and zerolog.Context.Caller, nil if they can't be read.
+var (
+	timestampHook = zerologHook(zerolog.Context.Timestamp)
+	callerHook    = zerologHook(zerolog.Context.Caller)
+)
+
+// zerologHook returns the type of the hook added by add, nil if it can't be read.
+func zerologHook(add func(zerolog.Context) zerolog.Context) reflect.Type {
+	if hooks := loggerHooks(add(zerolog.New(nil).With()).Logger()); len(hooks) == 1 {
+		return hooks[0]
+	}
+	return nil
+}
+
+// loggerHooks returns the types of the hooks of l. zerolog doesn't expose them, they're read with reflection.
+func loggerHooks(l zerolog.Logger) []reflect.Type {
+	hooks := reflect.ValueOf(l).FieldByName("hooks")
+	if hooks.Kind() != reflect.Slice {
+		return nil
+	}
+	var types []reflect.Type
+	for i := 0; i < hooks.Len(); i++ {
+		if hook := hooks.Index(i).Elem(); hook.IsValid() {
+			types = append(types, hook.Type())
+		}
+	}
+	return types
+}
+
+// loggerProbe describes the fields written by a zerolog.Logger in every record. It's read without writing
+// any record through the logger, so that its hooks never see a probe record.
+type loggerProbe struct {
+	// fields are the decoded fields of the logger's context.
+	fields map[string]any
+	// time and caller report whether the logger has the hooks of zerolog.Context.Timestamp and zerolog.Context.Caller.
+	time, caller bool
+}
+
+// newLoggerProbe reads the hooks and the context of l. zerolog doesn't expose its context, it's read with
+// reflection, as a JSON object missing its closing brace.
+func newLoggerProbe(l zerolog.Logger) loggerProbe {
+	var p loggerProbe
+	for _, hook := range loggerHooks(l) {
+		p.time = p.time || hook == timestampHook
+		p.caller = p.caller || hook == callerHook
+	}
+	if ctx := reflect.ValueOf(l).FieldByName("context"); ctx.Kind() == reflect.Slice && ctx.Len() > 1 {
+		dec := json.NewDecoder(io.MultiReader(bytes.NewReader(ctx.Bytes()), strings.NewReader("}")))
+		dec.UseNumber()
+		if dec.Decode(&p.fields) != nil {
+			p.fields = nil
+		}
+	}
+	return p
+}
+
+// logger returns a logger writing to w the timestamp and caller fields as the probed logger does, and no other
+// field. Its only hooks are zerolog's ones.
+func (p loggerProbe) logger(w io.Writer) zerolog.Logger {
+	ctx := zerolog.New(w).Level(zerolog.TraceLevel).With()
+	if p.time {
+		ctx = ctx.Timestamp()
+	}
+	if p.caller {
+		ctx = ctx.Caller()
+	}
+	return ctx.Logger()
+}
+
+// probeLoggerFields reports whether the records written by l carry the timestamp and caller fields,
+// added by zerolog's hooks or in its context.
+func probeLoggerFields(l zerolog.Logger) (hasTime, hasCaller bool) {
+	p := newLoggerProbe(l)
+	_, hasTime = p.fields[zerolog.TimestampFieldName]
+	_, hasCaller = p.fields[zerolog.CallerFieldName]
+	return hasTime || p.time, hasCaller || p.caller
+}
+
+// probeSchema adds to schema the fields written in every record by l, and attrs bounded by lim, prefixing their
+// keys with prefix. The attributes are written into a probe record by a logger mimicking l, see loggerProbe.
+func probeSchema(schema map[string]string, prefix string, l zerolog.Logger, lim attrLimits, attrs []slog.Attr) {
+	p := newLoggerProbe(l)
+	buf := bytes.Buffer{}
+	probe := p.logger(&buf)
+	evt := probe.Log()
+	if evt == nil {
+		return
+	}
+	mapAttrs(evt, lim, attrs...).Send()
+	fields := map[string]any{}
+	dec := json.NewDecoder(&buf)
+	dec.UseNumber()
+	if dec.Decode(&fields) != nil {
+		return
+	}
+	// Context fields come first in records, the attributes win when decoding duplicate keys.
+	for key, value := range p.fields {
+		if _, ok := fields[key]; !ok {
+			fields[key] = value
+		}
+	}
+	addSchemaFields(schema, prefix, fields)
+}
+
+// addSchemaFields adds the types of the decoded JSON fields to schema, flattening objects.
+func addSchemaFields(schema map[string]string, prefix string, fields map[string]any) {
+	for key, value := range fields {
+		key = prefix + key
+		switch value := value.(type) {
+		case map[string]any:
+			if len(value) == 0 {
+				schema[key] = schemaObject
+			}
+			addSchemaFields(schema, key+".", value)
+		case string:
+			schema[key] = schemaString
+		case json.Number:
+			schema[key] = schemaNumber
+		case bool:
+			schema[key] = schemaBoolean
+		case []any:
+			schema[key] = schemaArray
+		default:
+			schema[key] = schemaNull
+		}
+	}
+}
+
+// schemaEmitter writes the schema record of EmitSchemaOnStart once, for a handler and all its derived handlers.
+type schemaEmitter struct {
+	once sync.Once
+}
+
+// emit writes the schema returned by schema with logger, if it's the first call.
+func (e *schemaEmitter) emit(logger zerolog.Logger, schema func() map[string]string) {
+	if e == nil {
+		return
+	}
+	e.once.Do(func() {
+		evt := logger.Log()
+		if evt == nil {
+			return
+		}
+		fields := schema()
+		keys := make([]string, 0, len(fields))
+		for key := range fields {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		dict := zerolog.Dict()
+		for _, key := range keys {
+			dict.Str(key, fields[key])
+		}
+		evt.Dict(SchemaKey, dict).Msg(SchemaMessage)
+	})
+}
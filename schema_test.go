@@ -0,0 +1,92 @@
+package zeroslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"maps"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestSchema(t *testing.T) {
+	logger := zerolog.New(nil).With().Str("service", "api").Int("pid", 1).Logger()
+	for name, strict := range map[string]bool{"default": false, "strict": true} {
+		t.Run(name, func(t *testing.T) {
+			var hdl slog.Handler = NewHandler(logger, &HandlerOptions{AddSource: true, SourceFormat: SourceObject, StrictSlogCompliance: strict})
+			hdl = hdl.WithAttrs([]slog.Attr{slog.String("user", "bob"), slog.Bool("admin", true)}).
+				WithGroup("req").
+				WithAttrs([]slog.Attr{
+					slog.Int("id", 12),
+					slog.Any("tags", []string{"a"}),
+					slog.Duration("latency", time.Second),
+					slog.Group("client", slog.String("ip", "127.0.0.1")),
+				})
+			exp := map[string]string{
+				"level":           "string",
+				"time":            "string",
+				"message":         "string",
+				"caller.function": "string",
+				"caller.file":     "string",
+				"caller.line":     "number",
+				"service":         "string",
+				"pid":             "number",
+				"user":            "string",
+				"admin":           "boolean",
+				"req.id":          "number",
+				"req.tags":        "array",
+				"req.latency":     "number",
+				"req.client.ip":   "string",
+			}
+			if schema := hdl.(interface{ Schema() map[string]string }).Schema(); !maps.Equal(schema, exp) {
+				t.Fatalf("Unexpected schema %v", schema)
+			}
+		})
+	}
+}
+
+func TestSchema_Options(t *testing.T) {
+	hdl := NewJsonHandler(nil, &HandlerOptions{OmitLevel: true, AddSource: true, SourceFormat: SourceFlatFields})
+	exp := map[string]string{
+		"time":        "string",
+		"message":     "string",
+		"caller_file": "string",
+		"caller_line": "number",
+		"caller_func": "string",
+	}
+	if schema := hdl.Schema(); !maps.Equal(schema, exp) {
+		t.Fatalf("Unexpected schema %v", schema)
+	}
+}
+
+func TestEmitSchemaOnStart(t *testing.T) {
+	out := bytes.Buffer{}
+	logger := slog.New(NewJsonHandler(&out, &HandlerOptions{EmitSchemaOnStart: true})).With("user", "bob")
+	logger.Debug("filtered")
+	if out.Len() != 0 {
+		t.Fatalf("Schema written for a filtered record: %s", out.String())
+	}
+	logger.WithGroup("req").Info("first", "id", 1)
+	logger.Info("second")
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Unexpected output %s", out.String())
+	}
+	m := map[string]any{}
+	if err := json.Unmarshal([]byte(lines[0]), &m); err != nil {
+		t.Fatalf("Failed to json decode log output: %s", err.Error())
+	}
+	if m["message"] != SchemaMessage || m["level"] != nil {
+		t.Fatalf("Unexpected schema record %s", lines[0])
+	}
+	if schema, _ := m[SchemaKey].(map[string]any); schema["user"] != "string" || schema["level"] != "string" {
+		t.Fatalf("Unexpected schema record %s", lines[0])
+	}
+	if !strings.Contains(lines[1], `"message":"first"`) || !strings.Contains(lines[2], `"message":"second"`) {
+		t.Fatalf("Unexpected output %s", out.String())
+	}
+}
@@ -0,0 +1,39 @@
+package zeroslog
+
+import (
+	"path"
+	"runtime/debug"
+	"strings"
+)
+
+// ServiceKey is the key of the field holding the service name written with AddServiceName.
+const ServiceKey = "service"
+
+// readBuildInfo reads the build information of the binary. It's a variable for tests.
+var readBuildInfo = debug.ReadBuildInfo
+
+// serviceName returns the service name written with opts.AddServiceName: opts.ServiceName if set,
+// or the last element of the main module path, without its major version suffix. It returns an
+// empty string if the build information is unavailable.
+func serviceName(opts *HandlerOptions) string {
+	if opts.ServiceName != "" {
+		return opts.ServiceName
+	}
+	info, ok := readBuildInfo()
+	if !ok || info.Main.Path == "" {
+		return ""
+	}
+	name := path.Base(info.Main.Path)
+	if isMajorVersion(name) {
+		if dir := path.Dir(info.Main.Path); dir != "." {
+			name = path.Base(dir)
+		}
+	}
+	return name
+}
+
+// isMajorVersion reports whether elem is a module path major version suffix, like "v2".
+func isMajorVersion(elem string) bool {
+	digits, ok := strings.CutPrefix(elem, "v")
+	return ok && digits != "" && strings.Trim(digits, "0123456789") == ""
+}
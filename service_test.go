@@ -0,0 +1,53 @@
+package zeroslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"runtime/debug"
+	"testing"
+)
+
+func TestAddServiceName(t *testing.T) {
+	defer func(f func() (*debug.BuildInfo, bool)) { readBuildInfo = f }(readBuildInfo)
+	buildInfo := func(path string, ok bool) func() (*debug.BuildInfo, bool) {
+		return func() (*debug.BuildInfo, bool) {
+			if !ok {
+				return nil, false
+			}
+			return &debug.BuildInfo{Main: debug.Module{Path: path}}, true
+		}
+	}
+
+	for _, tc := range []struct {
+		name string
+		opts HandlerOptions
+		info func() (*debug.BuildInfo, bool)
+		exp  any
+	}{
+		{"disabled", HandlerOptions{ServiceName: "api"}, buildInfo("example.com/api", true), nil},
+		{"module", HandlerOptions{AddServiceName: true}, buildInfo("example.com/fleet/api", true), "api"},
+		{"major-version", HandlerOptions{AddServiceName: true}, buildInfo("example.com/fleet/api/v2", true), "api"},
+		{"single-element", HandlerOptions{AddServiceName: true}, buildInfo("api", true), "api"},
+		{"override", HandlerOptions{AddServiceName: true, ServiceName: "billing"}, buildInfo("example.com/api", true), "billing"},
+		{"override-no-build-info", HandlerOptions{AddServiceName: true, ServiceName: "billing"}, buildInfo("", false), "billing"},
+		{"no-build-info", HandlerOptions{AddServiceName: true}, buildInfo("", false), nil},
+		{"no-main-path", HandlerOptions{AddServiceName: true}, buildInfo("", true), nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			readBuildInfo = tc.info
+			out := bytes.Buffer{}
+			logger := slog.New(NewJsonHandler(&out, &tc.opts))
+			readBuildInfo = buildInfo("example.com/other", true) // Resolved at construction only.
+			logger.WithGroup("g").Info("hello", "a", 1)
+
+			var m map[string]any
+			if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+				t.Fatal(err)
+			}
+			if m[ServiceKey] != tc.exp {
+				t.Errorf("Expected service %v, got %v in %s", tc.exp, m[ServiceKey], out.String())
+			}
+		})
+	}
+}
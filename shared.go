@@ -0,0 +1,179 @@
+package zeroslog
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+
+	"github.com/rs/zerolog"
+)
+
+// Destination is an output of a handler created with NewSharedEncodingHandler.
+type Destination struct {
+	// Name identifies the destination for records routed with Route. Several destinations may share a name.
+	Name string
+	// Writer receives the records admitted by Leveler.
+	Writer io.Writer
+	// Leveler is the minimum level of the records written to Writer.
+	// If nil, opts.Level is used if set, and slog.LevelInfo otherwise.
+	Leveler slog.Leveler
+	// ReplaceAttr, if not nil, replaces opts.ReplaceAttr for this destination, like to redact attributes
+	// written to a less trusted output. Only NewFanoutHandler supports it.
+	ReplaceAttr func(groups []string, a slog.Attr) slog.Attr
+}
+
+// NewSharedEncodingHandler creates a handler encoding each record once as JSON, and writing the
+// resulting bytes to every destination whose level admits it. It's cheaper than sending records to
+// several handlers which only differ by their output and level, as attributes are encoded only once.
+// WithAttrs and WithGroup apply to all the destinations.
+//
+// The handler is enabled for the lowest level of the destinations, and levels are read for each record,
+// so that they can be adjusted with a LevelVar. Like with OnRecordSize, destination levels are compared to the
+// zerolog level of the record, mapped back with SlogLevel. With OmitLevel, records go to all the destinations.
+//
+// Records holding a marker returned by Route are also written to the destinations with the given names.
+//
+// A failed write to a destination doesn't prevent writing to the other ones, and is reported to zerolog.ErrorHandler.
+// Since all the destinations get the same bytes, it panics if a destination has a ReplaceAttr.
+func NewSharedEncodingHandler(destinations []Destination, opts *HandlerOptions) *Handler {
+	for _, d := range destinations {
+		if d.ReplaceAttr != nil {
+			panic("zeroslog: NewSharedEncodingHandler doesn't support Destination.ReplaceAttr, use NewFanoutHandler")
+		}
+	}
+	w := newFanoutWriter(destinations, opts)
+	return newSharedEncodingHandler(w, w, opts, w.names(), true)
+}
+
+// newSharedEncodingHandler creates a handler writing records to w, enabled for level. Route names are
+// checked against known, and unknown ones reported if report is true.
+func newSharedEncodingHandler(w fanoutWriter, level slog.Leveler, opts *HandlerOptions, known map[string]bool, report bool) *Handler {
+	h := NewHandler(zerolog.New(nil), optionsWithLevel(opts, level))
+	h.setOutput(w)
+	h.routes = &fanoutRoutes{destinations: w, known: known, report: report}
+	return h
+}
+
+// newFanoutWriter creates the fanoutWriter writing to destinations. Their level defaults to opts.Level if set,
+// and to slog.LevelInfo otherwise.
+func newFanoutWriter(destinations []Destination, opts *HandlerOptions) fanoutWriter {
+	def := slog.Leveler(slog.LevelInfo)
+	if opts != nil && opts.Level != nil {
+		def = opts.Level
+	}
+	w := make(fanoutWriter, len(destinations))
+	for i, d := range destinations {
+		lw, ok := d.Writer.(zerolog.LevelWriter)
+		if !ok {
+			lw = zerolog.LevelWriterAdapter{Writer: d.Writer}
+		}
+		w[i] = fanoutDestination{out: lw, level: d.Leveler, name: d.Name}
+		if w[i].level == nil {
+			w[i].level = def
+		}
+	}
+	return w
+}
+
+// NewFanoutHandler creates a handler writing records as JSON to several destinations, which can transform
+// attributes differently with Destination.ReplaceAttr.
+//
+// The cost is one encoding per distinct transformation: the destinations without ReplaceAttr share a single
+// encoding, as with NewSharedEncodingHandler, using opts.ReplaceAttr, while each destination with a ReplaceAttr
+// has its own encoding, even if several destinations use the same function. Destinations needing the same
+// transformation should then be combined into one, with an io.MultiWriter.
+//
+// Records are sent to the encodings in the order of the destinations, the shared one being at the position
+// of its first destination, and attribute values are resolved once for all of them. Unknown names given to Route
+// are reported once, by the first encoding. Encodings writing to named destinations are enabled for the lowest
+// level of all the destinations, so that records can be routed to them.
+func NewFanoutHandler(destinations []Destination, opts *HandlerOptions) slog.Handler {
+	all := newFanoutWriter(destinations, opts)
+	known := all.names()
+	// Encodings writing to named destinations are enabled for the levels of all the destinations,
+	// as records can be routed to them whatever their level.
+	levelOf := func(w fanoutWriter) slog.Leveler {
+		if len(w.names()) > 0 {
+			return all
+		}
+		return w
+	}
+	var handlers []slog.Handler
+	var shared []Destination
+	sharedAt := -1
+	for _, d := range destinations {
+		if d.ReplaceAttr == nil {
+			if sharedAt < 0 {
+				sharedAt = len(handlers)
+				handlers = append(handlers, nil)
+			}
+			shared = append(shared, d)
+			continue
+		}
+		opt := optionsWithLevel(opts, nil)
+		opt.ReplaceAttr = d.ReplaceAttr
+		w := newFanoutWriter([]Destination{d}, opts)
+		handlers = append(handlers, newSharedEncodingHandler(w, levelOf(w), opt, known, len(handlers) == 0))
+	}
+	if sharedAt >= 0 {
+		w := newFanoutWriter(shared, opts)
+		handlers[sharedAt] = newSharedEncodingHandler(w, levelOf(w), opts, known, sharedAt == 0)
+	}
+	return &multiHandler{handlers: handlers}
+}
+
+// fanoutDestination is a destination of a fanoutWriter.
+type fanoutDestination struct {
+	out   zerolog.LevelWriter
+	level slog.Leveler
+	name  string
+}
+
+// fanoutWriter is a zerolog.LevelWriter writing records to the destinations admitting their level.
+// It's also the slog.Leveler reporting the lowest level of the destinations.
+type fanoutWriter []fanoutDestination
+
+var (
+	_ zerolog.LevelWriter = fanoutWriter(nil)
+	_ slog.Leveler        = fanoutWriter(nil)
+)
+
+// names returns the set of the names of the destinations.
+func (w fanoutWriter) names() map[string]bool {
+	names := make(map[string]bool, len(w))
+	for _, d := range w {
+		if d.name != "" {
+			names[d.name] = true
+		}
+	}
+	return names
+}
+
+// Level implements slog.Leveler. It returns the lowest level of the destinations,
+// or the highest possible level if there is none.
+func (w fanoutWriter) Level() slog.Level {
+	lvl := SlogLevel(zerolog.Disabled)
+	for _, d := range w {
+		lvl = min(lvl, d.level.Level())
+	}
+	return lvl
+}
+
+// Write implements io.Writer.
+func (w fanoutWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel implements zerolog.LevelWriter.
+func (w fanoutWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	var errs []error
+	for _, d := range w {
+		if level != zerolog.NoLevel && SlogLevel(level) < d.level.Level() {
+			continue
+		}
+		if _, err := d.out.WriteLevel(level, p); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return len(p), errors.Join(errs...)
+}
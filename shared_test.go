@@ -0,0 +1,113 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+type errWriter struct{}
+
+func (errWriter) Write(p []byte) (int, error) { return 0, errors.New("write failure") }
+
+func TestSharedEncodingHandler_Levels(t *testing.T) {
+	debug, info, errs := bytes.Buffer{}, bytes.Buffer{}, bytes.Buffer{}
+	errLvl := new(slog.LevelVar)
+	errLvl.Set(slog.LevelError)
+	hdl := NewSharedEncodingHandler([]Destination{
+		{Writer: &debug, Leveler: slog.LevelDebug},
+		{Writer: &info},
+		{Writer: &errs, Leveler: errLvl},
+	}, nil)
+	ctx := context.Background()
+	if !hdl.Enabled(ctx, slog.LevelDebug) || hdl.Enabled(ctx, slog.LevelDebug-1) {
+		t.Fatal("Handler must be enabled for the lowest destination level")
+	}
+
+	logger := slog.New(hdl)
+	logger.Debug("debug")
+	logger.Info("info")
+	logger.Error("error")
+	errLvl.Set(slog.LevelWarn)
+	logger.Warn("warn")
+
+	for name, tc := range map[string]struct {
+		out *bytes.Buffer
+		exp []string
+	}{
+		"debug": {&debug, []string{"debug", "info", "error", "warn"}},
+		"info":  {&info, []string{"info", "error", "warn"}},
+		"error": {&errs, []string{"error", "warn"}},
+	} {
+		lines := strings.Split(strings.TrimSuffix(tc.out.String(), "\n"), "\n")
+		if len(lines) != len(tc.exp) {
+			t.Fatalf("%s: unexpected output %q", name, tc.out.String())
+		}
+		for i, msg := range tc.exp {
+			if !strings.Contains(lines[i], `"message":"`+msg+`"`) {
+				t.Fatalf("%s: unexpected record %s, expected message %q", name, lines[i], msg)
+			}
+		}
+	}
+}
+
+func TestSharedEncodingHandler_DefaultLevel(t *testing.T) {
+	a, b := bytes.Buffer{}, bytes.Buffer{}
+	hdl := NewSharedEncodingHandler([]Destination{{Writer: &a}, {Writer: &b, Leveler: slog.LevelError}}, &HandlerOptions{Level: slog.LevelWarn})
+	logger := slog.New(hdl)
+	logger.Info("info")
+	logger.Warn("warn")
+	if a.Len() == 0 || strings.Contains(a.String(), "info") || b.Len() != 0 {
+		t.Fatalf("Unexpected outputs %q and %q", a.String(), b.String())
+	}
+}
+
+func TestSharedEncodingHandler_Derived(t *testing.T) {
+	a, b := bytes.Buffer{}, bytes.Buffer{}
+	hdl := NewSharedEncodingHandler([]Destination{{Writer: &a}, {Writer: errWriter{}}, {Writer: &b}}, nil)
+	logger := slog.New(hdl).With("service", "api").WithGroup("req").With("id", 12)
+	logger.Info("hello", "path", "/")
+	exp := `"service":"api","req":{"id":12,"path":"/"}`
+	if !strings.Contains(a.String(), exp) || a.String() != b.String() {
+		t.Fatalf("Unexpected outputs %q and %q", a.String(), b.String())
+	}
+}
+
+// redact replaces the values of "password" attributes.
+func redact(groups []string, a slog.Attr) slog.Attr {
+	if a.Key == "password" {
+		return slog.String(a.Key, "REDACTED")
+	}
+	return a
+}
+
+func TestFanoutHandler_ReplaceAttr(t *testing.T) {
+	file, audit, console := bytes.Buffer{}, bytes.Buffer{}, bytes.Buffer{}
+	logger := slog.New(NewFanoutHandler([]Destination{
+		{Writer: &file},
+		{Writer: &console, ReplaceAttr: redact},
+		{Writer: &audit},
+	}, nil))
+	logger.WithGroup("user").With("name", "bob").Info("login", "password", "hunter2")
+
+	for name, out := range map[string]*bytes.Buffer{"file": &file, "audit": &audit} {
+		if !strings.Contains(out.String(), `"user":{"name":"bob","password":"hunter2"}`) {
+			t.Errorf("Expected the full record in %s, got %s", name, out.String())
+		}
+	}
+	if got := console.String(); strings.Contains(got, "hunter2") || !strings.Contains(got, `"user":{"name":"bob","password":"REDACTED"}`) {
+		t.Errorf("Expected a redacted record in console, got %s", got)
+	}
+}
+
+func TestSharedEncodingHandler_ReplaceAttr(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic")
+		}
+	}()
+	NewSharedEncodingHandler([]Destination{{Writer: &bytes.Buffer{}, ReplaceAttr: redact}}, nil)
+}
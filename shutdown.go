@@ -0,0 +1,87 @@
+package zeroslog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// Drainer is implemented by handlers doing work in background goroutines, like the batching and queued
+// handlers, or handlers emitting suppression summaries. Shutdown drains them together.
+type Drainer interface {
+	// Drain stops the background work of the handler, and delivers its pending records. It returns
+	// once they're delivered, or when ctx is done, in which case the error of ctx is returned.
+	Drain(ctx context.Context) error
+}
+
+var (
+	_ Drainer = (*Handler)(nil)
+	_ Drainer = (*BatchingHandler)(nil)
+	_ Drainer = (*QueuedHandler)(nil)
+	_ Drainer = (*RotatingFileHandler)(nil)
+	_ Drainer = (*CompressedFileHandler)(nil)
+	_ Drainer = (*EventLogHandler)(nil)
+)
+
+// Shutdown drains the handlers implementing Drainer concurrently, and returns their joined errors.
+// Other handlers are ignored. It returns when all the handlers are drained, or when ctx is done.
+func Shutdown(ctx context.Context, handlers ...slog.Handler) error {
+	errs := make(chan error, len(handlers))
+	n := 0
+	for _, hdl := range handlers {
+		if d, ok := hdl.(Drainer); ok {
+			n++
+			go func() { errs <- d.Drain(ctx) }()
+		}
+	}
+	var all []error
+	for ; n > 0; n-- {
+		all = append(all, <-errs)
+	}
+	return errors.Join(all...)
+}
+
+// Drain implements Drainer. It stops the goroutine emitting suppression summaries, and emits the pending ones.
+// Handlers derived from h must not be used after Drain.
+func (h *Handler) Drain(ctx context.Context) error {
+	return drain(ctx, h.Close)
+}
+
+// Drain implements Drainer. It closes h, canceling pending deliveries when ctx is done, in which case
+// the undelivered records are dropped, counted in Stats and reported to OnError.
+func (h *BatchingHandler) Drain(ctx context.Context) error {
+	return errors.Join(h.Handler.Drain(ctx), h.writer.closeContext(ctx))
+}
+
+// Drain implements Drainer. It closes h, but returns early if ctx is done before the pending records are written.
+func (h *QueuedHandler) Drain(ctx context.Context) error {
+	return drain(ctx, h.Close)
+}
+
+// Drain implements Drainer. It closes h, syncing and closing the file, but returns early if ctx is done first.
+func (h *RotatingFileHandler) Drain(ctx context.Context) error {
+	return drain(ctx, h.Close)
+}
+
+// Drain implements Drainer. It closes h, completing and closing the compressed file, but returns early if ctx
+// is done first.
+func (h *CompressedFileHandler) Drain(ctx context.Context) error {
+	return drain(ctx, h.Close)
+}
+
+// Drain implements Drainer. It closes h and the event log, but returns early if ctx is done first.
+func (h *EventLogHandler) Drain(ctx context.Context) error {
+	return drain(ctx, h.Close)
+}
+
+// drain calls close in a new goroutine, and returns its error, or the error of ctx if it's done first.
+func drain(ctx context.Context, close func() error) error {
+	done := make(chan error, 1)
+	go func() { done <- close() }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
@@ -0,0 +1,99 @@
+package zeroslog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShutdown(t *testing.T) {
+	rec := &batchRecorder{}
+	batching := NewBatchingHandler(rec.flush, 10, time.Hour, nil)
+	out := bytes.Buffer{}
+	queued := NewQueuedHandler(&out, QueueOptions{Shards: 2}, nil)
+	plain := NewJsonHandler(&bytes.Buffer{}, nil)
+	slog.New(batching).Info("batched")
+	slog.New(queued).Info("queued")
+
+	if err := Shutdown(context.Background(), batching, queued, plain, slog.Default().Handler()); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if msgs := rec.messages(t); len(msgs) != 1 || msgs[0] != "batched" {
+		t.Errorf("Expected the batched record to be delivered, got %v", msgs)
+	}
+	if !strings.Contains(out.String(), "queued") {
+		t.Errorf("Expected the queued record to be written, got %q", out.String())
+	}
+}
+
+func TestShutdown_Files(t *testing.T) {
+	dir := t.TempDir()
+	rotating, err := NewRotatingFileHandler(filepath.Join(dir, "app.log"), 0, 0, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compressed, err := NewCompressedFileHandler(filepath.Join(dir, "app.json"), GzipCodec(gzip.DefaultCompression), 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := &fakeEventLog{}
+	eventLog := &EventLogHandler{newEventLogHandler(log, slog.LevelInfo, nil)}
+	slog.New(rotating).Info("rotating")
+	slog.New(compressed).Info("compressed")
+
+	if err := Shutdown(context.Background(), rotating, compressed, eventLog); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if lines := rotatedFiles(t, filepath.Join(dir, "app.log"))["app.log"]; len(lines) != 1 || !strings.Contains(lines[0], `"rotating"`) {
+		t.Errorf("Expected the rotating record to be written, got %q", lines)
+	}
+	if lines := readGzipLines(t, filepath.Join(dir, "app.json.gz")); len(lines) != 1 || !strings.Contains(lines[0], `"compressed"`) {
+		t.Errorf("Expected the compressed record to be written, got %q", lines)
+	}
+	if log.closed != 1 {
+		t.Errorf("Expected the event log to be closed, got %d closes", log.closed)
+	}
+}
+
+func TestShutdown_Deadline(t *testing.T) {
+	var errs []error
+	hdl := newBatchingHandler(func(ctx context.Context, batch [][]byte) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, BatchOptions{MaxBatch: 10}, &HandlerOptions{OnError: func(err error) { errs = append(errs, err) }})
+	slog.New(hdl).Info("slow")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := Shutdown(ctx, hdl)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a deadline error, got %v", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("Expected Shutdown to return at the deadline, took %s", d)
+	}
+	if st := hdl.Stats(); st.Dropped != 1 || len(errs) != 1 {
+		t.Errorf("Expected the undelivered record to be dropped and reported, got %d dropped and %v", st.Dropped, errs)
+	}
+}
+
+func TestDrain_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := drain(ctx, func() error {
+		<-release
+		return nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a deadline error, got %v", err)
+	}
+}
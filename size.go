@@ -0,0 +1,36 @@
+package zeroslog
+
+import (
+	"io"
+	"log/slog"
+
+	"github.com/rs/zerolog"
+)
+
+// sizeWriter is a zerolog.LevelWriter reporting the size of each record written.
+type sizeWriter struct {
+	out    zerolog.LevelWriter
+	report func(level slog.Level, bytes int)
+}
+
+var _ zerolog.LevelWriter = sizeWriter{}
+
+// newSizeWriter wraps out so that the size of each record is passed to report.
+func newSizeWriter(out io.Writer, report func(level slog.Level, bytes int)) sizeWriter {
+	lw, ok := out.(zerolog.LevelWriter)
+	if !ok {
+		lw = zerolog.LevelWriterAdapter{Writer: out}
+	}
+	return sizeWriter{out: lw, report: report}
+}
+
+// Write implements io.Writer.
+func (w sizeWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel implements zerolog.LevelWriter.
+func (w sizeWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	w.report(SlogLevel(level), len(p))
+	return w.out.WriteLevel(level, p)
+}
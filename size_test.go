@@ -0,0 +1,48 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestOnRecordSize(t *testing.T) {
+	out := bytes.Buffer{}
+	var levels []slog.Level
+	var sizes []int
+	hdl := NewJsonHandler(&out, &HandlerOptions{
+		Level: slog.LevelDebug,
+		OnRecordSize: func(level slog.Level, bytes int) {
+			levels = append(levels, level)
+			sizes = append(sizes, bytes)
+		},
+	})
+	for i, lvl := range []slog.Level{slog.LevelDebug, slog.LevelWarn, slog.LevelError} {
+		rec := slog.NewRecord(now, lvl, "foobar", 0)
+		rec.AddAttrs(slog.String("payload", strings.Repeat("x", 10*i)))
+		hdl.WithGroup("g").Handle(context.Background(), rec)
+	}
+
+	lines := strings.SplitAfter(out.String(), "\n")
+	lines = lines[:len(lines)-1]
+	if len(sizes) != 3 || len(lines) != 3 {
+		t.Fatalf("Unexpected sizes %v for output %q", sizes, out.String())
+	}
+	for i, lvl := range []slog.Level{slog.LevelDebug, slog.LevelWarn, slog.LevelError} {
+		if sizes[i] != len(lines[i]) || levels[i] != lvl {
+			t.Errorf("Unexpected size %d at level %s for line %q", sizes[i], levels[i], lines[i])
+		}
+	}
+}
+
+func TestOnRecordSize_Console(t *testing.T) {
+	out := bytes.Buffer{}
+	total := 0
+	hdl := NewConsoleHandler(&out, &HandlerOptions{OnRecordSize: func(_ slog.Level, bytes int) { total += bytes }})
+	hdl.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "foobar", 0))
+	if total == 0 || out.Len() == 0 {
+		t.Fatalf("Record size not reported")
+	}
+}
@@ -0,0 +1,91 @@
+package zeroslog
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+)
+
+var (
+	stringerType      = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// textSlice returns the elements of value, if it's a slice or an array whose element type implements
+// fmt.Stringer or encoding.TextMarshaler, like a slice of enums, converted like mapAttrAny converts single
+// values: to their string form, and to nil for nil elements. Otherwise, it returns false.
+func textSlice(value any) ([]any, bool) {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+	if et := rv.Type().Elem(); !et.Implements(stringerType) && !et.Implements(textMarshalerType) {
+		return nil, false
+	}
+	if rv.Kind() == reflect.Slice && rv.IsNil() {
+		return nil, false
+	}
+	texts := make([]any, rv.Len())
+	for i := range texts {
+		texts[i] = textElement(rv.Index(i))
+	}
+	return texts, true
+}
+
+// textElement converts an element of a slice handled by textSlice.
+func textElement(e reflect.Value) any {
+	switch e.Kind() {
+	case reflect.Interface, reflect.Pointer, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		if e.IsNil() {
+			return nil
+		}
+	}
+	switch v := e.Interface().(type) {
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	case json.Marshaler:
+		txt, err := v.MarshalJSON()
+		if err != nil {
+			return "!ERROR:" + err.Error()
+		}
+		return json.RawMessage(txt)
+	case encoding.TextMarshaler:
+		txt, err := v.MarshalText()
+		if err != nil {
+			return "!ERROR:" + err.Error()
+		}
+		return string(txt)
+	default:
+		return v
+	}
+}
+
+// sliceLimitStage returns a pipeline stage truncating slice and array values to their first n elements.
+// Byte slices and values written by their own methods, like net.IP or json.RawMessage, are left unchanged.
+func sliceLimitStage(n int) attrStage {
+	return leafStage(func(a slog.Attr) slog.Attr {
+		if a.Value.Kind() != slog.KindAny {
+			return a
+		}
+		value := a.Value.Any()
+		switch value.(type) {
+		case error, fmt.Stringer, json.Marshaler, encoding.TextMarshaler:
+			return a
+		}
+		rv := reflect.ValueOf(value)
+		if (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) || rv.Len() <= n || rv.Type().Elem().Kind() == reflect.Uint8 {
+			return a
+		}
+		if rv.Kind() == reflect.Array {
+			arr := reflect.New(rv.Type()).Elem()
+			arr.Set(rv)
+			rv = arr
+		}
+		a.Value = slog.AnyValue(rv.Slice(0, n).Interface())
+		return a
+	})
+}
@@ -0,0 +1,94 @@
+package zeroslog
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+)
+
+type state int
+
+func (s state) String() string { return [...]string{"idle", "running", "done"}[s] }
+
+type color int
+
+func (c color) MarshalText() ([]byte, error) {
+	if c < 0 {
+		return nil, errors.New("bad color")
+	}
+	return []byte([...]string{"red", "green"}[c]), nil
+}
+
+type enum interface{ String() string }
+
+type direction string
+
+func (d direction) String() string { return "dir:" + string(d) }
+
+func TestTextSlices(t *testing.T) {
+	for name, tc := range map[string]struct {
+		value any
+		exp   string
+	}{
+		"stringer":      {[]state{0, 2, 1}, `["idle","done","running"]`},
+		"array":         {[2]state{1, 1}, `["running","running"]`},
+		"text":          {[]color{1, 0, -1}, `["green","red","!ERROR:bad color"]`},
+		"interface":     {[]enum{state(1), direction("up"), nil}, `["running","dir:up",null]`},
+		"stringer-ptrs": {[]*net.IPNet{{IP: net.IPv4(10, 0, 0, 0).To4(), Mask: net.CIDRMask(8, 32)}, nil}, `["10.0.0.0/8",null]`},
+		"empty":         {[]state{}, `[]`},
+		"nil":           {[]state(nil), `null`},
+		"ints":          {[]int{1, 2}, `[1,2]`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			for hname, newHandler := range map[string]func(*bytes.Buffer) slog.Handler{
+				"json":   func(b *bytes.Buffer) slog.Handler { return NewJsonHandler(b, nil) },
+				"logfmt": func(b *bytes.Buffer) slog.Handler { return NewLogfmtHandler(b, nil) },
+			} {
+				out := bytes.Buffer{}
+				slog.New(newHandler(&out)).Info("msg", "v", tc.value)
+				exp := `"v":` + tc.exp
+				if hname == "logfmt" {
+					exp = "v=" + logfmtQuote(tc.exp)
+				}
+				if !strings.Contains(out.String(), exp) {
+					t.Errorf("%s: expected %s in %s", hname, exp, out.String())
+				}
+			}
+		})
+	}
+}
+
+// logfmtQuote quotes s like logfmt values.
+func logfmtQuote(s string) string {
+	enc := logfmtEncoder{}
+	enc.appendValue(s)
+	return string(enc.buf)
+}
+
+func TestMaxSliceLen(t *testing.T) {
+	out := bytes.Buffer{}
+	logger := slog.New(NewJsonHandler(&out, &HandlerOptions{MaxSliceLen: 2}))
+	logger.WithGroup("g").Info("msg",
+		"states", []state{0, 1, 2},
+		"array", [3]int{1, 2, 3},
+		"short", []int{1},
+		"bytes", []byte("hello"),
+		"ip", net.IPv4(10, 0, 0, 1),
+		slog.Group("sub", "values", []any{1, "a", true}),
+	)
+	for _, exp := range []string{
+		`"states":["idle","running"]`,
+		`"array":[1,2]`,
+		`"short":[1]`,
+		`"bytes":"aGVsbG8="`,
+		`"ip":"10.0.0.1"`,
+		`"sub":{"values":[1,"a"]}`,
+	} {
+		if !strings.Contains(out.String(), exp) {
+			t.Errorf("Expected %s in %s", exp, out.String())
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package zeroslog
+
+import (
+	"log/slog"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// SourceFormat is the way the source of records is written. See HandlerOptions.SourceFormat.
+type SourceFormat int
+
+const (
+	// SourceString writes the source as a single "file:line" string in zerolog.CallerFieldName.
+	SourceString SourceFormat = iota
+	// SourceObject writes the source as an object in zerolog.CallerFieldName, with the "function", "file"
+	// and "line" fields of slog.Source.
+	SourceObject
+	// SourceFlatFields writes the source as 3 top-level fields named after zerolog.CallerFieldName
+	// with a "_file", "_line" and "_func" suffix, like "caller_file". The line is a number.
+	SourceFlatFields
+)
+
+// Suffixes of the source fields written with SourceFlatFields.
+const (
+	sourceFileSuffix = "_file"
+	sourceLineSuffix = "_line"
+	sourceFuncSuffix = "_func"
+)
+
+// writeSource writes the source src to evt in the name field, according to format.
+func writeSource(evt *zerolog.Event, name string, format SourceFormat, src *source) {
+	frame := &src.frame
+	switch format {
+	case SourceObject:
+		evt.Dict(name, zerolog.Dict().
+			Str("function", frame.Function).
+			Str("file", frame.File).
+			Int("line", frame.Line))
+	case SourceFlatFields:
+		evt.Str(name+sourceFileSuffix, frame.File).
+			Int(name+sourceLineSuffix, frame.Line).
+			Str(name+sourceFuncSuffix, frame.Function)
+	default:
+		if src.caller != "" {
+			evt.Str(name, src.caller)
+			return
+		}
+		buf := getBuffer()
+		*buf = append(*buf, frame.File...)
+		*buf = append(*buf, ':')
+		*buf = strconv.AppendInt(*buf, int64(frame.Line), 10)
+		evt.Bytes(name, *buf)
+		putBuffer(buf)
+	}
+}
+
+// writeSourceFields writes a source location to target in the name field, according to format,
+// like writeSource does for the source of records.
+func writeSourceFields[T zlogWriter[T]](target T, name string, format SourceFormat, function, file string, line int) T {
+	switch format {
+	case SourceObject:
+		return target.Dict(name, zerolog.Dict().
+			Str("function", function).
+			Str("file", file).
+			Int("line", line))
+	case SourceFlatFields:
+		return target.Str(name+sourceFileSuffix, file).
+			Int64(name+sourceLineSuffix, int64(line)).
+			Str(name+sourceFuncSuffix, function)
+	default:
+		return target.Str(name, file+":"+strconv.Itoa(line))
+	}
+}
+
+// sourceValue is the value of an attribute holding a *slog.Source, written according to format like
+// the source of records. Attribute values holding a *slog.Source or a slog.Source are converted to
+// sourceValue by sourceStage, and otherwise written like with SourceString.
+type sourceValue struct {
+	src    *slog.Source
+	format SourceFormat
+}
+
+// sourceStage returns the pipeline stage converting *slog.Source and slog.Source values to sourceValue,
+// for them to be written according to format.
+func sourceStage(format SourceFormat) attrStage {
+	return leafStage(func(a slog.Attr) slog.Attr {
+		if a.Value.Kind() != slog.KindAny {
+			return a
+		}
+		switch v := a.Value.Any().(type) {
+		case *slog.Source:
+			if v != nil {
+				a.Value = slog.AnyValue(sourceValue{src: v, format: format})
+			}
+		case slog.Source:
+			a.Value = slog.AnyValue(sourceValue{src: &v, format: format})
+		}
+		return a
+	})
+}
+
+// source is the source of a record.
+type source struct {
+	frame runtime.Frame
+	// caller is the "file:line" string written with SourceString. It's only set for cached sources.
+	caller string
+}
+
+// callerString returns the source as a "file:line" string.
+func (s *source) callerString() string {
+	if s.caller != "" {
+		return s.caller
+	}
+	return s.frame.File + ":" + strconv.Itoa(s.frame.Line)
+}
+
+// slogSource returns the source as a *slog.Source.
+func (s *source) slogSource() *slog.Source {
+	return &slog.Source{Function: s.frame.Function, File: s.frame.File, Line: s.frame.Line}
+}
+
+// sources caches the sources of program counters, which don't change during the life of the program.
+// A map is used rather than a sync.Map to avoid boxing program counters.
+var sources = struct {
+	sync.RWMutex
+	m map[uintptr]*source
+}{m: make(map[uintptr]*source)}
+
+// pcSource returns the cached source of pc.
+func pcSource(pc uintptr) *source {
+	sources.RLock()
+	src, ok := sources.m[pc]
+	sources.RUnlock()
+	if ok {
+		return src
+	}
+	pcs := [1]uintptr{pc}
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	src = &source{frame: frame, caller: frame.File + ":" + strconv.Itoa(frame.Line)}
+	sources.Lock()
+	sources.m[pc] = src
+	sources.Unlock()
+	return src
+}
+
+// maxSourceDepth is the maximum number of frames inspected when looking for the caller
+// of a function in HandlerOptions.SourceSkipPackages.
+const maxSourceDepth = 64
+
+// recordSource returns the source to report for a record logged from pc.
+//
+// If pc is inside one of opts.SourceSkipPackages, the current stack is searched for pc's function,
+// and the first caller outside of these packages is returned. When the record is handled from another
+// goroutine than the one which logged it, there is no such stack, and the source of pc is returned.
+func recordSource(opts *HandlerOptions, pc uintptr) *source {
+	src := pcSource(pc)
+	if !skipFrame(opts.SourceSkipPackages, src.frame) {
+		return src
+	}
+	var pcs [maxSourceDepth]uintptr
+	n := runtime.Callers(2, pcs[:]) // Skip runtime.Callers and recordSource
+	callers := runtime.CallersFrames(pcs[:n])
+	found := false
+	for {
+		f, more := callers.Next()
+		if found && f.Function == "runtime.goexit" {
+			// The stack ends without caller outside of the packages, like in a goroutine started by a wrapper.
+			return src
+		}
+		if found && !skipFrame(opts.SourceSkipPackages, f) {
+			return &source{frame: f}
+		}
+		found = found || f.Function == src.frame.Function
+		if !more {
+			return src
+		}
+	}
+}
+
+// skipFrame reports whether frame's function belongs to one of the packages, or their sub-packages.
+func skipFrame(packages []string, frame runtime.Frame) bool {
+	if len(packages) == 0 {
+		return false
+	}
+	pkg := framePackage(frame.Function)
+	for _, p := range packages {
+		if pkg == p || strings.HasPrefix(pkg, strings.TrimSuffix(p, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// framePackage returns the package path of the fully qualified function name fn,
+// like "github.com/phsym/zeroslog" for "github.com/phsym/zeroslog.(*Handler).Handle".
+func framePackage(fn string) string {
+	slash := strings.LastIndexByte(fn, '/') + 1
+	if dot := strings.IndexByte(fn[slash:], '.'); dot >= 0 {
+		return fn[:slash+dot]
+	}
+	return fn
+}
@@ -0,0 +1,186 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/phsym/zeroslog/internal/logwrap"
+	"github.com/rs/zerolog"
+)
+
+func TestSourceSkipPackages(t *testing.T) {
+	for name, tc := range map[string]struct {
+		skip []string
+		log  func(*slog.Logger)
+		file string
+	}{
+		"none":       {log: func(l *slog.Logger) { logwrap.Info(l, "foobar") }, file: "logwrap.go"},
+		"wrapper":    {skip: []string{"github.com/phsym/zeroslog/internal/logwrap"}, log: func(l *slog.Logger) { logwrap.Info(l, "foobar") }, file: "source_test.go"},
+		"parent":     {skip: []string{"github.com/phsym/zeroslog/internal/"}, log: func(l *slog.Logger) { logwrap.Info(l, "foobar") }, file: "source_test.go"},
+		"not-prefix": {skip: []string{"github.com/phsym/zeroslog/internal/log"}, log: func(l *slog.Logger) { logwrap.Info(l, "foobar") }, file: "logwrap.go"},
+		"async":      {skip: []string{"github.com/phsym/zeroslog/internal/logwrap"}, log: func(l *slog.Logger) { logwrap.InfoAsync(l, "foobar") }, file: "logwrap.go"},
+		"direct":     {skip: []string{"github.com/phsym/zeroslog/internal/logwrap"}, log: func(l *slog.Logger) { l.Info("foobar") }, file: "source_test.go"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			for _, group := range []bool{false, true} {
+				out := bytes.Buffer{}
+				var hdl slog.Handler = NewJsonHandler(&out, &HandlerOptions{AddSource: true, SourceSkipPackages: tc.skip})
+				if group {
+					hdl = hdl.WithGroup("g")
+				}
+				tc.log(slog.New(hdl))
+				m := map[string]any{}
+				if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+					t.Fatalf("Failed to json decode log output: %s", err.Error())
+				}
+				if caller, _ := m[zerolog.CallerFieldName].(string); !strings.HasPrefix(filepath.Base(caller), tc.file+":") {
+					t.Errorf("Unexpected caller %q, expected %s", caller, tc.file)
+				}
+			}
+		})
+	}
+}
+
+func TestFramePackage(t *testing.T) {
+	for fn, pkg := range map[string]string{
+		"github.com/phsym/zeroslog.(*Handler).Handle":     "github.com/phsym/zeroslog",
+		"github.com/phsym/zeroslog.sourceFrame":           "github.com/phsym/zeroslog",
+		"github.com/phsym/zeroslog.Test.func1":            "github.com/phsym/zeroslog",
+		"log/slog.(*Logger).log":                          "log/slog",
+		"main.main":                                       "main",
+		"github.com/a/b.v2/c.F":                           "github.com/a/b.v2/c",
+		"github.com/phsym/zeroslog/internal/logwrap.Info": "github.com/phsym/zeroslog/internal/logwrap",
+	} {
+		if p := framePackage(fn); p != pkg {
+			t.Errorf("Unexpected package %q for %q, expected %q", p, fn, pkg)
+		}
+	}
+}
+
+func TestSourceFormat(t *testing.T) {
+	for name, tc := range map[SourceFormat]func(t *testing.T, m map[string]any){
+		SourceString: func(t *testing.T, m map[string]any) {
+			if caller, _ := m["caller"].(string); !strings.HasPrefix(filepath.Base(caller), "source_test.go:") {
+				t.Errorf("Unexpected caller %v", m["caller"])
+			}
+		},
+		SourceObject: func(t *testing.T, m map[string]any) {
+			caller, _ := m["caller"].(map[string]any)
+			if file, _ := caller["file"].(string); filepath.Base(file) != "source_test.go" {
+				t.Errorf("Unexpected caller file %v", caller["file"])
+			}
+			if _, ok := caller["line"].(float64); !ok {
+				t.Errorf("Caller line must be a number, got %v", caller["line"])
+			}
+			if fn, _ := caller["function"].(string); !strings.HasPrefix(fn, "github.com/phsym/zeroslog.TestSourceFormat") {
+				t.Errorf("Unexpected caller function %v", caller["function"])
+			}
+		},
+		SourceFlatFields: func(t *testing.T, m map[string]any) {
+			if _, ok := m["caller"]; ok {
+				t.Errorf("Unexpected caller field")
+			}
+			if file, _ := m["caller_file"].(string); filepath.Base(file) != "source_test.go" {
+				t.Errorf("Unexpected caller_file %v", m["caller_file"])
+			}
+			if line, ok := m["caller_line"].(float64); !ok || line <= 0 {
+				t.Errorf("caller_line must be a positive number, got %v", m["caller_line"])
+			}
+			if fn, _ := m["caller_func"].(string); !strings.HasPrefix(fn, "github.com/phsym/zeroslog.TestSourceFormat") {
+				t.Errorf("Unexpected caller_func %v", m["caller_func"])
+			}
+		},
+	} {
+		t.Run(fmt.Sprint(name), func(t *testing.T) {
+			for _, group := range []bool{false, true} {
+				out := bytes.Buffer{}
+				var hdl slog.Handler = NewJsonHandler(&out, &HandlerOptions{AddSource: true, SourceFormat: name})
+				if group {
+					hdl = hdl.WithGroup("g")
+				}
+				slog.New(hdl).Info("foobar")
+				m := map[string]any{}
+				if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+					t.Fatalf("Failed to json decode log output: %s", err.Error())
+				}
+				tc(t, m)
+			}
+		})
+	}
+}
+
+func TestAddSource_Allocs(t *testing.T) {
+	pc, _, _, _ := runtime.Caller(0)
+	for name, opts := range map[string]*HandlerOptions{
+		"string": {AddSource: true},
+		"flat":   {AddSource: true, SourceFormat: SourceFlatFields},
+		"skip":   {AddSource: true, SourceSkipPackages: []string{"github.com/phsym/zeroslog/internal/logwrap"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			hdl := NewJsonHandler(io.Discard, opts)
+			rec := slog.NewRecord(now, slog.LevelInfo, "foobar", pc)
+			rec.AddAttrs(slog.String("foo", "bar"))
+			hdl.Handle(context.Background(), rec) // Warm up the cache
+			if n := testing.AllocsPerRun(100, func() { hdl.Handle(context.Background(), rec) }); n > 1 {
+				t.Errorf("Unexpected %v allocations per record", n)
+			}
+		})
+	}
+}
+
+func TestSourceAttr(t *testing.T) {
+	var pcs [1]uintptr
+	runtime.Callers(1, pcs[:])
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	src := &slog.Source{Function: frame.Function, File: frame.File, Line: frame.Line}
+
+	// fields returns the fields of a JSON record whose key starts with prefix, with prefix replaced with "x".
+	fields := func(t *testing.T, out []byte, prefix string) map[string]any {
+		m := map[string]any{}
+		if err := json.Unmarshal(out, &m); err != nil {
+			t.Fatal(err)
+		}
+		if g, ok := m["g"].(map[string]any); ok {
+			m = g
+		}
+		found := map[string]any{}
+		for k, v := range m {
+			if strings.HasPrefix(k, prefix) {
+				found["x"+strings.TrimPrefix(k, prefix)] = v
+			}
+		}
+		return found
+	}
+	for _, format := range []SourceFormat{SourceString, SourceObject, SourceFlatFields} {
+		t.Run(fmt.Sprint(format), func(t *testing.T) {
+			opts := &HandlerOptions{AddSource: true, SourceFormat: format}
+			out := bytes.Buffer{}
+			_ = NewJsonHandler(&out, opts).Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "msg", pcs[0]))
+			exp := fields(t, out.Bytes(), "caller")
+			if len(exp) == 0 {
+				t.Fatalf("Missing source in %s", out.String())
+			}
+
+			opts.AddSource = false
+			for name, attr := range map[string]slog.Attr{
+				"Pointer": slog.Any("src", src),
+				"Value":   slog.Any("src", *src),
+				"Group":   slog.Group("g", slog.Any("src", src)),
+			} {
+				out.Reset()
+				slog.New(NewJsonHandler(&out, opts)).LogAttrs(context.Background(), slog.LevelInfo, "msg", attr)
+				if got := fields(t, out.Bytes(), "src"); !jsonEqual(got, exp) {
+					t.Errorf("%s: expected the source written like %v, got %s", name, exp, out.String())
+				}
+			}
+		})
+	}
+}
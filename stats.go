@@ -0,0 +1,125 @@
+package zeroslog
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// LatencyBuckets are the upper bounds of the buckets of the latency histogram reported in Stats.
+// They grow exponentially from 1µs to about 0.5s. An additional last bucket counts greater latencies.
+var LatencyBuckets = func() []time.Duration {
+	buckets := make([]time.Duration, 20)
+	for i := range buckets {
+		buckets[i] = time.Microsecond << i
+	}
+	return buckets
+}()
+
+// Stats holds counters about the records processed by a handler.
+// Counters are shared between a handler and all the handlers derived from it.
+type Stats struct {
+	// Emitted is the number of records sent to the zerolog logger.
+	Emitted uint64
+	// Dropped is the number of records the handler dropped after emission started,
+	// or because of DropMessages, OnlyMessages and AttrLevelOverrides.
+	Dropped uint64
+	// TimedOut is the number of records dropped because of WriteTimeout.
+	TimedOut uint64
+	// Suppressed is the number of records suppressed by SuppressRepeats.
+	Suppressed uint64
+	// DroppedAttrs is the number of attributes dropped because they are not in AllowKeys.
+	DroppedAttrs uint64
+	// OverflowKeys is the number of attributes rewritten to an OverflowKey field because of MaxUniqueKeys.
+	OverflowKeys uint64
+	// InvalidUTF8 is the number of strings whose invalid UTF-8 sequences were replaced because of ValidateUTF8.
+	InvalidUTF8 uint64
+	// Errors is the number of errors reported to OnError.
+	Errors uint64
+	// Latency is the histogram of the time spent in Handle when MeasureLatency is set, and nil otherwise.
+	// Latency[i] is the number of records handled in at most LatencyBuckets[i], and more than the previous
+	// bucket bound. The last element counts records handled in more than the last bucket bound.
+	Latency []uint64
+}
+
+// stats holds the live counters backing Stats.
+type stats struct {
+	emitted      atomic.Uint64
+	dropped      atomic.Uint64
+	timedOut     atomic.Uint64
+	suppressed   atomic.Uint64
+	droppedAttrs atomic.Uint64
+	overflowKeys atomic.Uint64
+	invalidUTF8  atomic.Uint64
+	errors       atomic.Uint64
+	// latency is nil unless latency measurement is enabled.
+	latency []atomic.Uint64
+}
+
+// newStats creates the counters for a root handler, with latency measurement if measureLatency is true.
+func newStats(measureLatency bool) *stats {
+	s := new(stats)
+	if measureLatency {
+		s.latency = make([]atomic.Uint64, len(LatencyBuckets)+1)
+	}
+	return s
+}
+
+// observeSince records the time elapsed since start into the latency histogram.
+func (s *stats) observeSince(start time.Time) {
+	elapsed := time.Since(start)
+	i := 0
+	for i < len(LatencyBuckets) && elapsed > LatencyBuckets[i] {
+		i++
+	}
+	s.latency[i].Add(1)
+}
+
+// latencySnapshot returns a copy of the latency histogram, or nil if not measured.
+func (s *stats) latencySnapshot() []uint64 {
+	if s.latency == nil {
+		return nil
+	}
+	hist := make([]uint64, len(s.latency))
+	for i := range s.latency {
+		hist[i] = s.latency[i].Load()
+	}
+	return hist
+}
+
+// snapshot returns a copy of the current counter values.
+func (s *stats) snapshot() Stats {
+	return Stats{
+		Emitted:      s.emitted.Load(),
+		Dropped:      s.dropped.Load(),
+		TimedOut:     s.timedOut.Load(),
+		Suppressed:   s.suppressed.Load(),
+		DroppedAttrs: s.droppedAttrs.Load(),
+		OverflowKeys: s.overflowKeys.Load(),
+		InvalidUTF8:  s.invalidUTF8.Load(),
+		Errors:       s.errors.Load(),
+		Latency:      s.latencySnapshot(),
+	}
+}
+
+// Stats returns a snapshot of the handler counters.
+func (h *Handler) Stats() Stats {
+	return h.counterStats().snapshot()
+}
+
+// reportError counts err and forwards it to opts.OnError if set.
+func (h *Handler) reportError(err error) {
+	if h.isZero() {
+		return
+	}
+	h.stats.errors.Add(1)
+	if h.opts.OnError != nil {
+		h.opts.OnError(err)
+	}
+}
+
+// reportDrop counts a record dropped because of a write timeout, and reports err.
+func (h *Handler) reportDrop(err error) {
+	h.stats.dropped.Add(1)
+	h.stats.timedOut.Add(1)
+	h.reportError(err)
+}
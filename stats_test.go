@@ -0,0 +1,49 @@
+package zeroslog
+
+import (
+	"context"
+	"expvar"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+func TestMeasureLatency(t *testing.T) {
+	hdl := NewJsonHandler(io.Discard, &HandlerOptions{MeasureLatency: true})
+	prefix := expvarPrefix("zeroslog_test_latency")
+	hdl.PublishExpvar(prefix)
+	grouped := hdl.WithGroup("g")
+	for i := 0; i < 10; i++ {
+		hdl.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "foobar", 0))
+		grouped.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "foobar", 0))
+	}
+
+	st := hdl.Stats()
+	if len(st.Latency) != len(LatencyBuckets)+1 {
+		t.Fatalf("Unexpected histogram %v", st.Latency)
+	}
+	total := uint64(0)
+	for _, n := range st.Latency {
+		total += n
+	}
+	if total != 20 {
+		t.Fatalf("Histogram counts %d records, expected 20", total)
+	}
+
+	published := expvar.Get(prefix + ".latency").(expvar.Func).Value().(map[string]uint64)
+	total = 0
+	for _, n := range published {
+		total += n
+	}
+	if total != 20 || len(published) != len(st.Latency) {
+		t.Fatalf("Unexpected published histogram %v", published)
+	}
+}
+
+func TestMeasureLatency_Off(t *testing.T) {
+	hdl := NewJsonHandler(io.Discard, nil)
+	hdl.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "foobar", 0))
+	if st := hdl.Stats(); st.Latency != nil {
+		t.Fatalf("Unexpected histogram %v", st.Latency)
+	}
+}
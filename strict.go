@@ -0,0 +1,101 @@
+package zeroslog
+
+import (
+	"log/slog"
+	"slices"
+)
+
+// mergesAttrs reports whether the attributes added with WithAttrs are written with the ones of the records,
+// instead of being encoded into the context of the logger, with StrictSlogCompliance, DedupKeys, or
+// LogstashOptions.AttrsKey.
+func (opts *HandlerOptions) mergesAttrs() bool {
+	return opts.StrictSlogCompliance || opts.DedupKeys || opts.LogstashFormat.attrsKey() != ""
+}
+
+// strictAttrs returns the attributes to write at one level of a record when mergesAttrs is true:
+// the attributes added with WithAttrs at that level, followed by the record attributes recAttrs,
+// whose group path is prefix, normalized with normalizeAttrs with StrictSlogCompliance, or only
+// deduplicated with dedupAttrs with DedupKeys. The first n record attributes are the record's own,
+// and the following ones come from LevelAttrs.
+// child is the name of the group written after the attributes, if any, which wins over attributes with the same key.
+func (h *Handler) strictAttrs(pending pendingAttrs, prefix string, recAttrs []slog.Attr, n int, child string) []slog.Attr {
+	fields := pending.collect(nil)
+	var over overflowAttrs
+	for i, a := range recAttrs {
+		if a, ok := h.pipe.attr(prefix, a); ok && !h.pipe.overflow(&over, a) {
+			fields = append(fields, h.tag(a, recordOrigin(i, n)))
+		}
+	}
+	if a, ok := over.attr(); ok {
+		fields = append(fields, h.tag(a, originRecord))
+	}
+	if h.opts.StrictSlogCompliance {
+		fields = normalizeAttrs(fields)
+	} else if h.opts.DedupKeys {
+		fields = dedupAttrs(fields)
+	}
+	if child != "" {
+		fields = slices.DeleteFunc(fields, func(a slog.Attr) bool { return a.Key == child })
+	}
+	return fields
+}
+
+// normalizeAttrs normalizes resolved attributes according to the slog.Handler rules:
+// empty attributes are removed, the members of groups with an empty key are inlined,
+// empty groups are removed, and only the last of attributes sharing the same key is kept.
+// Groups members are normalized recursively.
+func normalizeAttrs(attrs []slog.Attr) []slog.Attr {
+	flat := make([]slog.Attr, 0, len(attrs))
+	flat = appendNormalized(flat, attrs)
+	return lastByKey(flat)
+}
+
+// dedupAttrs returns resolved attributes where only the last of the ones sharing the same key is kept,
+// at its position, recursively in groups, for DedupKeys. Groups with an empty key are deduplicated like
+// the other attributes, as they're not inlined.
+func dedupAttrs(attrs []slog.Attr) []slog.Attr {
+	kept := lastByKey(attrs)
+	for i, a := range kept {
+		if a.Value.Kind() == slog.KindGroup {
+			kept[i].Value = slog.GroupValue(dedupAttrs(a.Value.Group())...)
+		}
+	}
+	return kept
+}
+
+// lastByKey returns a copy of attrs where only the last of the attributes sharing the same key is kept,
+// at its position.
+func lastByKey(attrs []slog.Attr) []slog.Attr {
+	seen := make(map[string]struct{}, len(attrs))
+	kept := attrs[:0:0]
+	for i := len(attrs) - 1; i >= 0; i-- {
+		if _, ok := seen[attrs[i].Key]; ok {
+			continue
+		}
+		seen[attrs[i].Key] = struct{}{}
+		kept = append(kept, attrs[i])
+	}
+	slices.Reverse(kept)
+	return kept
+}
+
+// appendNormalized appends attrs to dst, removing empty attributes and groups and inlining groups with an empty key.
+func appendNormalized(dst []slog.Attr, attrs []slog.Attr) []slog.Attr {
+	for _, a := range attrs {
+		if a.Equal(slog.Attr{}) {
+			continue
+		}
+		if a.Value.Kind() != slog.KindGroup {
+			dst = append(dst, a)
+			continue
+		}
+		if a.Key == "" {
+			dst = appendNormalized(dst, a.Value.Group())
+			continue
+		}
+		if members := normalizeAttrs(a.Value.Group()); len(members) > 0 {
+			dst = append(dst, slog.Attr{Key: a.Key, Value: slog.GroupValue(members...)})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,197 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+	"testing/slogtest"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// withSlogFieldNames sets zerolog's field names to the slog ones for the duration of the test.
+func withSlogFieldNames(t *testing.T) {
+	prevTime, prevLevel, prevMsg := zerolog.TimestampFieldName, zerolog.LevelFieldName, zerolog.MessageFieldName
+	zerolog.TimestampFieldName, zerolog.LevelFieldName, zerolog.MessageFieldName = slog.TimeKey, slog.LevelKey, slog.MessageKey
+	t.Cleanup(func() {
+		zerolog.TimestampFieldName, zerolog.LevelFieldName, zerolog.MessageFieldName = prevTime, prevLevel, prevMsg
+	})
+}
+
+func TestStrictSlogCompliance_Slogtest(t *testing.T) {
+	withSlogFieldNames(t)
+	for name, newHandler := range map[string]func(io.Writer, *HandlerOptions) slog.Handler{
+		"json":    func(w io.Writer, o *HandlerOptions) slog.Handler { return NewJsonHandler(w, o) },
+		"handler": func(w io.Writer, o *HandlerOptions) slog.Handler { return NewHandler(zerolog.New(w), o) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			out := bytes.Buffer{}
+			hdl := newHandler(&out, &HandlerOptions{Level: slog.LevelDebug, StrictSlogCompliance: true})
+			err := slogtest.TestHandler(hdl, func() []map[string]any {
+				dec := json.NewDecoder(&out)
+				results := []map[string]any{}
+				for {
+					m := map[string]any{}
+					if dec.Decode(&m) != nil {
+						return results
+					}
+					results = append(results, m)
+				}
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+// strictCases are conformance cases in the spirit of the go-slog test suite,
+// each logging one record with a derived handler, and the expected output without the envelope fields.
+var strictCases = map[string]struct {
+	derive func(slog.Handler) slog.Handler
+	attrs  []slog.Attr
+	exp    string
+}{
+	"duplicate-keys": {
+		attrs: []slog.Attr{slog.Int("a", 1), slog.Int("b", 2), slog.Int("a", 3)},
+		exp:   `{"b":2,"a":3}`,
+	},
+	"duplicate-with-attrs": {
+		derive: func(h slog.Handler) slog.Handler { return h.WithAttrs([]slog.Attr{slog.Int("a", 1), slog.Int("c", 0)}) },
+		attrs:  []slog.Attr{slog.Int("a", 2)},
+		exp:    `{"c":0,"a":2}`,
+	},
+	"duplicate-in-group": {
+		derive: func(h slog.Handler) slog.Handler {
+			return h.WithGroup("g").WithAttrs([]slog.Attr{slog.Int("a", 1)})
+		},
+		attrs: []slog.Attr{slog.Int("a", 2), slog.Group("sub", slog.Int("x", 1), slog.Int("x", 2))},
+		exp:   `{"g":{"a":2,"sub":{"x":2}}}`,
+	},
+	"group-over-attr": {
+		derive: func(h slog.Handler) slog.Handler {
+			return h.WithAttrs([]slog.Attr{slog.Int("g", 1), slog.Int("b", 1)}).WithGroup("g")
+		},
+		attrs: []slog.Attr{slog.Int("a", 2)},
+		exp:   `{"b":1,"g":{"a":2}}`,
+	},
+	"empty-attr": {
+		attrs: []slog.Attr{{}, slog.Int("a", 1), slog.Any("", nil)},
+		exp:   `{"a":1}`,
+	},
+	"empty-group": {
+		attrs: []slog.Attr{slog.Group("empty"), slog.Group("nested", slog.Group("empty")), slog.Int("a", 1)},
+		exp:   `{"a":1}`,
+	},
+	"empty-handler-groups": {
+		derive: func(h slog.Handler) slog.Handler {
+			return h.WithAttrs([]slog.Attr{slog.Int("a", 1)}).WithGroup("g").WithGroup("h")
+		},
+		exp: `{"a":1}`,
+	},
+	"empty-inner-handler-group": {
+		derive: func(h slog.Handler) slog.Handler {
+			return h.WithGroup("g").WithAttrs([]slog.Attr{slog.Int("a", 1)}).WithGroup("h")
+		},
+		exp: `{"g":{"a":1}}`,
+	},
+	"inline-group": {
+		attrs: []slog.Attr{slog.Group("", slog.Int("a", 1), slog.Group("", slog.Int("b", 2)))},
+		exp:   `{"a":1,"b":2}`,
+	},
+	"empty-group-name": {
+		derive: func(h slog.Handler) slog.Handler { return h.WithGroup("").WithAttrs([]slog.Attr{slog.Int("a", 1)}) },
+		attrs:  []slog.Attr{slog.Int("b", 2)},
+		exp:    `{"a":1,"b":2}`,
+	},
+}
+
+func TestStrictSlogCompliance_Cases(t *testing.T) {
+	for name, tc := range strictCases {
+		t.Run(name, func(t *testing.T) {
+			out := bytes.Buffer{}
+			hdl := NewHandler(zerolog.New(&out), &HandlerOptions{StrictSlogCompliance: true, OmitLevel: true})
+			var h slog.Handler = hdl
+			if tc.derive != nil {
+				h = tc.derive(h)
+			}
+			rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "", 0)
+			rec.AddAttrs(tc.attrs...)
+			if err := h.Handle(context.Background(), rec); err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			m := map[string]any{}
+			if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+				t.Fatalf("Failed to json decode log output: %s", err.Error())
+			}
+			delete(m, zerolog.MessageFieldName)
+			got, _ := json.Marshal(m)
+			var exp map[string]any
+			json.Unmarshal([]byte(tc.exp), &exp)
+			want, _ := json.Marshal(exp)
+			if !bytes.Equal(got, want) {
+				t.Fatalf("Unexpected output %s, expected %s", out.String(), tc.exp)
+			}
+			if bytes.Count(out.Bytes(), []byte(`"a":`)) > 1 {
+				t.Fatalf("Duplicate keys in output %s", out.String())
+			}
+		})
+	}
+}
+
+func TestDedupKeys(t *testing.T) {
+	for name, tc := range map[string]struct {
+		derive func(*slog.Logger) *slog.Logger
+		attrs  []any
+		exp    string
+	}{
+		"with attrs": {
+			derive: func(l *slog.Logger) *slog.Logger { return l.With("a", 1).With("a", 2) },
+			attrs:  []any{"a", 3},
+			exp:    `{"level":"info","a":3,"message":"msg"}`,
+		},
+		"last position": {
+			derive: func(l *slog.Logger) *slog.Logger { return l.With("a", 1, "b", 1) },
+			attrs:  []any{"c", 2, "a", 3},
+			exp:    `{"level":"info","b":1,"c":2,"a":3,"message":"msg"}`,
+		},
+		"groups": {
+			derive: func(l *slog.Logger) *slog.Logger { return l.With("a", 1).WithGroup("g").With("x", 1, "y", 1) },
+			attrs:  []any{"x", 2, "x", 3},
+			exp:    `{"level":"info","a":1,"g":{"y":1,"x":3},"message":"msg"}`,
+		},
+		"group name": {
+			derive: func(l *slog.Logger) *slog.Logger { return l.With("g", 1).WithGroup("g") },
+			attrs:  []any{"y", 1},
+			exp:    `{"level":"info","g":{"y":1},"message":"msg"}`,
+		},
+		"group members": {
+			derive: func(l *slog.Logger) *slog.Logger { return l },
+			attrs:  []any{slog.Group("h", "a", 1, "a", 2), slog.Group("i", "a", 1), slog.Group("i", "b", 1, "b", 2)},
+			exp:    `{"level":"info","h":{"a":2},"i":{"b":2},"message":"msg"}`,
+		},
+	} {
+		out := bytes.Buffer{}
+		var errs []error
+		l := tc.derive(slog.New(NewHandler(zerolog.New(&out), &HandlerOptions{
+			DedupKeys:           true,
+			WarnOnDuplicateKeys: true,
+			OnError:             func(err error) { errs = append(errs, err) },
+		})))
+		rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "msg", 0)
+		rec.Add(tc.attrs...)
+		if err := l.Handler().Handle(context.Background(), rec); err != nil {
+			t.Fatal(err)
+		}
+		if got := out.String(); got != tc.exp+"\n" {
+			t.Errorf("%s: unexpected output %s, expected %s", name, got, tc.exp)
+		}
+		if len(errs) > 0 {
+			t.Errorf("%s: unexpected errors %v", name, errs)
+		}
+	}
+}
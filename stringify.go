@@ -0,0 +1,72 @@
+package zeroslog
+
+import (
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// stringifyStage returns the pipeline stage implementing StringifyValues.
+func stringifyStage(keys keyMatcher) attrStage {
+	var apply func(prefix string, a slog.Attr, matched bool) slog.Attr
+	// apply converts a if it matches, or if matched is true because a parent group already matched.
+	apply = func(prefix string, a slog.Attr, matched bool) slog.Attr {
+		key := prefix + a.Key
+		matched = matched || keys.match(key)
+		a.Value = a.Value.Resolve()
+		if a.Value.Kind() != slog.KindGroup {
+			if matched {
+				a.Value = slog.StringValue(valueString(a.Value))
+			}
+			return a
+		}
+		if a.Key != "" {
+			prefix = key + "."
+		}
+		group := a.Value.Group()
+		members := make([]slog.Attr, len(group))
+		for i, m := range group {
+			members[i] = apply(prefix, m, matched)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(members...)}
+	}
+	return func(prefix string, a slog.Attr) (slog.Attr, bool) {
+		return apply(prefix, a, false), true
+	}
+}
+
+// valueString returns the string representation of v written with StringifyValues: the text of the JSON value
+// written without it for numbers, booleans and times, and the string form of the value for other kinds.
+func valueString(v slog.Value) string {
+	switch v.Kind() {
+	case slog.KindFloat64:
+		return strconv.FormatFloat(v.Float64(), 'f', -1, 64)
+	case slog.KindTime:
+		return timeString(v.Time())
+	case slog.KindDuration:
+		if zerolog.DurationFieldInteger {
+			return strconv.FormatInt(int64(v.Duration()/zerolog.DurationFieldUnit), 10)
+		}
+		return strconv.FormatFloat(float64(v.Duration())/float64(zerolog.DurationFieldUnit), 'f', -1, 64)
+	default:
+		return v.String()
+	}
+}
+
+// timeString formats t like zerolog does, according to zerolog.TimeFieldFormat.
+func timeString(t time.Time) string {
+	switch zerolog.TimeFieldFormat {
+	case zerolog.TimeFormatUnix:
+		return strconv.FormatInt(t.Unix(), 10)
+	case zerolog.TimeFormatUnixMs:
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	case zerolog.TimeFormatUnixMicro:
+		return strconv.FormatInt(t.UnixMicro(), 10)
+	case zerolog.TimeFormatUnixNano:
+		return strconv.FormatInt(t.UnixNano(), 10)
+	default:
+		return t.Format(zerolog.TimeFieldFormat)
+	}
+}
@@ -0,0 +1,61 @@
+package zeroslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestStringifyValues(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	out := bytes.Buffer{}
+	logger := slog.New(NewJsonHandler(&out, &HandlerOptions{
+		StringifyValues: []string{"req.id", "req.ok", "req.ratio", "req.at", "req.t*", "req.size", "ctx_id", "req.meta"},
+	}))
+	logger.With("ctx_id", 7, "ctx_n", 7).WithGroup("req").With("size", 12).Info("msg",
+		"id", 42, "ok", true, "ratio", 0.25, "at", at, "took", 1500*time.Millisecond,
+		"n", 42, "flag", true, "f", 0.5,
+		slog.Group("meta", "version", 3, slog.Group("inner", "retry", false)),
+	)
+
+	var m map[string]any
+	if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	exp := map[string]any{
+		"size":  "12",
+		"id":    "42",
+		"ok":    "true",
+		"ratio": "0.25",
+		"at":    at.Format(zerolog.TimeFieldFormat),
+		"took":  "1500",
+		"n":     42.0,
+		"flag":  true,
+		"f":     0.5,
+		"meta":  map[string]any{"version": "3", "inner": map[string]any{"retry": "false"}},
+	}
+	if !reflect.DeepEqual(m["req"], exp) {
+		t.Errorf("Got req %v, expected %v", m["req"], exp)
+	}
+	if m["ctx_id"] != "7" || m["ctx_n"] != 7.0 {
+		t.Errorf("Unexpected context attributes in %s", out.String())
+	}
+}
+
+func TestStringifyValues_TopLevel(t *testing.T) {
+	out := bytes.Buffer{}
+	slog.New(NewJsonHandler(&out, &HandlerOptions{StringifyValues: []string{"id", "g.ok"}})).
+		Info("msg", "id", 42, "other", 1, slog.Group("g", "ok", true, "n", 1))
+	var m map[string]any
+	if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["id"] != "42" || m["other"] != 1.0 || !reflect.DeepEqual(m["g"], map[string]any{"ok": "true", "n": 1.0}) {
+		t.Errorf("Unexpected record %s", out.String())
+	}
+}
@@ -0,0 +1,179 @@
+package zeroslog
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// minSuppressPrune is the number of tracked messages above which stale ones are pruned when a new one is seen.
+const minSuppressPrune = 1024
+
+// suppressKey identifies records considered as repeats of each other.
+type suppressKey struct {
+	level   slog.Level
+	message string
+}
+
+// suppression tracks the records of a suppressKey.
+type suppression struct {
+	// lastEmitted is the time of the last emitted record.
+	lastEmitted time.Time
+	// count is the number of records suppressed since the last summary.
+	count       int
+	first, last time.Time
+}
+
+// suppressor suppresses repeated records, and periodically summarizes them.
+// It is shared between a root handler and the handlers derived from it.
+type suppressor struct {
+	root   *Handler
+	window time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[suppressKey]*suppression
+	// pruneAt is the number of entries above which stale ones are pruned on insertion.
+	pruneAt int
+
+	start sync.Once
+	stop  sync.Once
+	done  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// newSuppressor creates a suppressor for the root handler h.
+func newSuppressor(h *Handler) *suppressor {
+	return &suppressor{
+		root:    h,
+		window:  h.opts.SuppressRepeats,
+		now:     time.Now,
+		entries: make(map[suppressKey]*suppression),
+		pruneAt: minSuppressPrune,
+		done:    make(chan struct{}),
+	}
+}
+
+// suppressed reports whether rec must be suppressed, and accounts for it.
+// It is safe to call on a nil suppressor, which never suppresses anything.
+func (s *suppressor) suppressed(rec *slog.Record) bool {
+	if s == nil {
+		return false
+	}
+	key := suppressKey{rec.Level, rec.Message}
+	t := rec.Time
+	if t.IsZero() {
+		// Records may have no time, repeats are then timed on arrival.
+		t = s.now()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok {
+		if len(s.entries) >= s.pruneAt {
+			s.prune(t)
+		}
+		e = &suppression{}
+		s.entries[key] = e
+	}
+	if !ok || t.Sub(e.lastEmitted) >= s.window {
+		e.lastEmitted = t
+		return false
+	}
+	if e.count == 0 {
+		e.first = t
+	}
+	e.count++
+	e.last = t
+	s.root.stats.suppressed.Add(1)
+	if s.root.opts.SummaryInterval > 0 {
+		s.start.Do(s.startTicker)
+	}
+	return true
+}
+
+// prune forgets about messages which have not been seen for a whole window before now, unless they have
+// suppressed records waiting for a summary, so that distinct messages don't accumulate between summaries,
+// or forever without SummaryInterval. It's called with s.mu held, and amortized by doubling the next threshold.
+func (s *suppressor) prune(now time.Time) {
+	summarized := s.root.opts.SummaryInterval > 0
+	for key, e := range s.entries {
+		if e.count > 0 && summarized {
+			continue
+		}
+		if now.Sub(e.lastEmitted) >= s.window && now.Sub(e.last) >= s.window {
+			delete(s.entries, key)
+		}
+	}
+	s.pruneAt = max(2*len(s.entries), minSuppressPrune)
+}
+
+// startTicker starts the goroutine emitting summaries every SummaryInterval.
+func (s *suppressor) startTicker() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.root.opts.SummaryInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.summarize()
+			case <-s.done:
+				return
+			}
+		}
+	}()
+}
+
+// summarize emits a summary record for each level and message having suppressed records,
+// and forgets about messages which have not been seen for a whole window.
+func (s *suppressor) summarize() {
+	now := s.now()
+	s.mu.Lock()
+	var summaries []slog.Record
+	for key, e := range s.entries {
+		if e.count == 0 {
+			if now.Sub(e.lastEmitted) >= s.window {
+				delete(s.entries, key)
+			}
+			continue
+		}
+		rec := slog.NewRecord(now, key.level, "suppressed repeated records", 0)
+		rec.AddAttrs(
+			slog.String("suppressed_message", key.message),
+			slog.Int("suppressed_count", e.count),
+			slog.Time("suppressed_first", e.first),
+			slog.Time("suppressed_last", e.last),
+		)
+		summaries = append(summaries, rec)
+		e.count = 0
+	}
+	s.mu.Unlock()
+	for _, rec := range summaries {
+		s.root.emit(context.Background(), &rec)
+	}
+}
+
+// close stops the summary goroutine and emits the pending summaries.
+// It is safe to call on a nil suppressor.
+func (s *suppressor) close() {
+	if s == nil {
+		return
+	}
+	s.stop.Do(func() {
+		close(s.done)
+		s.wg.Wait()
+		if s.root.opts.SummaryInterval > 0 {
+			s.summarize()
+		}
+	})
+}
+
+// Close releases the resources held by the handler, and flushes pending suppression summaries.
+// Handlers derived from h must not be used after Close.
+func (h *Handler) Close() error {
+	h.suppress.close()
+	return nil
+}
@@ -0,0 +1,115 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func decodeAll(t *testing.T, r io.Reader) []map[string]any {
+	t.Helper()
+	results := []map[string]any{}
+	ScanRecords(r)(func(m map[string]any, err error) bool {
+		if err != nil {
+			t.Fatalf("Failed to json decode log output: %s", err.Error())
+		}
+		results = append(results, m)
+		return true
+	})
+	return results
+}
+
+func TestSuppressRepeats(t *testing.T) {
+	out := bytes.Buffer{}
+	hdl := NewJsonHandler(&out, &HandlerOptions{SuppressRepeats: time.Minute, SummaryInterval: time.Hour})
+	clock := now
+	hdl.suppress.now = func() time.Time { return clock }
+	grouped := hdl.WithGroup("g")
+
+	start := now
+	for i := 0; i < 10; i++ {
+		rec := slog.NewRecord(start.Add(time.Duration(i)*time.Second), slog.LevelWarn, "connection refused", 0)
+		grouped.Handle(context.Background(), rec)
+	}
+	hdl.Handle(context.Background(), slog.NewRecord(start, slog.LevelInfo, "other", 0))
+	hdl.Handle(context.Background(), slog.NewRecord(start.Add(2*time.Minute), slog.LevelWarn, "connection refused", 0))
+
+	if records := decodeAll(t, &out); len(records) != 3 {
+		t.Fatalf("Expected 3 records, got %v", records)
+	}
+	if st := hdl.Stats(); st.Suppressed != 9 {
+		t.Fatalf("Unexpected stats %+v", st)
+	}
+
+	clock = now.Add(3 * time.Minute)
+	hdl.suppress.summarize()
+	summaries := decodeAll(t, &out)
+	if len(summaries) != 1 {
+		t.Fatalf("Expected one summary, got %v", summaries)
+	}
+	sum := summaries[0]
+	if sum["suppressed_message"] != "connection refused" || sum["suppressed_count"] != 9.0 || sum["level"] != "warn" {
+		t.Fatalf("Unexpected summary %v", sum)
+	}
+	if sum["suppressed_first"] != start.Add(time.Second).Format(time.RFC3339) || sum["suppressed_last"] != start.Add(9*time.Second).Format(time.RFC3339) {
+		t.Fatalf("Unexpected summary timestamps %v", sum)
+	}
+	if sum["time"] != clock.Format(time.RFC3339) {
+		t.Fatalf("Unexpected summary time %v", sum)
+	}
+
+	hdl.suppress.summarize()
+	if out.Len() > 0 {
+		t.Fatalf("Unexpected summary without suppressed records: %q", out.String())
+	}
+}
+
+func TestSuppressRepeats_Close(t *testing.T) {
+	out := bytes.Buffer{}
+	hdl := NewJsonHandler(&out, &HandlerOptions{SuppressRepeats: time.Minute, SummaryInterval: time.Hour})
+	for i := 0; i < 3; i++ {
+		hdl.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "repeated", 0))
+	}
+	hdl.Close()
+	hdl.Close()
+	records := decodeAll(t, &out)
+	if len(records) != 2 || records[1]["suppressed_count"] != 2.0 {
+		t.Fatalf("Unexpected records %v", records)
+	}
+}
+
+func TestSuppressRepeats_ZeroTime(t *testing.T) {
+	out := bytes.Buffer{}
+	hdl := NewJsonHandler(&out, &HandlerOptions{SuppressRepeats: time.Minute})
+	clock := now
+	hdl.suppress.now = func() time.Time { return clock }
+	for i := 0; i < 3; i++ {
+		hdl.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "no time", 0))
+		clock = clock.Add(40 * time.Second)
+	}
+	if records := decodeAll(t, &out); len(records) != 2 {
+		t.Fatalf("Expected the records without time to be suppressed within the window only, got %v", records)
+	}
+}
+
+func TestSuppressRepeats_Prune(t *testing.T) {
+	hdl := NewJsonHandler(io.Discard, &HandlerOptions{SuppressRepeats: time.Second})
+	for i := 0; i < 10*minSuppressPrune; i++ {
+		rec := slog.NewRecord(now.Add(time.Duration(i)*time.Millisecond), slog.LevelInfo, fmt.Sprintf("message %d", i), 0)
+		hdl.Handle(context.Background(), rec)
+		if i%2 == 0 {
+			// Suppressed repeats are forgotten too without SummaryInterval.
+			hdl.Handle(context.Background(), rec)
+		}
+	}
+	if n := len(hdl.suppress.entries); n > 2*minSuppressPrune {
+		t.Errorf("Expected stale messages to be pruned, got %d tracked messages", n)
+	}
+	if st := hdl.Stats(); st.Suppressed != 5*minSuppressPrune {
+		t.Errorf("Unexpected stats %+v", st)
+	}
+}
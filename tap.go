@@ -0,0 +1,104 @@
+package zeroslog
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// MemoryTapHandler is an slog.Handler forwarding records to an inner handler, while keeping
+// the JSON rendering of the most recent ones in memory, for instance for a debug endpoint.
+type MemoryTapHandler struct {
+	inner  slog.Handler
+	render slog.Handler
+	ring   *ring
+}
+
+var _ slog.Handler = (*MemoryTapHandler)(nil)
+
+// NewMemoryTapHandler creates a handler forwarding records to inner, and keeping the last n
+// records enabled by inner, rendered as JSON lines. Derived handlers share the same records.
+func NewMemoryTapHandler(inner slog.Handler, n int) *MemoryTapHandler {
+	r := &ring{lines: make([][]byte, max(n, 1))}
+	return &MemoryTapHandler{
+		inner:  inner,
+		render: NewHandler(zerolog.New(r), nil),
+		ring:   r,
+	}
+}
+
+// Snapshot returns a copy of the retained records, from the oldest to the newest.
+func (h *MemoryTapHandler) Snapshot() [][]byte {
+	return h.ring.snapshot()
+}
+
+// Enabled implements slog.Handler.
+func (h *MemoryTapHandler) Enabled(ctx context.Context, lvl slog.Level) bool {
+	return h.inner.Enabled(ctx, lvl)
+}
+
+// Handle implements slog.Handler.
+func (h *MemoryTapHandler) Handle(ctx context.Context, rec slog.Record) error {
+	if !h.inner.Enabled(ctx, rec.Level) {
+		return nil
+	}
+	_ = h.render.Handle(ctx, rec)
+	return h.inner.Handle(ctx, rec)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *MemoryTapHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &MemoryTapHandler{
+		inner:  h.inner.WithAttrs(attrs),
+		render: h.render.WithAttrs(attrs),
+		ring:   h.ring,
+	}
+}
+
+// WithGroup implements slog.Handler.
+func (h *MemoryTapHandler) WithGroup(name string) slog.Handler {
+	return &MemoryTapHandler{
+		inner:  h.inner.WithGroup(name),
+		render: h.render.WithGroup(name),
+		ring:   h.ring,
+	}
+}
+
+// ring is an io.Writer keeping a copy of the last written lines.
+type ring struct {
+	mu    sync.Mutex
+	lines [][]byte
+	next  int
+	full  bool
+}
+
+// Write implements io.Writer.
+func (r *ring) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Reuse the evicted slot's storage, as it's never exposed outside of the ring.
+	r.lines[r.next] = append(r.lines[r.next][:0], p...)
+	r.next++
+	if r.next == len(r.lines) {
+		r.next = 0
+		r.full = true
+	}
+	return len(p), nil
+}
+
+// snapshot returns a copy of the lines, from the oldest to the newest.
+func (r *ring) snapshot() [][]byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var lines [][]byte
+	if r.full {
+		lines = append(lines, r.lines[r.next:]...)
+	}
+	lines = append(lines, r.lines[:r.next]...)
+	for i, line := range lines {
+		lines[i] = append([]byte(nil), line...)
+	}
+	return lines
+}
@@ -0,0 +1,47 @@
+package zeroslog
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strconv"
+	"testing"
+)
+
+func TestMemoryTapHandler(t *testing.T) {
+	inner := slog.NewJSONHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelInfo})
+	tap := NewMemoryTapHandler(inner, 3)
+	hdl := tap.WithAttrs([]slog.Attr{slog.String("foo", "bar")}).WithGroup("g")
+
+	if snap := tap.Snapshot(); len(snap) != 0 {
+		t.Fatalf("Unexpected records in snapshot %q", snap)
+	}
+	for i := 0; i < 5; i++ {
+		rec := slog.NewRecord(now, slog.LevelInfo, strconv.Itoa(i), 0)
+		rec.AddAttrs(slog.Int("i", i))
+		hdl.Handle(context.Background(), rec)
+		hdl.Handle(context.Background(), slog.NewRecord(now, slog.LevelDebug, "filtered", 0))
+	}
+
+	snap := tap.Snapshot()
+	if len(snap) != 3 {
+		t.Fatalf("Expected 3 records in snapshot, got %d", len(snap))
+	}
+	for i, line := range snap {
+		m := map[string]any{}
+		if err := json.Unmarshal(line, &m); err != nil {
+			t.Fatalf("Failed to json decode log output: %s", err.Error())
+		}
+		if m["message"] != strconv.Itoa(i+2) || m["foo"] != "bar" || m["g"].(map[string]any)["i"] != float64(i+2) {
+			t.Fatalf("Unexpected record %v at position %d", m, i)
+		}
+	}
+
+	// Snapshots must not be affected by later records.
+	snap[0][0] = 'X'
+	hdl.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "5", 0))
+	if snap2 := tap.Snapshot(); snap2[0][0] != '{' || string(snap[1]) != string(snap2[0]) {
+		t.Fatalf("Snapshot shares memory with the handler")
+	}
+}
@@ -0,0 +1,87 @@
+package zeroslog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// ErrWriteTimeout is reported when a record could not be written within HandlerOptions.WriteTimeout.
+var ErrWriteTimeout = errors.New("zeroslog: write timeout")
+
+// deadlineWriter is implemented by writers supporting write deadlines, like net.Conn and *os.File.
+type deadlineWriter interface {
+	SetWriteDeadline(t time.Time) error
+}
+
+// timeoutWriter is an io.Writer enforcing a deadline on every write.
+type timeoutWriter struct {
+	out       io.Writer
+	timeout   time.Duration
+	onTimeout func(error)
+	// pending holds a token while a write runs in a background goroutine.
+	pending chan struct{}
+}
+
+// newTimeoutWriter wraps out so that writes taking longer than timeout are abandoned
+// and reported to onTimeout.
+func newTimeoutWriter(out io.Writer, timeout time.Duration, onTimeout func(error)) *timeoutWriter {
+	return &timeoutWriter{
+		out:       out,
+		timeout:   timeout,
+		onTimeout: onTimeout,
+		pending:   make(chan struct{}, 1),
+	}
+}
+
+// Write implements io.Writer. Timed out writes are reported but never returned as errors,
+// so that zerolog doesn't report them a second time.
+func (w *timeoutWriter) Write(p []byte) (int, error) {
+	if dw, ok := w.out.(deadlineWriter); ok {
+		if err := dw.SetWriteDeadline(time.Now().Add(w.timeout)); err == nil {
+			n, err := w.out.Write(p)
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				w.onTimeout(fmt.Errorf("%w: %w", ErrWriteTimeout, err))
+				return len(p), nil
+			}
+			return n, err
+		}
+	}
+	return w.writeAsync(p)
+}
+
+// writeAsync writes p from a separate goroutine, giving up after the timeout.
+// At most one write is in flight at any time, so a wedged writer doesn't leak goroutines.
+func (w *timeoutWriter) writeAsync(p []byte) (int, error) {
+	timer := time.NewTimer(w.timeout)
+	defer timer.Stop()
+	select {
+	case w.pending <- struct{}{}:
+	case <-timer.C:
+		w.onTimeout(fmt.Errorf("%w: previous write still pending", ErrWriteTimeout))
+		return len(p), nil
+	}
+
+	// zerolog reuses the buffer once Write returns, so the goroutine needs its own copy.
+	buf := append([]byte(nil), p...)
+	type result struct {
+		n   int
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer func() { <-w.pending }()
+		n, err := w.out.Write(buf)
+		done <- result{n, err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.n, res.err
+	case <-timer.C:
+		w.onTimeout(ErrWriteTimeout)
+		return len(p), nil
+	}
+}
@@ -0,0 +1,89 @@
+package zeroslog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingWriter is an io.Writer which never completes until released.
+type blockingWriter struct{ release chan struct{} }
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	return len(p), nil
+}
+
+func TestTimeoutWriter_Blocking(t *testing.T) {
+	w := &blockingWriter{release: make(chan struct{})}
+	defer close(w.release)
+	var mu sync.Mutex
+	var errs []error
+	hdl := NewJsonHandler(w, &HandlerOptions{
+		WriteTimeout: 20 * time.Millisecond,
+		OnError: func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, err)
+		},
+	})
+
+	for i := 0; i < 3; i++ {
+		start := time.Now()
+		if err := hdl.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "foobar", 0)); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Fatalf("Handle blocked for %s", elapsed)
+		}
+	}
+
+	st := hdl.Stats()
+	if st.TimedOut != 3 || st.Dropped != 3 || st.Errors != 3 {
+		t.Fatalf("Unexpected stats %+v", st)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(errs) != 3 {
+		t.Fatalf("Expected 3 reported errors, got %d", len(errs))
+	}
+	for _, err := range errs {
+		if !errors.Is(err, ErrWriteTimeout) {
+			t.Fatalf("Unexpected error %s", err)
+		}
+	}
+}
+
+func TestTimeoutWriter_Deadline(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+	hdl := NewJsonHandler(client, &HandlerOptions{WriteTimeout: 20 * time.Millisecond})
+
+	start := time.Now()
+	hdl.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "foobar", 0))
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Handle blocked for %s", elapsed)
+	}
+	if st := hdl.Stats(); st.TimedOut != 1 {
+		t.Fatalf("Unexpected stats %+v", st)
+	}
+}
+
+func TestTimeoutWriter_Success(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+	hdl := NewJsonHandler(client, &HandlerOptions{WriteTimeout: time.Second})
+
+	go hdl.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "foobar", 0))
+	buf := make([]byte, 1024)
+	n, err := server.Read(buf)
+	if err != nil || n == 0 {
+		t.Fatalf("Failed to read record: %v", err)
+	}
+}
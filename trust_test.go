@@ -0,0 +1,63 @@
+package zeroslog
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestTrustLoggerTimestamps(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		ctx     func(zerolog.Context) zerolog.Context
+		trust   bool
+		time    int
+		callers int
+	}{
+		{"plain", func(c zerolog.Context) zerolog.Context { return c }, true, 1, 1},
+		{"timestamp", zerolog.Context.Timestamp, false, 2, 1},
+		{"timestamp-trusted", zerolog.Context.Timestamp, true, 1, 1},
+		{"caller", zerolog.Context.Caller, false, 1, 2},
+		{"caller-trusted", zerolog.Context.Caller, true, 1, 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			out := bytes.Buffer{}
+			logger := tc.ctx(zerolog.New(&out).With()).Logger()
+			hdl := NewHandler(logger, &HandlerOptions{AddSource: true, TrustLoggerTimestamps: tc.trust})
+			slog.New(hdl).WithGroup("g").Info("hello", "a", 1)
+			if n := strings.Count(out.String(), `"time":`); n != tc.time {
+				t.Fatalf("Unexpected %d time fields in %s", n, out.String())
+			}
+			if n := strings.Count(out.String(), `"caller":`); n != tc.callers {
+				t.Fatalf("Unexpected %d caller fields in %s", n, out.String())
+			}
+		})
+	}
+}
+
+func TestTrustLoggerTimestamps_Hooks(t *testing.T) {
+	out := bytes.Buffer{}
+	runs := 0
+	logger := zerolog.New(&out).With().Str("svc", "api").Str(zerolog.CallerFieldName, "main.go:1").Timestamp().Logger().
+		Hook(zerolog.HookFunc(func(*zerolog.Event, zerolog.Level, string) { runs++ }))
+	hdl := NewHandler(logger, &HandlerOptions{AddSource: true, TrustLoggerTimestamps: true})
+	if !hdl.loggerTime || !hdl.loggerCaller {
+		t.Errorf("Expected the timestamp hook and the caller field to be detected")
+	}
+	if schema := hdl.Schema(); schema["svc"] != schemaString || schema[zerolog.TimestampFieldName] != schemaString {
+		t.Errorf("Expected the context field and the logger's timestamp in the schema, got %v", schema)
+	}
+	if problems := hdl.Check(); len(problems) != 0 {
+		t.Errorf("Expected no problem, got %v", problems)
+	}
+	if runs != 0 {
+		t.Fatalf("Expected probing not to run the logger's hooks, they ran %d times", runs)
+	}
+	slog.New(hdl).Info("hello")
+	if n := strings.Count(out.String(), `"time":`); runs != 1 || n != 1 {
+		t.Errorf("Expected the hook to run once and 1 time field, got %d runs in %s", runs, out.String())
+	}
+}
@@ -0,0 +1,82 @@
+package zeroslog
+
+import (
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Unit is a unit values can be converted to. See HandlerOptions.UnitCoercion.
+type Unit int
+
+// Supported units.
+const (
+	UnitNanoseconds Unit = iota + 1
+	UnitMicroseconds
+	UnitMilliseconds
+	UnitSeconds
+	UnitBytes
+)
+
+// duration returns the duration of one time unit, or 0 if u is not a time unit.
+func (u Unit) duration() time.Duration {
+	switch u {
+	case UnitNanoseconds:
+		return time.Nanosecond
+	case UnitMicroseconds:
+		return time.Microsecond
+	case UnitMilliseconds:
+		return time.Millisecond
+	case UnitSeconds:
+		return time.Second
+	default:
+		return 0
+	}
+}
+
+// unitSuffix associates a key suffix with a unit.
+type unitSuffix struct {
+	suffix string
+	unit   Unit
+}
+
+// unitCoercer converts attribute values according to their key suffix.
+type unitCoercer []unitSuffix
+
+// newUnitCoercer creates a unitCoercer, or nil if units is empty.
+// Longer suffixes are checked first, so that "_ms" wins over "s".
+func newUnitCoercer(units map[string]Unit) unitCoercer {
+	if len(units) == 0 {
+		return nil
+	}
+	c := make(unitCoercer, 0, len(units))
+	for suffix, unit := range units {
+		c = append(c, unitSuffix{suffix, unit})
+	}
+	slices.SortFunc(c, func(a, b unitSuffix) int {
+		if n := len(b.suffix) - len(a.suffix); n != 0 {
+			return n
+		}
+		return strings.Compare(a.suffix, b.suffix)
+	})
+	return c
+}
+
+// coerce converts the value of a, which must be resolved, to the unit matching its key suffix.
+func (c unitCoercer) coerce(a slog.Attr) slog.Attr {
+	for _, us := range c {
+		if !strings.HasSuffix(a.Key, us.suffix) {
+			continue
+		}
+		switch d := us.unit.duration(); {
+		case d > 0 && a.Value.Kind() == slog.KindDuration:
+			return slog.Float64(a.Key, float64(a.Value.Duration())/float64(d))
+		case us.unit == UnitBytes && a.Value.Kind() == slog.KindFloat64:
+			return slog.Int64(a.Key, int64(math.Round(a.Value.Float64())))
+		}
+		return a
+	}
+	return a
+}
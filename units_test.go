@@ -0,0 +1,61 @@
+package zeroslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestUnitCoercion(t *testing.T) {
+	out := bytes.Buffer{}
+	hdl := NewJsonHandler(&out, &HandlerOptions{UnitCoercion: map[string]Unit{
+		"_ms":    UnitMilliseconds,
+		"_s":     UnitSeconds,
+		"s":      UnitNanoseconds,
+		"_bytes": UnitBytes,
+	}}).WithAttrs([]slog.Attr{slog.Duration("ctx_ms", 2*time.Second)}).WithGroup("g")
+
+	rec := slog.NewRecord(now, slog.LevelInfo, "foobar", 0)
+	rec.AddAttrs(
+		slog.Duration("latency_ms", 1500*time.Microsecond),
+		slog.Duration("timeout_s", 2500*time.Millisecond),
+		slog.Duration("others", 3*time.Microsecond),
+		slog.Float64("size_bytes", 1023.6),
+		slog.Int("count_bytes", 12),
+		slog.String("wrong_ms", "12"),
+		slog.Duration("plain", 3*time.Second),
+		slog.Group("sub", slog.Duration("nested_ms", time.Second)),
+	)
+	hdl.Handle(context.Background(), rec)
+
+	m := map[string]any{}
+	if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+		t.Fatalf("Failed to json decode log output: %s", err.Error())
+	}
+	g := m["g"].(map[string]any)
+	for key, exp := range map[string]any{
+		"latency_ms":  1.5,
+		"timeout_s":   2.5,
+		"others":      3000.0,
+		"size_bytes":  1024.0,
+		"count_bytes": 12.0,
+		"wrong_ms":    "12",
+		"plain":       3000.0,
+	} {
+		if g[key] != exp {
+			t.Errorf("Unexpected value %v for %s, expected %v", g[key], key, exp)
+		}
+	}
+	if v := g["sub"].(map[string]any)["nested_ms"]; v != 1000.0 {
+		t.Errorf("Unexpected nested value %v", v)
+	}
+	if m["ctx_ms"] != 2000.0 {
+		t.Errorf("Unexpected context value %v", m["ctx_ms"])
+	}
+	if !bytes.Contains(out.Bytes(), []byte(`"size_bytes":1024,`)) {
+		t.Errorf("Bytes must be written as integers: %s", out.String())
+	}
+}
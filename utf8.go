@@ -0,0 +1,31 @@
+package zeroslog
+
+import (
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+)
+
+// validUTF8 returns s with its invalid UTF-8 sequences replaced with U+FFFD, counting invalid strings in st,
+// if not nil. Valid strings, the common case, are returned as is after a single check.
+func validUTF8(s string, st *stats) string {
+	if utf8.ValidString(s) {
+		return s
+	}
+	if st != nil {
+		st.invalidUTF8.Add(1)
+	}
+	return strings.ToValidUTF8(s, "\uFFFD")
+}
+
+// utf8Stage returns the pipeline stage implementing ValidateUTF8 for string values.
+func utf8Stage(st *stats) attrStage {
+	return leafStage(func(a slog.Attr) slog.Attr {
+		if a.Value.Kind() == slog.KindString {
+			if s := a.Value.String(); !utf8.ValidString(s) {
+				a.Value = slog.StringValue(validUTF8(s, st))
+			}
+		}
+		return a
+	})
+}
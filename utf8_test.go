@@ -0,0 +1,48 @@
+package zeroslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestValidateUTF8(t *testing.T) {
+	tests := []struct {
+		name, value, exp string
+	}{
+		{"Valid", "héllo ✓", "héllo ✓"},
+		{"RawFF", "a\xffb", "a\uFFFDb"},
+		{"Overlong", "a\xc0\xafb", "a\uFFFDb"},
+		{"LoneSurrogate", "a\xed\xa0\x80b", "a\uFFFDb"},
+		{"Truncated", "a\xe2\x9c", "a\uFFFD"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out := bytes.Buffer{}
+			hdl := NewJsonHandler(&out, &HandlerOptions{ValidateUTF8: true})
+			slog.New(hdl).WithGroup("g").Info(test.value, "v", test.value)
+			if !utf8.Valid(out.Bytes()) {
+				t.Fatalf("Invalid UTF-8 output %q", out.String())
+			}
+			var m struct {
+				Message string
+				G       struct{ V string }
+			}
+			if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+				t.Fatal(err)
+			}
+			if m.Message != test.exp || m.G.V != test.exp {
+				t.Errorf("Expected %q, got message %q and value %q", test.exp, m.Message, m.G.V)
+			}
+			invalid := uint64(0)
+			if test.value != test.exp {
+				invalid = 2
+			}
+			if st := hdl.Stats(); st.InvalidUTF8 != invalid {
+				t.Errorf("Expected %d invalid strings, got %d", invalid, st.InvalidUTF8)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package zeroslog
+
+import "context"
+
+// Warmup eagerly performs the initialization handlers defer until the first record is handled, like compiling
+// the DropMessages and OnlyMessages regular expressions, so that it doesn't delay the first records, and returns
+// the configuration errors it finds instead of reporting them to OnError. Handlers work the same without it.
+// It returns the error of ctx if it's done.
+func (h *Handler) Warmup(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return h.messages.compile(false)
+}
@@ -0,0 +1,25 @@
+package zeroslog
+
+// zeroStats holds the counters of zero Handlers, which are never incremented.
+var zeroStats = new(stats)
+
+// isZero reports whether h is a zero Handler, not created with NewHandler or one of the shortcut constructors.
+func (h *Handler) isZero() bool {
+	return h.opts == nil
+}
+
+// counterStats returns the counters of h, or zeroStats for a zero Handler.
+func (h *Handler) counterStats() *stats {
+	if h.stats == nil {
+		return zeroStats
+	}
+	return h.stats
+}
+
+// options returns the options of h, or the default options for a zero Handler.
+func (h *Handler) options() *HandlerOptions {
+	if h.opts == nil {
+		return &HandlerOptions{}
+	}
+	return h.opts
+}
@@ -7,8 +7,9 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"math"
 	"net"
-	"runtime"
+	"slices"
 	"strings"
 	"time"
 
@@ -22,12 +23,326 @@ type HandlerOptions struct {
 	// of the log statement and add a SourceKey attribute to the output.
 	AddSource bool
 
+	// SourceSkipPackages are package paths ignored when computing the source of a record with AddSource,
+	// like the ones of logging wrappers. When the record is logged from one of these packages or their sub-packages,
+	// the first caller outside of them is reported instead. This is only possible when the record is handled
+	// synchronously, from the goroutine which logged it; otherwise the original source is reported.
+	SourceSkipPackages []string
+
+	// SourceFormat is the way the source is written with AddSource. It defaults to SourceString.
+	// Attributes holding a *slog.Source or a slog.Source, like the ones added by middlewares capturing
+	// the source themselves, are written the same way, under their own key.
+	SourceFormat SourceFormat
+
+	// AddDeltaTime makes the handler write the milliseconds elapsed since the previous record written by the
+	// handler, or any handler derived from it, in a DeltaTimeKey field alongside the time field. Deltas are
+	// computed from record times: the first record, and records older than the previous one, as when records
+	// are handled concurrently, get a zero delta.
+	AddDeltaTime bool
+
+	// AddServiceName makes the handler write a ServiceKey field with the service name in every record.
+	// It's ServiceName if set, or the last element of the main module path from the build information
+	// of the binary, like "api" for "example.com/fleet/api/v2". The name is resolved when the handler is
+	// created, and the field is not written if the build information is unavailable.
+	AddServiceName bool
+
+	// ServiceName overrides the service name written with AddServiceName.
+	ServiceName string
+
+	// AddContextInfo makes the handler write, at the top level of records handled with a context having a
+	// deadline, a ContextDeadlineKey field with the milliseconds left before the deadline when the record was
+	// logged, negative once it's passed, and a ContextCanceledKey field telling whether the context is done.
+	// Records handled with a context done without deadline only get a ContextCanceledKey field set to true,
+	// and other records get neither.
+	AddContextInfo bool
+
+	// AllowContextMirror makes the handler write a copy of the records handled with a context returned
+	// by ContextWithMirror to the writer it carries. It only applies to handlers created from an io.Writer,
+	// and for console handlers the copy is the JSON record, before console formatting.
+	AllowContextMirror bool
+
+	// AllowKeys, if not empty, restricts the attributes written by the handler to the ones
+	// whose dot-joined group path (e.g. "http.method") matches one of the given keys or patterns.
+	// Patterns use the path.Match syntax, like "http.*". A group whose path matches is written entirely,
+	// otherwise only its matching members are. Dropped attributes are counted in Stats.
+	// The level, time, message and caller fields are always written.
+	AllowKeys []string
+
+	// AttrLevelOverrides, if its Key is set, selects the minimum level of records according to the value of
+	// the attribute having the key, in the record or added with WithAttrs, like a tenant identifier, so that
+	// debug records can be written for a single tenant. Records without the attribute, or whose value has no
+	// override, are filtered as without overrides. As Enabled can't see attributes, it reports the levels of
+	// the overrides as enabled, and Handle drops the records below their level, counting them in Stats.
+	AttrLevelOverrides AttrLevelOverrides
+
+	// AuditKeys are the keys every audit record must carry, as dot-joined group paths
+	// (e.g. "actor" or "req.target"). Keys added with WithAttrs count. When an audit record misses
+	// some of them, the handler adds an "audit_incomplete" field listing the missing keys,
+	// and reports an error to OnError. Audit records are records with a top-level "audit" boolean
+	// attribute set to true, or at AuditLevel.
+	AuditKeys []string
+
+	// AuditLevel, if not nil, is the level of audit records. See AuditKeys.
+	AuditLevel slog.Leveler
+
+	// ComponentKey is the key of the component name field written by handlers returned by Named.
+	// It defaults to DefaultComponentKey.
+	ComponentKey string
+
+	// DedupKeys makes the handler write only the last of the fields sharing the same key within a group of a record,
+	// at the position of the last one, including the attributes added with WithAttrs, which are then written with
+	// the ones of the record instead of being encoded once into the context of the logger, and the group names
+	// added with WithGroup. Members of groups are deduplicated recursively. It costs a map allocation per group
+	// of each record. Fields already in the context of the wrapped logger are not covered.
+	// StrictSlogCompliance implies it.
+	DedupKeys bool
+
+	// DefaultAttrs are attributes written at the top level of the records which don't provide the same key
+	// themselves: records whose top-level attributes, or the name of their top-level group, have the key, and
+	// handlers derived with WithAttrs from attributes having the key, don't get the default. Attributes inside
+	// groups never override defaults. Unlike attributes added with WithAttrs, defaults are written after the
+	// attributes of the record, and go through the attribute related options once, when the handler is created.
+	DefaultAttrs []slog.Attr
+
+	// DropEmptyKeys makes the handler drop the attributes having an empty key, except groups, whose members are
+	// written as usual. Empty attributes, having an empty key and no value, are always ignored, as required by
+	// slog.Handler, but attributes having an empty key and a value are written with an empty key by default.
+	DropEmptyKeys bool
+
+	// DropMessages makes the handler drop records whose message matches any of the patterns, before
+	// writing anything. Patterns enclosed in slashes, like "/^health(z)?$/", are regular expressions, and the
+	// other ones substrings. Matching is case-sensitive, unless a regular expression has the (?i) flag.
+	// Dropped records are counted in Stats. Patterns are compiled by Handler.Warmup, which returns the errors of
+	// invalid regular expressions, or else when the first record is handled, in which case the errors are reported
+	// to OnError. Invalid regular expressions match nothing.
+	DropMessages []string
+
+	// EmitSchemaOnStart makes the handler write a record describing its schema, as returned by Schema,
+	// before the first record it writes. The record has the SchemaMessage message, no level, and holds the
+	// schema in a SchemaKey object. It's written once for the handler and all its derived handlers,
+	// with the schema of the handler writing the first record.
+	EmitSchemaOnStart bool
+
+	// EnvelopeFirst makes the handler write the level, the OpenTelemetry severity, the time and the source
+	// of records before their attributes, instead of after them, for tools scanning the beginning of lines.
+	// Only the order of the fields changes. Fields of the wrapped logger's own context still come first,
+	// and attributes added with WithAttrs are then written for each record, after the envelope.
+	EnvelopeFirst bool
+
+	// ExpandMessage makes the handler replace the {key} placeholders of record messages with the string form
+	// of the record attribute having this key, or this dot-joined group path relative to the handler groups,
+	// like {user.id}. The attributes are still written. Placeholders without matching attribute are left
+	// verbatim, and "{{" and "}}" are written as literal braces. Messages are expanded after SuppressRepeats
+	// compares them.
+	ExpandMessage bool
+
+	// FieldNames overrides zerolog's global field names for the fields written by the handler, so that
+	// handlers of the same process can use different names. See FieldNames.
+	FieldNames FieldNames
+
+	// FlushRetries is the number of times a failed batch flush is retried before the batch
+	// is dropped and the failure reported to OnError. It is used by batching handlers.
+	FlushRetries int
+
+	// Hooks are zerolog hooks added to the wrapped logger. They run exactly once
+	// per emitted record, including records logged through groups, and can retrieve
+	// the context passed to slog through zerolog.Event.GetCtx.
+	Hooks []zerolog.Hook
+
+	// InternStrings, if greater than zero, is the maximum number of strings kept in a cache shared by
+	// the handler and its derived handlers, to reuse the text of attribute values instead of converting it
+	// for each record. It applies to values written as the text returned by their MarshalText method,
+	// like enums implementing encoding.TextMarshaler. The least recently used strings are evicted first.
+	InternStrings int
+
+	// LevelAttrs maps levels to callbacks returning attributes added at the top level of the records at that level
+	// or above, like expensive diagnostic fields only wanted for errors. Callbacks are only called for records
+	// being written, after the level checks, in increasing level order, and their attributes go through the same
+	// options as record attributes.
+	LevelAttrs map[slog.Level]func() []slog.Attr
+
 	// Level reports the minimum record level that will be logged.
 	// The handler discards records with lower levels.
 	// If Level is nil, the handler assumes the level set in the logger.
 	// The handler calls Level.Level if it's not nil for each record processed;
 	// to adjust the minimum level dynamically, use a LevelVar.
 	Level slog.Leveler
+
+	// LogConfigOnFirstUse makes the handler write a record summarizing its effective configuration, like its
+	// minimum level and where it comes from, its output format and WriterLabel, at the first call to Handle of
+	// the handler or any handler derived from it. The record has the ConfigMessage message, the info level
+	// whatever the handler level, and holds the configuration in a ConfigKey object. Options holding keys or
+	// patterns are only summarized by their count.
+	LogConfigOnFirstUse bool
+
+	// LogstashFormat, if not nil, makes the handler write records in the Logstash event format: the time field
+	// is named LogstashTimestampKey, overriding FieldNames.Time, and records get a LogstashVersionKey field set
+	// to LogstashVersion, and a LogstashEventKey object holding the dataset and module, unless both are empty.
+	// These fields are written with the record envelope. Attributes are written at the top level, or under
+	// LogstashOptions.AttrsKey if set.
+	LogstashFormat *LogstashOptions
+
+	// MaxAttrDepth is the maximum number of groups, slices and maps enclosing each other in an attribute value.
+	// Deeper groups and containers are written as TruncatedValue, so that pathological or self-referencing
+	// values can't exhaust the stack or the memory. It defaults to DefaultMaxAttrDepth, and a negative value
+	// removes the limit.
+	MaxAttrDepth int
+
+	// MaxAttrWidth is the maximum number of members written for each group, slice and map in an attribute
+	// value, at any depth. Groups and maps with more members are written with their first ones, in order for
+	// groups and sorted by key for maps, and a TruncatedValue member holding the number of omitted ones, and
+	// longer slices with their first elements followed by TruncatedValue. Unlike MaxSliceLen, it's meant as
+	// a safety bound. It defaults to DefaultMaxAttrWidth, and a negative value removes the limit.
+	MaxAttrWidth int
+
+	// MaxSliceLen, if greater than zero, truncates the slice and array values of attributes to their first
+	// MaxSliceLen elements. Byte slices, and values written by their own methods, like net.IP, are not truncated.
+	MaxSliceLen int
+
+	// MaxUniqueKeys, if greater than zero, bounds the number of distinct keys written by the handler and its
+	// derived handlers, as dot-joined group paths, to protect log indexes from keys built from unbounded values.
+	// Once MaxUniqueKeys keys have been seen, an attribute with a new key is written as an OverflowKey group
+	// holding it, like "overflow_key":{"3f2a...":1}, and counted in Stats. Keys seen before keep being written.
+	// Keys of attributes added with WithAttrs are counted when the attributes are added.
+	MaxUniqueKeys int
+
+	// OmitLevel removes the level field from the output. Records are still filtered according to their level,
+	// but are written as zerolog.NoLevel events: hooks, samplers and OnRecordSize see zerolog.NoLevel.
+	OmitLevel bool
+
+	// OnlyMessages makes the handler drop records whose message doesn't match any of the patterns,
+	// with the same syntax as DropMessages. Records matching DropMessages are dropped anyway.
+	OnlyMessages []string
+
+	// OTELSeverity makes the handler write the OpenTelemetry severity of records, as returned by the OTELSeverity
+	// function, in OTELSeverityNumberKey and OTELSeverityTextKey fields, for the JSON file receiver of the
+	// OpenTelemetry collector. They're written alongside the level field, or instead of it with OmitLevel.
+	OTELSeverity bool
+
+	// ReplaceAttr, if not nil, is called to rewrite each attribute which is not a group before it's written,
+	// like slog.HandlerOptions.ReplaceAttr, to redact or rename attributes. groups are the names of the groups
+	// holding the attribute, including the ones added with WithGroup, and must not be retained. An attribute
+	// replaced with an attribute having an empty key is dropped. Attributes added with WithAttrs go through it
+	// once, when they're added. Unlike with slog handlers, it's called after AllowKeys filtered attributes.
+	//
+	// Like with slog handlers, it's also called with no groups for the level, time, message and source fields
+	// written by the handler, with their actual names, like zerolog.MessageFieldName or the FieldNames overrides,
+	// and the values slog handlers pass: the slog.Level, the time.Time, the message string and a *slog.Source.
+	// Fields returned unchanged are written as usual, and replaced ones like attributes. Records whose level field
+	// is replaced are written like with OmitLevel, so hooks, samplers and OnRecordSize see zerolog.NoLevel.
+	ReplaceAttr func(groups []string, a slog.Attr) slog.Attr
+
+	// ReservedKeyPolicy tells how to write top-level attributes whose key is the name of a field
+	// written by the handler: zerolog.TimestampFieldName, zerolog.MessageFieldName, zerolog.LevelFieldName
+	// unless OmitLevel is set, the source fields when AddSource is set, or their FieldNames overrides, and the
+	// severity fields when OTELSeverity is set.
+	// By default, they are written as is, leading to duplicate keys. Attributes inside groups never collide.
+	ReservedKeyPolicy ReservedKeyPolicy
+
+	// SampleAttrs makes the handler keep the values of the record attributes matching SampleAttrs.Keys on
+	// about SampleAttrs.Rate of the records only, and write SampledOutValue instead on the other ones, for bulky
+	// values like SQL queries or request bodies. Whether a record keeps the values is decided from a hash of its
+	// time, level and message, so that handlers sampling the same records at the same rate agree. Attributes
+	// added with WithAttrs, DefaultAttrs and LevelAttrs are never sampled.
+	SampleAttrs SampleAttrs
+
+	// StrictSlogCompliance makes the handler follow all the slog.Handler rules, at some performance cost:
+	// empty attributes are ignored, the attributes of groups with an empty key are inlined, empty groups
+	// are not written, and when several attributes share the same key within a group, including the ones
+	// added with WithAttrs, only the last one is written.
+	StrictSlogCompliance bool
+
+	// StrictEmission makes Handle return an error wrapping ErrNotEmitted when the record won't be written
+	// by the zerolog logger, because of zerolog's global level, a disabled logger, a logger without writer,
+	// or a record below the handler level. It's meant to detect misconfigurations, as it makes
+	// Handle more expensive.
+	StrictEmission bool
+
+	// StringifyValues are keys or patterns, like AllowKeys, of the attributes always written as strings,
+	// whatever their kind, for sinks expecting string fields. Numbers, booleans, times and durations are
+	// written as the text of the JSON value they would have been written as, and other values as their
+	// string form. A group whose path matches has all its members written as strings.
+	StringifyValues []string
+
+	// SuppressRepeats, if greater than zero, suppresses records having the same level and message
+	// as a record emitted less than SuppressRepeats earlier, according to the records time.
+	// Suppressed records are counted in Stats.
+	SuppressRepeats time.Duration
+
+	// SummaryInterval, if greater than zero, makes the handler emit a summary record every SummaryInterval
+	// for each level and message that had records suppressed by SuppressRepeats, with the number of suppressed
+	// records and the time of the first and last ones. Summaries are emitted from a background goroutine, and
+	// pending ones are flushed by Close.
+	SummaryInterval time.Duration
+
+	// MeasureLatency makes the handler measure the time spent in Handle, and record it in
+	// the latency histogram reported by Stats.
+	MeasureLatency bool
+
+	// OnRecordSize, if not nil, is called with the level and size in bytes of each record written.
+	// The level is the zerolog level of the record, mapped back with SlogLevel.
+	// Like WriteTimeout, it only applies to handlers created from an io.Writer, and for console handlers
+	// the size is the one of the JSON record, before console formatting.
+	OnRecordSize func(level slog.Level, bytes int)
+
+	// OnError, if not nil, is called with errors the handler could not return
+	// to the caller, such as records dropped because of a write timeout.
+	OnError func(err error)
+
+	// OnRecordError, if not nil, is called with the errors detected while writing a record, along with
+	// the context passed to Handle, the record, and the group path of the error: the groups of the handler,
+	// followed by the groups of the record holding the faulty attribute, if any. Detected errors are write
+	// failures of handlers created from an io.Writer, and attribute values failing to be marshaled,
+	// reported as *MarshalError. Records are still written as without OnRecordError, but values are
+	// marshaled before the record is written, and the attributes added with WithAttrs are not checked.
+	OnRecordError func(ctx context.Context, groupPath []string, rec slog.Record, err error)
+
+	// TagProvenance makes the handler append the origin of attributes to their key, for debugging where a field
+	// comes from: "@rec" for the attributes of the record, "@with" for the ones added with WithAttrs,
+	// "@default" for DefaultAttrs and "@level" for LevelAttrs, like "user@with". Only the keys of attributes
+	// at the level they were added are tagged, not the members of their groups, and keys are tagged after the
+	// other options are applied. Fields of the wrapped logger and fields written by the handler itself are not
+	// tagged. As tagged keys differ, StrictSlogCompliance and DedupKeys don't deduplicate keys of different origins.
+	// It's meant for debugging only, as it changes the output schema.
+	TagProvenance bool
+
+	// TrustLoggerTimestamps makes the handler detect whether the wrapped logger already writes the timestamp
+	// or caller fields, because it was configured with zerolog.Context.Timestamp or zerolog.Context.Caller,
+	// in which case the handler doesn't write them itself, to avoid duplicate keys. Detection reads the hooks
+	// and the context of the logger when the handler is created, without writing a record, so that its hooks
+	// don't run. Fields added by other hooks aren't detected.
+	// The logger's timestamp is the time the record is written rather than the record time, and its caller
+	// is computed from zerolog's call stack, which depends on zerolog.CallerSkipFrameCount.
+	TrustLoggerTimestamps bool
+
+	// UnitCoercion maps key suffixes to units. The values of attributes whose key ends with one of the suffixes
+	// are converted to the associated unit: time.Duration values are written as a number of the time unit,
+	// regardless of zerolog.DurationFieldUnit, and numbers are written as an integer number of bytes for UnitBytes.
+	// Values of other types are written unchanged. For instance, with {"_ms": UnitMilliseconds},
+	// slog.Duration("latency_ms", 1500*time.Microsecond) is written as "latency_ms":1.5.
+	UnitCoercion map[string]Unit
+
+	// ValidateUTF8 makes the handler replace the invalid UTF-8 sequences of string attribute values and
+	// messages with U+FFFD, for consumers rejecting documents with invalid strings. The strings having
+	// invalid sequences are counted in Stats. Strings nested in other values, like slices, are left unchanged.
+	ValidateUTF8 bool
+
+	// WarnOnDuplicateKeys makes the handler report a *DuplicateKeyError to OnError for each key written
+	// more than once in the same group of a record, including keys of attributes added with WithAttrs and
+	// group names. Records are written unchanged. Keys are tracked per record only when it's set,
+	// and never with StrictSlogCompliance or DedupKeys, which remove duplicates.
+	WarnOnDuplicateKeys bool
+
+	// WriteTimeout, if greater than zero, bounds the time spent writing a single record.
+	// When the output supports SetWriteDeadline (net.Conn, *os.File pipes), the deadline is set
+	// before each write. Otherwise the write runs in a separate goroutine, and records are dropped
+	// while a previous write is still pending. Timed out records are counted in Stats.
+	// It only applies to handlers created from an io.Writer, like NewJsonHandler or NewConsoleHandler.
+	WriteTimeout time.Duration
+
+	// WriterLabel identifies the output of the handler in its Fingerprint, as writers can't be compared.
+	// Handlers writing to different outputs should be given different labels.
+	WriterLabel string
 }
 
 // zerologHandler is an internal interface used to expose additional methods
@@ -35,13 +350,79 @@ type HandlerOptions struct {
 type zerologHandler interface {
 	slog.Handler
 	// handleGroup handles records comming from the child group.
-	handleGroup(group string, rec *slog.Record, e *zerolog.Event)
+	// rec only carries the record envelope: its attributes are already written into e.
+	// top holds attributes to write at the top level of the event.
+	handleGroup(ctx context.Context, group string, rec *slog.Record, e *zerolog.Event, top []slog.Attr)
+	// shouldEmit cheaply reports whether a record at the given level
+	// would actually be written by the root handler.
+	shouldEmit(lvl slog.Level) bool
+	// attrsSchema adds the fields of the logger context and the attributes added with WithAttrs to schema.
+	attrsSchema(schema map[string]string)
 }
 
 // Handler is an slog.Handler implementation that uses zerolog to process slog.Record.
+//
+// A zero Handler, like in
+//
+//	var h zeroslog.Handler
+//	logger := slog.New(&h)
+//
+// is a valid no-op handler, so that handlers embedded in structs or declared without NewHandler don't panic:
+// Enabled reports every level as disabled, Handle and HandleRaw discard records and return nil, and
+// WithAttrs, WithGroup and Named return h itself. Its Stats stay at zero, its Schema is empty, and
+// Check reports ProblemZeroHandler. The other methods behave as with the default options.
 type Handler struct {
-	opts   *HandlerOptions
+	// opts is the immutable configuration, shared with derived handlers.
+	opts *HandlerOptions
+	// logger is the wrapped logger, without the pending attributes.
 	logger zerolog.Logger
+	// out is the output of the logger when it's set by the handler, used to tee records to context mirrors.
+	out io.Writer
+	// component is the slash-joined component name set with Named.
+	component string
+	// nanoTime is true if record times are written with nanoseconds when zerolog.TimeFieldFormat is
+	// time.RFC3339, for console writers to print fractional seconds.
+	nanoTime bool
+	// routes are the named destinations of shared encoding handlers, nil for other handlers.
+	routes *fanoutRoutes
+	// queue is the writer of batching handlers, to wait for room before handling records.
+	queue    *batchWriter
+	pending  pendingAttrs
+	level    levelThreshold
+	stats    *stats
+	suppress *suppressor
+	// sampler is nil unless SampleAttrs samples values out.
+	sampler *attrSampler
+	// messages is nil unless DropMessages or OnlyMessages are set.
+	messages *messageFilter
+	schema   *schemaEmitter
+	config   *configEmitter
+	// delta is nil unless AddDeltaTime is set.
+	delta *deltaClock
+	// override is the AttrLevelOverrides level selected by the attributes added with WithAttrs, if any.
+	override slog.Leveler
+	// boosts are the levels installed with BoostLevel.
+	boosts *levelBoosts
+	// filtered is set when options filter, delay or observe records before they're written, like DropMessages
+	// or MeasureLatency. Handle skips them all otherwise.
+	filtered bool
+	pipe     *pipeline
+	// limits are the MaxAttrDepth and MaxAttrWidth bounds of written attribute values.
+	limits attrLimits
+	// levelAttrs are the LevelAttrs callbacks, sorted by level.
+	levelAttrs []levelAttrsFunc
+	// loggerTime and loggerCaller are set with TrustLoggerTimestamps when the wrapped logger
+	// writes the timestamp and caller fields itself.
+	loggerTime   bool
+	loggerCaller bool
+	// keys are the flattened keys added with WithAttrs, only tracked for audit purposes.
+	keys []string
+	// defaults are the written DefaultAttrs whose key isn't provided by attributes added with WithAttrs.
+	defaults []slog.Attr
+	// ctxAttrs are the attributes added with WithAttrs, only tracked for WarnOnDuplicateKeys.
+	ctxAttrs pendingAttrs
+	// chain is the fingerprint of the attributes and groups added to the handler.
+	chain fingerprint
 }
 
 var _ zerologHandler = (*Handler)(nil)
@@ -52,149 +433,799 @@ var _ zerologHandler = (*Handler)(nil)
 // Unlesse opts.Level is not nil, the logger level is used to filter out records, otherwise
 // opts.Level is used.
 //
-// The provided logger instance must be configured to not send timestamps or caller information.
+// The provided logger instance must be configured to not send timestamps or caller information,
+// unless opts.TrustLoggerTimestamps is set.
+//
+// The handler never modifies logger: it only derives copies of it, so that logger can keep being used
+// directly, and writes the same output whatever the handler, or the handlers derived from it, do.
+// Its writer, hooks and sampler are shared however, as with any copy of a zerolog.Logger.
 //
 // If opts is nil, it assumes default options values.
 func NewHandler(logger zerolog.Logger, opts *HandlerOptions) *Handler {
-	if opts == nil {
-		opts = new(HandlerOptions)
+	opt := newConfig(opts)
+	var loggerTime, loggerCaller bool
+	if opt.TrustLoggerTimestamps {
+		loggerTime, loggerCaller = probeLoggerFields(logger)
+	}
+	if opt.AddServiceName {
+		if name := serviceName(opt); name != "" {
+			logger = logger.With().Str(ServiceKey, name).Logger()
+		}
+	}
+	for _, hook := range opt.Hooks {
+		logger = logger.Hook(hook)
+	}
+	h := &Handler{
+		opts:         opt,
+		logger:       logger,
+		level:        newLevelThreshold(logger, opt.Level),
+		stats:        newStats(opt.MeasureLatency),
+		boosts:       newLevelBoosts(),
+		chain:        fingerprintOffset,
+		levelAttrs:   newLevelAttrs(opt.LevelAttrs),
+		loggerTime:   loggerTime,
+		loggerCaller: loggerCaller,
+	}
+	h.messages = newMessageFilter(opt, h.reportError)
+	h.sampler = newAttrSampler(opt.SampleAttrs)
+	h.pipe = newPipeline(opt, h.stats, zerologReservedKey(opt))
+	h.limits = opt.attrLimits()
+	h.defaults = h.pipe.attrs("", resolveAttrs(opt.DefaultAttrs))
+	if opt.SuppressRepeats > 0 {
+		h.suppress = newSuppressor(h)
 	}
-	opt := *opts // Copy
-	return &Handler{
-		opts:   &opt,
-		logger: logger,
+	if opt.EmitSchemaOnStart {
+		h.schema = new(schemaEmitter)
 	}
+	if opt.AddDeltaTime {
+		h.delta = new(deltaClock)
+	}
+	if opt.LogConfigOnFirstUse {
+		h.config = &configEmitter{root: h}
+	}
+	h.filtered = opt.StrictEmission || opt.AttrLevelOverrides.enabled() || h.messages != nil || h.suppress != nil ||
+		h.schema != nil || h.config != nil || h.stats.latency != nil
+	return h
 }
 
 // NewJsonHandler is a shortcut to calling
 //
-//	NewHandler(zerolog.New(out).Level(zerolog.InfoLevel), opts)
+//	NewHandler(zerolog.New(out).Level(lvl), opts)
+//
+// where lvl is ZerologLevel(opts.Level) if opts.Level is a slog.Level, zerolog.TraceLevel if it's another
+// slog.Leveler, like a *slog.LevelVar, and zerolog.InfoLevel if opts or opts.Level is nil, so that records
+// are filtered by opts.Level alone when it's set.
 func NewJsonHandler(out io.Writer, opts *HandlerOptions) *Handler {
-	return NewHandler(zerolog.New(out).Level(zerolog.InfoLevel), opts)
+	h := NewHandler(zerolog.New(out).Level(shortcutLevel(opts)), opts)
+	h.setOutput(out)
+	return h
+}
+
+// setOutput sets the output of the logger to out, wrapped according to the writer related options.
+func (h *Handler) setOutput(out io.Writer) {
+	if h.opts.WriteTimeout > 0 {
+		out = newTimeoutWriter(out, h.opts.WriteTimeout, h.reportDrop)
+	}
+	if h.opts.OnRecordSize != nil {
+		out = newSizeWriter(out, h.opts.OnRecordSize)
+	}
+	h.out = out
+	h.logger = h.logger.Output(out)
 }
 
 // NewConsoleHandler creates a new zerolog handler, wrapping out into a zerolog.ConsoleWriter.
 // It's a shortcut to calling
 //
-//	NewHandler(zerolog.New(&zerolog.ConsoleWriter{Out: out, TimeFormat: time.DateTime}).Level(zerolog.InfoLevel), opts)
+//	NewConsoleHandlerWithTimeFormat(out, time.DateTime, opts)
 func NewConsoleHandler(out io.Writer, opts *HandlerOptions) *Handler {
-	return NewJsonHandler(&zerolog.ConsoleWriter{Out: out, TimeFormat: time.DateTime}, opts)
+	return NewConsoleHandlerWithTimeFormat(out, time.DateTime, opts)
+}
+
+// NewConsoleHandlerWithTimeFormat creates a new zerolog handler, wrapping out into a zerolog.ConsoleWriter
+// printing times with timeFormat, like time.StampMilli or time.RFC3339Nano. It's similar to calling
+//
+//	NewHandler(zerolog.New(&zerolog.ConsoleWriter{Out: out, TimeFormat: timeFormat}).Level(lvl), opts)
+//
+// with the same lvl as NewJsonHandler, except that, with zerolog's default time.RFC3339 zerolog.TimeFieldFormat, record times are passed to the
+// console writer with nanoseconds, so that timeFormat can print fractional seconds.
+//
+// The handler is safe for concurrent use: records are formatted one at a time, and each of them reaches out
+// in a single Write call, even if the console writer writes it in several parts.
+func NewConsoleHandlerWithTimeFormat(out io.Writer, timeFormat string, opts *HandlerOptions) *Handler {
+	cw := &zerolog.ConsoleWriter{Out: &lineWriter{out: out}, TimeFormat: timeFormat}
+	h := NewJsonHandler(zerolog.SyncWriter(cw), opts)
+	h.nanoTime = true
+	return h
 }
 
 // Enabled implements slog.Handler.
+//
+// A handler wrapping a logger set to zerolog.Disabled never reports any level as enabled,
+// regardless of opts.Level. A logger set to zerolog.NoLevel is treated as zerolog.TraceLevel.
+//
+// When opts.Level is a *slog.LevelVar, level changes are observed by the next call.
 func (h *Handler) Enabled(_ context.Context, lvl slog.Level) bool {
-	if h.opts.Level != nil {
-		return lvl >= h.opts.Level.Level()
+	if h.isZero() {
+		return false
 	}
-	return zerologLevel(lvl) >= h.logger.GetLevel()
+	return h.level.enabled(lvl) || !h.level.disabled && (h.overrideEmits(lvl) || h.boostEmits(lvl))
 }
 
-// startLog creates a new logging event at the given level.
-func (h *Handler) startLog(lvl slog.Level) *zerolog.Event {
-	logger := h.logger
+// loggerLevel returns the level of the wrapped logger, with zerolog.NoLevel
+// being mapped to zerolog.TraceLevel.
+func (h *Handler) loggerLevel() zerolog.Level {
+	if lvl := h.logger.GetLevel(); lvl != zerolog.NoLevel {
+		return lvl
+	}
+	return zerolog.TraceLevel
+}
+
+// MinLevel returns the minimum level of the records written by the handler. It's opts.Level if set,
+// or the level of the wrapped logger otherwise, lowered to the lowest level of opts.AttrLevelOverrides
+// and of the active BoostLevel boosts, and raised to zerolog's global level.
+// A disabled handler returns the highest possible slog.Level.
+func (h *Handler) MinLevel() slog.Level {
+	if h.isZero() || h.logger.GetLevel() == zerolog.Disabled {
+		return SlogLevel(zerolog.Disabled)
+	}
+	lvl := SlogLevel(h.loggerLevel())
 	if h.opts.Level != nil {
-		logger = h.logger.Level(zerologLevel(h.opts.Level.Level()))
+		lvl = h.opts.Level.Level()
+	}
+	if floor, ok := h.opts.AttrLevelOverrides.floor(); ok {
+		lvl = min(lvl, floor)
+	}
+	if boost, ok := h.boosts.level(); ok {
+		lvl = min(lvl, boost)
+	}
+	return max(lvl, SlogLevel(zerolog.GlobalLevel()))
+}
+
+// shouldEmit implements zerologHandler.
+// It checks the handler's effective level and zerolog's global level without creating an event.
+func (h *Handler) shouldEmit(lvl slog.Level) bool {
+	zlvl := ZerologLevel(lvl)
+	if zlvl < zerolog.GlobalLevel() {
+		return false
+	}
+	switch {
+	case h.logger.GetLevel() == zerolog.Disabled:
+		return false
+	case h.opts.Level != nil:
+		return zlvl >= ZerologLevel(h.opts.Level.Level()) || h.overrideEmits(lvl) || h.boostEmits(lvl)
+	default:
+		return zlvl >= h.loggerLevel() || h.overrideEmits(lvl) || h.boostEmits(lvl)
+	}
+}
+
+// startLog creates a new logging event at the given level, carrying ctx for hooks.
+func (h *Handler) startLog(ctx context.Context, lvl slog.Level) *zerolog.Event {
+	if h.opts.OmitLevel {
+		return h.startLogNoLevel(ctx, lvl)
 	}
-	return logger.WithLevel(zerologLevel(lvl))
+	var replaced slog.Attr
+	keep := true
+	if h.opts.ReplaceAttr != nil {
+		// The level is only replaced in records that are written.
+		if !h.shouldEmit(lvl) {
+			return nil
+		}
+		name := h.opts.FieldNames.level()
+		replaced = h.opts.ReplaceAttr(nil, slog.Any(name, lvl))
+		keep = isBuiltin(replaced, name, lvl)
+	}
+	if h.opts.FieldNames.Level != "" || !keep {
+		evt := h.startLogNoLevel(ctx, lvl)
+		switch {
+		case evt == nil:
+		case keep:
+			evt.Str(h.opts.FieldNames.Level, zerolog.LevelFieldMarshalFunc(ZerologLevel(lvl)))
+		default:
+			h.writeReplaced(evt, replaced)
+		}
+		return evt
+	}
+	logger := h.contextLogger()
+	out, redirected := h.recordOutput(ctx)
+	// The level is computed first, so that the logger is only copied if it has to change.
+	level := logger.GetLevel()
+	switch {
+	case level == zerolog.Disabled:
+	case h.opts.Level != nil:
+		level = ZerologLevel(h.opts.Level.Level())
+	case level == zerolog.NoLevel:
+		level = zerolog.TraceLevel
+	}
+	if level != zerolog.Disabled {
+		if floor, ok := h.opts.AttrLevelOverrides.floor(); ok {
+			level = min(level, ZerologLevel(floor))
+		}
+		if boost, ok := h.boosts.level(); ok {
+			level = min(level, ZerologLevel(boost))
+		}
+	}
+	if redirected || level != logger.GetLevel() {
+		l := logger.Level(level)
+		if redirected {
+			l = l.Output(out)
+		}
+		logger = &l
+	}
+	evt := logger.WithLevel(ZerologLevel(lvl))
+	if evt != nil && ctx != nil {
+		evt = evt.Ctx(ctx)
+	}
+	return evt
+}
+
+// startLogNoLevel creates a new logging event without level field, if a record at the given level should be emitted.
+func (h *Handler) startLogNoLevel(ctx context.Context, lvl slog.Level) *zerolog.Event {
+	if !h.shouldEmit(lvl) {
+		return nil
+	}
+	logger := h.contextLogger()
+	if out, ok := h.recordOutput(ctx); ok {
+		l := logger.Output(out)
+		logger = &l
+	}
+	evt := logger.Log()
+	if evt != nil && ctx != nil {
+		evt = evt.Ctx(ctx)
+	}
+	return evt
+}
+
+// contextLogger returns the wrapped logger, with the attributes added with WithAttrs.
+// With StrictSlogCompliance or DedupKeys, these attributes are written with the record ones instead,
+// and with EnvelopeFirst, after the record envelope.
+// The returned logger must not be modified.
+func (h *Handler) contextLogger() *zerolog.Logger {
+	if h.pending.last == nil || h.opts.mergesAttrs() || h.opts.EnvelopeFirst {
+		return &h.logger
+	}
+	return h.pending.applyLogger(&h.logger, h.limits)
 }
 
-// endLog finalize the log event by appending record source, timestamp and message before sending it.
-func (h *Handler) endLog(rec *slog.Record, evt *zerolog.Event) {
-	if h.opts.AddSource && rec.PC > 0 {
-		frame, _ := runtime.CallersFrames([]uintptr{rec.PC}).Next()
-		evt.Str(zerolog.CallerFieldName, fmt.Sprintf("%s:%d", frame.File, frame.Line))
+// startRecord creates a new logging event for rec, like startLog. With EnvelopeFirst, it also writes the
+// envelope of the record, followed by the attributes added with WithAttrs, unless they're written with the
+// record ones with StrictSlogCompliance or DedupKeys.
+func (h *Handler) startRecord(ctx context.Context, rec *slog.Record) *zerolog.Event {
+	evt := h.startLog(ctx, rec.Level)
+	if evt == nil || !h.opts.EnvelopeFirst {
+		return evt
+	}
+	h.writeSeverity(evt, rec.Level)
+	h.writeEnvelope(evt, rec)
+	if !h.opts.mergesAttrs() {
+		appendSegments(evt, h.limits, h.pending.last)
+	}
+	return evt
+}
+
+// writeSeverity writes the OpenTelemetry severity of lvl if OTELSeverity is set.
+func (h *Handler) writeSeverity(evt *zerolog.Event, lvl slog.Level) {
+	if h.opts.OTELSeverity {
+		number, text := OTELSeverity(lvl)
+		evt.Int(OTELSeverityNumberKey, number).Str(OTELSeverityTextKey, text)
+	}
+}
+
+// writeEnvelope writes the source and time of rec, the time elapsed since the previous record with AddDeltaTime,
+// and the Logstash fields with LogstashFormat.
+func (h *Handler) writeEnvelope(evt *zerolog.Event, rec *slog.Record) {
+	if h.opts.AddSource && rec.PC > 0 && !h.loggerCaller {
+		name, src := h.opts.FieldNames.caller(), recordSource(h.opts, rec.PC)
+		if h.opts.ReplaceAttr == nil || h.keepBuiltin(evt, slog.Any(name, src.slogSource())) {
+			writeSource(evt, name, h.opts.SourceFormat, src)
+		}
+	}
+	if !rec.Time.IsZero() && !h.loggerTime && (h.opts.ReplaceAttr == nil || h.keepBuiltin(evt, slog.Time(h.opts.FieldNames.time(), rec.Time))) {
+		if h.nanoTime && zerolog.TimeFieldFormat == time.RFC3339 {
+			evt.Str(h.opts.FieldNames.time(), rec.Time.Format(time.RFC3339Nano))
+		} else {
+			evt.Time(h.opts.FieldNames.time(), rec.Time)
+		}
 	}
+	if h.delta != nil && !rec.Time.IsZero() {
+		evt.Float64(DeltaTimeKey, h.delta.since(rec.Time))
+	}
+	h.writeLogstash(evt)
+}
 
-	if !rec.Time.IsZero() {
-		evt.Time(zerolog.TimestampFieldName, rec.Time)
+// endLog finalize the log event by appending top-level attributes, and the record envelope unless it's
+// already written with EnvelopeFirst, and message before sending it.
+func (h *Handler) endLog(rec *slog.Record, evt *zerolog.Event, top []slog.Attr) {
+	if h.component != "" {
+		evt.Str(h.componentKey(), h.component)
+	}
+	if !h.opts.EnvelopeFirst {
+		h.writeSeverity(evt, rec.Level)
+	}
+	mapAttrs(evt, h.limits, top...)
+	if h.opts.AddContextInfo {
+		writeContextInfo(evt, evt.GetCtx(), rec.Time)
+	}
+	if !h.opts.EnvelopeFirst {
+		h.writeEnvelope(evt, rec)
+	}
+	h.stats.emitted.Add(1)
+	msg := rec.Message
+	if h.opts.ValidateUTF8 {
+		msg = validUTF8(msg, h.stats)
+	}
+	if h.opts.ReplaceAttr != nil && !h.keepBuiltin(evt, slog.String(h.opts.FieldNames.message(), msg)) {
+		evt.Send()
+		return
+	}
+	if h.opts.FieldNames.Message == "" {
+		evt.Msg(msg)
+		return
 	}
-	evt.Msg(rec.Message)
+	if msg != "" {
+		evt.Str(h.opts.FieldNames.Message, msg)
+	}
+	evt.Send()
 }
 
 // handleGroup handles records comming from a child group.
-func (h *Handler) handleGroup(group string, rec *slog.Record, dict *zerolog.Event) {
-	evt := h.startLog(rec.Level)
-	evt.Dict(group, dict)
-	h.endLog(rec, evt)
+// dict is nil if the group is empty.
+func (h *Handler) handleGroup(ctx context.Context, group string, rec *slog.Record, dict *zerolog.Event, top []slog.Attr) {
+	evt := h.startRecord(ctx, rec)
+	if evt == nil {
+		return
+	}
+	var extra []slog.Attr
+	if len(h.levelAttrs) > 0 {
+		attrs := getAttrs()
+		defer putAttrs(attrs)
+		*attrs = h.appendLevelAttrs(*attrs, rec.Level)
+		extra = *attrs
+	}
+	if dict == nil {
+		group = ""
+	}
+	reporter := reporterFromContext(ctx)
+	if h.opts.mergesAttrs() {
+		fields := h.strictAttrs(h.pending, "", extra, 0, group)
+		if reporter != nil {
+			for i, a := range fields {
+				fields[i] = reporter.attr(nil, a)
+			}
+		}
+		h.writeFields(evt, fields, group, dict)
+	} else {
+		var over overflowAttrs
+		for _, a := range extra {
+			if a, ok := h.pipe.attr("", a); ok && !h.pipe.overflow(&over, a) {
+				mapAttr(evt, h.limits, reporter.attr(nil, h.tag(a, originLevel)))
+			}
+		}
+		if a, ok := over.attr(); ok {
+			mapAttr(evt, h.limits, reporter.attr(nil, h.tag(a, originLevel)))
+		}
+		if dict != nil {
+			evt.Dict(group, dict)
+		}
+	}
+	if len(h.defaults) > 0 {
+		h.writeDefaults(evt, group, extra)
+	}
+	h.endLog(rec, evt, top)
 }
 
 // Handle implements slog.Handler.
-func (h *Handler) Handle(_ context.Context, rec slog.Record) error {
-	evt := h.startLog(rec.Level)
+func (h *Handler) Handle(ctx context.Context, rec slog.Record) error {
+	if h.isZero() {
+		return nil
+	}
+	if !h.filtered {
+		return h.emit(ctx, &rec)
+	}
+	h.config.emit()
+	if h.stats.latency != nil {
+		defer h.stats.observeSince(time.Now())
+	}
+	if dropped, err := h.filter(ctx, &rec, h.override, "", h.Schema); dropped {
+		return err
+	}
+	return h.emit(ctx, &rec)
+}
+
+// filter applies the options filtering or delaying rec before it's written, and reports whether it's dropped,
+// with the error Handle returns. prefix is the group path of the handler, override the AttrLevelOverrides
+// level selected by its WithAttrs attributes, and schema its Schema method, for EmitSchemaOnStart.
+func (h *Handler) filter(ctx context.Context, rec *slog.Record, override slog.Leveler, prefix string, schema func() map[string]string) (bool, error) {
+	if h.opts.StrictEmission {
+		if err := h.emissionError(rec.Level); err != nil {
+			return true, err
+		}
+	}
+	if !h.shouldEmit(rec.Level) {
+		return true, nil
+	}
+	if h.opts.AttrLevelOverrides.enabled() && !h.boostEmits(rec.Level) {
+		if dropped, err := h.overrideDrop(override, prefix, rec); dropped {
+			return true, err
+		}
+	}
+	if h.messages.dropped(h.stats, rec) || h.suppress.suppressed(rec) || !h.waitQueue(ctx) {
+		return true, nil
+	}
+	h.schema.emit(h.logger, schema)
+	return false, nil
+}
+
+// emit writes rec to the logger.
+func (h *Handler) emit(ctx context.Context, rec *slog.Record) error {
+	if h.opts.ExpandMessage {
+		rec.Message = expandMessage(rec.Message, rec)
+	}
+	reporter, ctx := h.recordReporter(ctx, nil, rec)
+	marks := recordMarkers(rec)
+	evt := h.startRecord(marks.context(ctx), rec)
+	if evt == nil {
+		return nil
+	}
+	if !h.buffersAttrs() && len(h.levelAttrs) == 0 && len(h.defaults) == 0 {
+		h.streamAttrs(evt, reporter, rec, marks.found())
+		h.endLog(rec, evt, nil)
+		return nil
+	}
+	// Attributes are materialized once, as audit checks walk them again.
+	attrs := getAttrs()
+	defer putAttrs(attrs)
+	*attrs = appendResolvedAttrs(*attrs, rec)
+	if marks.found() {
+		*attrs = slices.DeleteFunc(*attrs, isMarker)
+	}
+	h.sampler.sample("", rec, *attrs)
+	n := len(*attrs)
+	*attrs = h.appendLevelAttrs(*attrs, rec.Level)
+	if h.opts.mergesAttrs() {
+		fields := h.strictAttrs(h.pending, "", *attrs, n, "")
+		reporter.attrs(fields)
+		h.writeFields(evt, fields, "", nil)
+	} else {
+		dup := h.duplicateKeys()
+		defer dup.release()
+		dup.context("", h.ctxAttrs)
+		var over overflowAttrs
+		for i, a := range *attrs {
+			if a, ok := h.pipe.attr("", a); ok && !h.pipe.overflow(&over, a) {
+				dup.attr("", a)
+				mapAttr(evt, h.limits, reporter.attr(nil, h.tag(a, recordOrigin(i, n))))
+			}
+		}
+		if a, ok := over.attr(); ok {
+			dup.attr("", a)
+			mapAttr(evt, h.limits, reporter.attr(nil, h.tag(a, originRecord)))
+		}
+	}
+	if len(h.defaults) > 0 {
+		h.writeDefaults(evt, "", *attrs)
+	}
+	h.endLog(rec, evt, h.audit(h.keys, "", rec, (*attrs)[:n]))
+	return nil
+}
+
+// streamAttrs writes the attributes of rec to evt as they're walked, skipping the markers if skipMarkers is set.
+func (h *Handler) streamAttrs(evt *zerolog.Event, reporter *recordReporter, rec *slog.Record, skipMarkers bool) {
+	dup := h.duplicateKeys()
+	if dup == nil && reporter == nil && !h.opts.TagProvenance && h.pipe.empty() {
+		// Nothing transforms nor observes attributes.
+		rec.Attrs(func(a slog.Attr) bool {
+			if !skipMarkers || !isMarker(a) {
+				mapAttr(evt, h.limits, resolveAttr(a))
+			}
+			return true
+		})
+		return
+	}
+	defer dup.release()
+	dup.context("", h.ctxAttrs)
+	var over overflowAttrs
+	write := func(a slog.Attr) {
+		dup.attr("", a)
+		mapAttr(evt, h.limits, reporter.attr(nil, h.tag(a, originRecord)))
+	}
 	rec.Attrs(func(a slog.Attr) bool {
-		mapAttr(evt, a)
+		if skipMarkers && isMarker(a) {
+			return true
+		}
+		if a, ok := h.pipe.attr("", resolveAttr(a)); ok && !h.pipe.overflow(&over, a) {
+			write(a)
+		}
 		return true
 	})
-	h.endLog(&rec, evt)
-	return nil
+	if a, ok := over.attr(); ok {
+		write(a)
+	}
+}
+
+// buffersAttrs reports whether options need all the attributes of records at once, like audit checks, in which
+// case they're materialized before being written, rather than written as they're walked.
+func (h *Handler) buffersAttrs() bool {
+	return h.opts.mergesAttrs() || h.sampler != nil || len(h.opts.AuditKeys) > 0
 }
 
 // WithAttrs implements slog.Handler.
+//
+// Attribute values are resolved once, when WithAttrs is called. Without attributes, or with only empty ones,
+// WithAttrs returns h itself.
 func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	return &Handler{
-		opts:   h.opts,
-		logger: mapAttrs(h.logger.With(), attrs...).Logger(),
+	if h.isZero() {
+		return h
+	}
+	attrs = withoutEmpty(resolveAttrs(attrs))
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	if ctx, ok := h.pending.applied(); ok {
+		// Start over from the parent's context, which is already built.
+		h2.logger = ctx.Logger()
+		h2.pending = pendingAttrs{}
 	}
+	written := h.pipe.attrs("", attrs)
+	h2.pending = h2.pending.add(h.tagAll(written, originWith))
+	if h.opts.WarnOnDuplicateKeys {
+		h2.ctxAttrs = h.ctxAttrs.add(written)
+	}
+	h2.keys = h.auditKeys(h.keys, "", attrs)
+	h2.defaults = withoutProvided(h.defaults, attrs, h.opts.StrictSlogCompliance)
+	h2.chain = h.chain.attrs(attrs)
+	if h.opts.AttrLevelOverrides.enabled() {
+		if level, ok := h.opts.AttrLevelOverrides.lookup("", attrs); ok {
+			h2.override = level
+		}
+	}
+	return &h2
 }
 
 // WithGroup implements slog.Handler.
+//
+// Group names are trimmed of surrounding spaces, and an empty name returns h itself, as if no group was added.
 func (h *Handler) WithGroup(name string) slog.Handler {
+	name = strings.TrimSpace(name)
+	if h.isZero() || name == "" {
+		return h
+	}
 	return &groupHandler{
-		parent: h,
-		ctx:    h.logger.With().Reset(),
-		name:   strings.TrimSpace(name),
+		parent:   h,
+		root:     h,
+		ctx:      h.logger.With().Reset(),
+		name:     name,
+		prefix:   name + ".",
+		keys:     h.keys,
+		chain:    h.chain.group(name),
+		override: h.override,
 	}
 }
 
 // groupHandler handles groups and subgroups.
 type groupHandler struct {
 	parent zerologHandler
-	ctx    zerolog.Context
-	name   string
+	root   *Handler
+	// ctx is the context of the group, without the pending attributes.
+	ctx     zerolog.Context
+	pending pendingAttrs
+	name    string
+	// prefix is the dot-joined group path, including the trailing dot.
+	prefix string
+	// keys are the flattened keys added with WithAttrs, only tracked for audit purposes.
+	keys []string
+	// ctxAttrs are the attributes added with WithAttrs, only tracked for WarnOnDuplicateKeys.
+	ctxAttrs pendingAttrs
+	// hasAttrs is true if attributes were written into the group with WithAttrs.
+	hasAttrs bool
+	// chain is the fingerprint of the attributes and groups added to the handler.
+	chain fingerprint
+	// override is the AttrLevelOverrides level selected by the attributes added with WithAttrs, if any.
+	override slog.Leveler
 }
 
 var _ zerologHandler = (*groupHandler)(nil)
 
 // Enabled implements slog.Handler.
 func (h *groupHandler) Enabled(ctx context.Context, lvl slog.Level) bool {
-	return h.parent.Enabled(ctx, lvl)
+	return h.root.Enabled(ctx, lvl)
+}
+
+// MinLevel returns the minimum level of the records written by the handler.
+// See Handler.MinLevel.
+func (h *groupHandler) MinLevel() slog.Level {
+	return h.root.MinLevel()
+}
+
+// shouldEmit implements zerologHandler.
+func (h *groupHandler) shouldEmit(lvl slog.Level) bool {
+	return h.parent.shouldEmit(lvl)
 }
 
 // handleGroup handles records comming from a child group.
-func (h *groupHandler) handleGroup(group string, rec *slog.Record, dict *zerolog.Event) {
-	l := h.ctx.Logger()
-	evt := l.Log()
-	evt.Dict(group, dict)
-	h.parent.handleGroup(h.name, rec, evt)
+// dict is nil if the group is empty, and so is the event passed to the parent if h is empty too.
+func (h *groupHandler) handleGroup(ctx context.Context, group string, rec *slog.Record, dict *zerolog.Event, top []slog.Attr) {
+	if !h.root.opts.mergesAttrs() {
+		var evt *zerolog.Event
+		if dict != nil || h.hasAttrs {
+			l := h.groupLogger()
+			evt = l.Log()
+			if dict != nil {
+				evt.Dict(group, dict)
+			}
+		}
+		h.parent.handleGroup(ctx, h.name, rec, evt, top)
+		return
+	}
+	if dict == nil {
+		group = ""
+	}
+	fields := h.root.strictAttrs(h.pending, h.prefix, nil, 0, group)
+	var evt *zerolog.Event
+	if !isEmptyGroup(fields) || dict != nil {
+		l := h.groupLogger()
+		evt = mapAttrs(l.Log(), h.root.limits, fields...)
+		if dict != nil {
+			evt.Dict(group, dict)
+		}
+	}
+	h.parent.handleGroup(ctx, h.name, rec, evt, top)
 }
 
 // Handle implements slog.Handler.
+//
+// Records without attributes are handled by the closest parent having attributes, so that groups
+// added speculatively, and left empty, are neither written nor walked.
 func (h *groupHandler) Handle(ctx context.Context, rec slog.Record) error {
-	l := h.ctx.Logger()
-	evt := l.Log()
-	rec.Attrs(func(a slog.Attr) bool {
-		mapAttr(evt, a)
-		return true
-	})
-	h.parent.handleGroup(h.name, &rec, evt)
+	if !h.hasAttrs && (rec.NumAttrs() == 0 || isOnlyMarkers(&rec)) {
+		return h.nonEmptyParent().Handle(ctx, rec)
+	}
+	if h.root.filtered {
+		h.root.config.emit()
+		if h.root.stats.latency != nil {
+			defer h.root.stats.observeSince(time.Now())
+		}
+		if dropped, err := h.root.filter(ctx, &rec, h.override, h.prefix, h.Schema); dropped {
+			return err
+		}
+	} else if !h.root.shouldEmit(rec.Level) {
+		return nil
+	}
+	if h.root.opts.ExpandMessage {
+		rec.Message = expandMessage(rec.Message, &rec)
+	}
+	// Parents only receive the record envelope, so that they can't walk, and resolve, the attributes again.
+	// Attributes are only materialized for options needing them all at once.
+	var attrs []slog.Attr
+	var marks markers
+	buffered := h.root.buffersAttrs()
+	if buffered {
+		buf := getAttrs()
+		defer putAttrs(buf)
+		*buf, marks = removeMarkers(appendResolvedAttrs(*buf, &rec))
+		attrs = *buf
+		h.root.sampler.sample(h.prefix, &rec, attrs)
+	}
+	var reporter *recordReporter
+	var groups []string
+	if h.root.opts.OnRecordError != nil {
+		groups = h.groupNames()
+		reporter, ctx = h.root.recordReporter(ctx, groups, &rec)
+	}
+	var evt *zerolog.Event
+	if h.root.opts.mergesAttrs() {
+		if fields := h.root.strictAttrs(h.pending, h.prefix, attrs, len(attrs), ""); !isEmptyGroup(fields) {
+			reporter.attrs(fields)
+			l := h.groupLogger()
+			evt = mapAttrs(l.Log(), h.root.limits, fields...)
+		}
+	} else {
+		dup := h.root.duplicateKeys()
+		defer dup.release()
+		dup.groups(h)
+		l := h.groupLogger()
+		evt = l.Log()
+		// The group is omitted if it's left without attributes.
+		empty := !h.hasAttrs
+		var over overflowAttrs
+		put := func(a slog.Attr) {
+			dup.attr(h.prefix, a)
+			mapAttr(evt, h.root.limits, reporter.attr(groups, h.root.tag(a, originRecord)))
+			empty = empty && isEmptyAttr(a.Key, a.Value.Resolve())
+		}
+		write := func(a slog.Attr) {
+			if a, ok := h.root.pipe.attr(h.prefix, a); ok && !h.root.pipe.overflow(&over, a) {
+				put(a)
+			}
+		}
+		if buffered {
+			for _, a := range attrs {
+				write(a)
+			}
+		} else {
+			// Markers are collected in the same walk, as they only matter to parents.
+			used := markersUsed.Load()
+			rec.Attrs(func(a slog.Attr) bool {
+				if !used || !marks.add(a) {
+					write(resolveAttr(a))
+				}
+				return true
+			})
+		}
+		if a, ok := over.attr(); ok {
+			put(a)
+		}
+		if empty {
+			evt = nil
+		}
+	}
+	top := h.root.audit(h.keys, h.prefix, &rec, attrs)
+	env := recordEnvelope(rec)
+	h.parent.handleGroup(marks.context(ctx), h.name, &env, evt, top)
 	return nil
 }
 
-// WithAttrs implements slog.Handler.
+// nonEmptyParent returns the closest parent of h having attributes added with WithAttrs, or the root handler.
+func (h *groupHandler) nonEmptyParent() zerologHandler {
+	parent := h.parent
+	for g, ok := parent.(*groupHandler); ok && !g.hasAttrs; g, ok = parent.(*groupHandler) {
+		parent = g.parent
+	}
+	return parent
+}
+
+// groupLogger returns a logger whose context holds the attributes of the group.
+// With StrictSlogCompliance or DedupKeys, these attributes are written with the record ones instead.
+func (h *groupHandler) groupLogger() zerolog.Logger {
+	if h.pending.last == nil || h.root.opts.mergesAttrs() {
+		return h.ctx.Logger()
+	}
+	return h.pending.apply(h.ctx.Logger(), h.root.limits).Logger()
+}
+
+// WithAttrs implements slog.Handler. See Handler.WithAttrs.
 func (h *groupHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	return &groupHandler{
-		parent: h.parent,
-		ctx:    mapAttrs(h.ctx.Logger().With().Reset(), attrs...),
-		name:   h.name,
+	attrs = withoutEmpty(resolveAttrs(attrs))
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	if ctx, ok := h.pending.applied(); ok {
+		// Start over from the parent's context, which is already built.
+		h2.ctx = ctx
+		h2.pending = pendingAttrs{}
 	}
+	written := h.root.pipe.attrs(h.prefix, attrs)
+	h2.pending = h2.pending.add(h.root.tagAll(written, originWith))
+	h2.hasAttrs = h.hasAttrs || !isEmptyGroup(written)
+	if h.root.opts.WarnOnDuplicateKeys {
+		h2.ctxAttrs = h.ctxAttrs.add(written)
+	}
+	h2.keys = h.root.auditKeys(h.keys, h.prefix, attrs)
+	h2.chain = h.chain.attrs(attrs)
+	if h.root.opts.AttrLevelOverrides.enabled() {
+		if level, ok := h.root.opts.AttrLevelOverrides.lookup(h.prefix, attrs); ok {
+			h2.override = level
+		}
+	}
+	return &h2
 }
 
-// WithGroup implements slog.Handler.
+// WithGroup implements slog.Handler. See Handler.WithGroup.
 func (h *groupHandler) WithGroup(name string) slog.Handler {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return h
+	}
 	return &groupHandler{
-		parent: h,
-		ctx:    h.ctx.Logger().With().Reset(),
-		name:   name,
+		parent:   h,
+		root:     h.root,
+		ctx:      h.ctx.Logger().With().Reset(),
+		name:     name,
+		prefix:   h.prefix + name + ".",
+		keys:     h.keys,
+		chain:    h.chain.group(name),
+		override: h.override,
 	}
 }
 
@@ -225,21 +1256,47 @@ var (
 )
 
 // mapAttrs writes multiple slog.Attr into the target which is either a zerolog.Context
-// or a *zerolog.Event.
-func mapAttrs[T zlogWriter[T]](target T, a ...slog.Attr) T {
+// or a *zerolog.Event, with groups and containers bounded by lim.
+func mapAttrs[T zlogWriter[T]](target T, lim attrLimits, a ...slog.Attr) T {
 	for _, attr := range a {
-		target = mapAttr(target, attr)
+		target = mapAttrDepth(target, lim, attr, 0)
+	}
+	return target
+}
+
+// mapGroupDepth writes the members of a group enclosed in depth groups or containers into the target,
+// followed by the number of omitted members if there are more than allowed by lim.
+func mapGroupDepth[T zlogWriter[T]](target T, lim attrLimits, depth int, members []slog.Attr) T {
+	n, omitted := lim.truncated(len(members))
+	for _, attr := range members[:n] {
+		target = mapAttrDepth(target, lim, attr, depth)
+	}
+	if omitted > 0 {
+		target = target.Int64(TruncatedValue, int64(omitted))
 	}
 	return target
 }
 
 // mapAttr writes slog.Attr into the target which is either a zerolog.Context
-// or a *zerolog.Event.
-func mapAttr[T zlogWriter[T]](target T, a slog.Attr) T {
+// or a *zerolog.Event, with groups and containers bounded by lim.
+func mapAttr[T zlogWriter[T]](target T, lim attrLimits, a slog.Attr) T {
+	return mapAttrDepth(target, lim, a, 0)
+}
+
+// mapAttrDepth writes slog.Attr enclosed in depth groups or containers into the target.
+// Empty attributes are ignored, as required by slog.Handler. Groups and containers nested deeper
+// than lim allows are written as TruncatedValue.
+func mapAttrDepth[T zlogWriter[T]](target T, lim attrLimits, a slog.Attr, depth int) T {
 	value := a.Value.Resolve()
+	if isEmptyAttr(a.Key, value) {
+		return target
+	}
 	switch value.Kind() {
 	case slog.KindGroup:
-		return target.Dict(a.Key, mapAttrs(zerolog.Dict(), value.Group()...))
+		if lim.tooDeep(depth) {
+			return target.Str(a.Key, TruncatedValue)
+		}
+		return target.Dict(a.Key, mapGroupDepth(zerolog.Dict(), lim, depth+1, value.Group()))
 	case slog.KindBool:
 		return target.Bool(a.Key, value.Bool())
 	case slog.KindDuration:
@@ -257,12 +1314,28 @@ func mapAttr[T zlogWriter[T]](target T, a slog.Attr) T {
 	case slog.KindAny:
 		fallthrough
 	default:
-		return mapAttrAny(target, a.Key, value.Any())
+		return mapAttrAny(target, lim, a.Key, value.Any(), depth)
 	}
 }
 
-func mapAttrAny[T zlogWriter[T]](target T, key string, value any) T {
+// mapAttrAny writes a value of slog.KindAny into the target. slog values in slices and maps,
+// like group values or LogValuers, are written like attributes of the same value, and slices of
+// Stringers or TextMarshalers, like enums, like arrays of single values of the same type.
+// Sources are written like the source of records with SourceString, unless sourceStage converted them.
+// depth is the number of groups or containers enclosing the value.
+func mapAttrAny[T zlogWriter[T]](target T, lim attrLimits, key string, value any, depth int) T {
 	switch v := value.(type) {
+	case []slog.Value, []any, map[string]any:
+		return target.Interface(key, jsonValue(v, lim, depth))
+	case sourceValue:
+		return writeSourceFields(target, key, v.format, v.src.Function, v.src.File, v.src.Line)
+	case *slog.Source:
+		if v == nil {
+			return target.Interface(key, nil)
+		}
+		return writeSourceFields(target, key, SourceString, v.Function, v.File, v.Line)
+	case slog.Source:
+		return writeSourceFields(target, key, SourceString, v.Function, v.File, v.Line)
 	case net.IP:
 		return target.IPAddr(key, v)
 	case net.IPNet:
@@ -286,12 +1359,25 @@ func mapAttrAny[T zlogWriter[T]](target T, key string, value any) T {
 		}
 		return target.Str(key, "!ERROR:"+err.Error())
 	default:
+		if texts, ok := textSlice(value); ok {
+			return target.Interface(key, texts)
+		}
 		return target.Interface(key, value)
 	}
 }
 
-// zerologLevel maps slog.Level into zerolog.Level.
-func zerologLevel(lvl slog.Level) zerolog.Level {
+// LevelTrace is the level below slog.LevelDebug, mapped to zerolog.TraceLevel.
+const LevelTrace slog.Level = slog.LevelDebug - 4
+
+// Levels above slog.LevelError, mapped to zerolog.FatalLevel and zerolog.PanicLevel.
+// Records at those levels are only logged: the handler never exits nor panics.
+const (
+	LevelFatal slog.Level = slog.LevelError + 4
+	LevelPanic slog.Level = slog.LevelError + 8
+)
+
+// ZerologLevel maps slog.Level into zerolog.Level.
+func ZerologLevel(lvl slog.Level) zerolog.Level {
 	switch {
 	case lvl < slog.LevelDebug:
 		return zerolog.TraceLevel
@@ -301,7 +1387,40 @@ func zerologLevel(lvl slog.Level) zerolog.Level {
 		return zerolog.InfoLevel
 	case lvl < slog.LevelError:
 		return zerolog.WarnLevel
-	default:
+	case lvl < LevelFatal:
 		return zerolog.ErrorLevel
+	case lvl < LevelPanic:
+		return zerolog.FatalLevel
+	default:
+		return zerolog.PanicLevel
+	}
+}
+
+// SlogLevel maps zerolog.Level into slog.Level.
+// zerolog.NoLevel is mapped to LevelTrace, and zerolog.Disabled
+// to the highest possible slog.Level.
+func SlogLevel(lvl zerolog.Level) slog.Level {
+	switch lvl {
+	case zerolog.TraceLevel, zerolog.NoLevel:
+		return LevelTrace
+	case zerolog.DebugLevel:
+		return slog.LevelDebug
+	case zerolog.InfoLevel:
+		return slog.LevelInfo
+	case zerolog.WarnLevel:
+		return slog.LevelWarn
+	case zerolog.ErrorLevel:
+		return slog.LevelError
+	case zerolog.FatalLevel:
+		return LevelFatal
+	case zerolog.PanicLevel:
+		return LevelPanic
+	case zerolog.Disabled:
+		return slog.Level(math.MaxInt)
+	default:
+		if lvl < zerolog.TraceLevel {
+			return LevelTrace
+		}
+		return slog.Level(math.MaxInt)
 	}
 }
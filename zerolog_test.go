@@ -11,6 +11,7 @@ import (
 	"net"
 	"reflect"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -165,6 +166,239 @@ func TestZerolog_Levels_NoOption(t *testing.T) {
 	}
 }
 
+func TestZerolog_Levels_Disabled(t *testing.T) {
+	for _, opts := range []*HandlerOptions{nil, {Level: slog.LevelDebug - 4}} {
+		out := bytes.Buffer{}
+		hdl := NewHandler(zerolog.New(&out).Level(zerolog.Disabled), opts)
+		for _, l := range levels {
+			if hdl.Enabled(nil, l.slvl) {
+				t.Fatalf("Level %s must be disabled", l.slvl)
+			}
+			hdl.Handle(nil, slog.NewRecord(time.Now(), l.slvl, "foobar", 0))
+			if out.Len() > 0 {
+				t.Fatalf("Unexpected output for level %s: %q", l.slvl, out.String())
+			}
+		}
+	}
+}
+
+func TestZerolog_Levels_NoLevel(t *testing.T) {
+	for _, l := range levels {
+		out := bytes.Buffer{}
+		hdl := NewHandler(zerolog.New(&out).Level(zerolog.NoLevel), nil)
+		if !hdl.Enabled(nil, l.slvl) {
+			t.Fatalf("Level %s must be enabled", l.slvl)
+		}
+		hdl.Handle(nil, slog.NewRecord(time.Now(), l.slvl, "foobar", 0))
+		m := map[string]any{}
+		if err := json.NewDecoder(&out).Decode(&m); err != nil {
+			t.Fatalf("Failed to json decode log output: %s", err.Error())
+		}
+		if m[zerolog.LevelFieldName] != l.zlvl.String() {
+			t.Fatalf("Unexpected value for field %s. Got %s but expected %s", zerolog.LevelFieldName, m[zerolog.LevelFieldName], l.zlvl.String())
+		}
+	}
+}
+
+func TestZerolog_MinLevel(t *testing.T) {
+	for zlvl, slvl := range map[zerolog.Level]slog.Level{
+		zerolog.TraceLevel: slog.LevelDebug - 4,
+		zerolog.DebugLevel: slog.LevelDebug,
+		zerolog.InfoLevel:  slog.LevelInfo,
+		zerolog.WarnLevel:  slog.LevelWarn,
+		zerolog.ErrorLevel: slog.LevelError,
+	} {
+		hdl := NewHandler(zerolog.New(io.Discard).Level(zlvl), nil)
+		if lvl := hdl.MinLevel(); lvl != slvl {
+			t.Errorf("Unexpected min level %s for logger level %s", lvl, zlvl)
+		}
+	}
+	if lvl := NewHandler(zerolog.New(io.Discard).Level(zerolog.NoLevel), nil).MinLevel(); lvl != slog.LevelDebug-4 {
+		t.Errorf("Unexpected min level %s for logger without level", lvl)
+	}
+	if lvl := NewHandler(zerolog.New(io.Discard).Level(zerolog.Disabled), &HandlerOptions{Level: slog.LevelDebug}).MinLevel(); lvl != SlogLevel(zerolog.Disabled) {
+		t.Errorf("Unexpected min level %s for disabled logger", lvl)
+	}
+
+	lvl := &slog.LevelVar{}
+	hdl := NewHandler(zerolog.New(io.Discard).Level(zerolog.ErrorLevel), &HandlerOptions{Level: lvl})
+	group := hdl.WithGroup("g").(interface{ MinLevel() slog.Level })
+	for _, l := range []slog.Level{slog.LevelDebug, slog.LevelWarn, slog.LevelInfo + 2} {
+		lvl.Set(l)
+		if got := hdl.MinLevel(); got != l {
+			t.Errorf("Unexpected min level %s, expected %s", got, l)
+		}
+		if got := group.MinLevel(); got != l {
+			t.Errorf("Unexpected group min level %s, expected %s", got, l)
+		}
+	}
+
+	prev := zerolog.GlobalLevel()
+	defer zerolog.SetGlobalLevel(prev)
+	zerolog.SetGlobalLevel(zerolog.WarnLevel)
+	lvl.Set(slog.LevelDebug)
+	if got := hdl.MinLevel(); got != slog.LevelWarn {
+		t.Errorf("Unexpected min level %s with global level %s", got, zerolog.WarnLevel)
+	}
+}
+
+func TestZerolog_OmitLevel(t *testing.T) {
+	for _, l := range levels {
+		for _, opts := range []*HandlerOptions{{OmitLevel: true}, {OmitLevel: true, Level: slog.LevelWarn}} {
+			out := bytes.Buffer{}
+			hdl := NewHandler(zerolog.New(&out).Level(zerolog.InfoLevel), opts)
+			ref := NewHandler(zerolog.New(io.Discard).Level(zerolog.InfoLevel), &HandlerOptions{Level: opts.Level})
+			if hdl.Enabled(nil, l.slvl) != ref.Enabled(nil, l.slvl) {
+				t.Fatalf("OmitLevel changed Enabled for level %s", l.slvl)
+			}
+			hdl.WithGroup("g").Handle(nil, slog.NewRecord(now, l.slvl, "foobar", 0))
+			if !ref.Enabled(nil, l.slvl) {
+				if out.Len() > 0 {
+					t.Fatalf("Unexpected output for level %s: %q", l.slvl, out.String())
+				}
+				continue
+			}
+			m := map[string]any{}
+			if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+				t.Fatalf("Failed to json decode log output: %s", err.Error())
+			}
+			if _, ok := m[zerolog.LevelFieldName]; ok {
+				t.Fatalf("Unexpected level field in output: %q", out.String())
+			}
+			if m[zerolog.MessageFieldName] != "foobar" {
+				t.Fatalf("Unexpected output: %q", out.String())
+			}
+		}
+	}
+}
+
+type countingValuer struct{ count *int }
+
+func (v countingValuer) LogValue() slog.Value {
+	*v.count++
+	return slog.StringValue("counted")
+}
+
+func TestZerolog_Group_NoEmit(t *testing.T) {
+	for name, setup := range map[string]func() (zerolog.Logger, func()){
+		"disabled": func() (zerolog.Logger, func()) {
+			return zerolog.New(io.Discard).Level(zerolog.Disabled), func() {}
+		},
+		"global-level": func() (zerolog.Logger, func()) {
+			prev := zerolog.GlobalLevel()
+			zerolog.SetGlobalLevel(zerolog.Disabled)
+			return zerolog.New(io.Discard), func() { zerolog.SetGlobalLevel(prev) }
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			out := bytes.Buffer{}
+			logger, restore := setup()
+			defer restore()
+			count := 0
+			hdl := NewHandler(logger.Output(&out), &HandlerOptions{Level: slog.LevelDebug}).
+				WithGroup("g1").
+				WithAttrs([]slog.Attr{slog.String("foo", "bar")}).
+				WithGroup("g2")
+			rec := slog.NewRecord(now, slog.LevelError, "foobar", 0)
+			rec.AddAttrs(slog.Any("lazy", countingValuer{&count}), slog.Group("sub", slog.Int("a", 1)))
+			if err := hdl.Handle(nil, rec); err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if out.Len() > 0 {
+				t.Fatalf("Unexpected output: %q", out.String())
+			}
+			if count != 0 {
+				t.Fatalf("LogValuer resolved %d times for a record that is not emitted", count)
+			}
+		})
+	}
+}
+
+func TestZerolog_WithAttrs_ResolveOnce(t *testing.T) {
+	for name, derive := range map[string]func(slog.Handler, []slog.Attr) slog.Handler{
+		"root":  func(h slog.Handler, attrs []slog.Attr) slog.Handler { return h.WithAttrs(attrs) },
+		"group": func(h slog.Handler, attrs []slog.Attr) slog.Handler { return h.WithGroup("g").WithAttrs(attrs) },
+	} {
+		for hname, newHandler := range map[string]func(io.Writer) slog.Handler{
+			"json": func(out io.Writer) slog.Handler { return NewJsonHandler(out, nil) },
+			"audit": func(out io.Writer) slog.Handler {
+				return NewJsonHandler(out, &HandlerOptions{AuditKeys: []string{"actor"}})
+			},
+			"dual": func(out io.Writer) slog.Handler { return NewDualHandler(out, nil, io.Discard, nil, nil) },
+		} {
+			t.Run(name+"/"+hname, func(t *testing.T) {
+				out := bytes.Buffer{}
+				count := 0
+				hdl := derive(newHandler(&out), []slog.Attr{slog.Group("sub", slog.Any("lazy", countingValuer{&count}))})
+				if count != 1 {
+					t.Fatalf("LogValuer resolved %d times by WithAttrs, expected 1", count)
+				}
+				for i := 0; i < 3; i++ {
+					hdl.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "foobar", 0))
+				}
+				if count != 1 {
+					t.Fatalf("LogValuer resolved %d times, expected 1", count)
+				}
+				if n := strings.Count(out.String(), `"sub":{"lazy":"counted"}`); n != 3 {
+					t.Fatalf("Unexpected output: %s", out.String())
+				}
+			})
+		}
+	}
+}
+
+func TestZerolog_Group_WithAttrs_Chained(t *testing.T) {
+	out := bytes.Buffer{}
+	hdl := NewJsonHandler(&out, nil).WithGroup("g").WithAttrs([]slog.Attr{slog.Int("a", 1)}).WithAttrs([]slog.Attr{slog.Int("b", 2)})
+	hdl.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "foobar", 0))
+	if !strings.Contains(out.String(), `"g":{"a":1,"b":2}`) {
+		t.Fatalf("Unexpected output: %s", out.String())
+	}
+}
+
+func TestZerolog_Group_ResolveOnce(t *testing.T) {
+	for name, opts := range map[string]*HandlerOptions{
+		"default": nil,
+		"transforms": {
+			AllowKeys:    []string{"a.*"},
+			UnitCoercion: map[string]Unit{"_ms": UnitMilliseconds},
+			AuditKeys:    []string{"actor"},
+			AuditLevel:   slog.LevelInfo,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			out := bytes.Buffer{}
+			count := 0
+			hdl := NewJsonHandler(&out, opts).WithGroup("a").WithGroup("b")
+			rec := slog.NewRecord(now, slog.LevelInfo, "foobar", 0)
+			rec.AddAttrs(slog.Any("lazy", countingValuer{&count}))
+			if err := hdl.Handle(context.Background(), rec); err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if count != 1 {
+				t.Fatalf("LogValuer resolved %d times, expected 1", count)
+			}
+			if !strings.Contains(out.String(), `"a":{"b":{"lazy":"counted"}}`) {
+				t.Fatalf("Unexpected output: %s", out.String())
+			}
+		})
+	}
+}
+
+func TestSlogLevel(t *testing.T) {
+	for _, lvl := range levels {
+		if got := ZerologLevel(SlogLevel(lvl.zlvl)); got != lvl.zlvl {
+			t.Errorf("Level %s does not round-trip, got %s", lvl.zlvl, got)
+		}
+	}
+	if SlogLevel(zerolog.NoLevel) != SlogLevel(zerolog.TraceLevel) {
+		t.Errorf("NoLevel must be mapped to trace")
+	}
+	if SlogLevel(zerolog.Disabled) <= SlogLevel(zerolog.PanicLevel) {
+		t.Errorf("Disabled must be mapped above any other level")
+	}
+}
+
 func TestZerolog_NoGroup(t *testing.T) {
 	out := bytes.Buffer{}
 	hdl := NewJsonHandler(&out, nil).
@@ -235,6 +469,33 @@ func TestZerolog_Group(t *testing.T) {
 	}
 }
 
+func TestZerolog_Group_Empty(t *testing.T) {
+	for name, withGroups := range map[string]func(slog.Handler) slog.Handler{
+		"root": func(h slog.Handler) slog.Handler { return h.WithGroup("req").WithGroup("sub") },
+		"parent-attrs": func(h slog.Handler) slog.Handler {
+			return h.WithGroup("a").WithAttrs([]slog.Attr{slog.Int("n", 1)}).WithGroup("req").WithGroup("sub")
+		},
+	} {
+		for _, strict := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/strict=%t", name, strict), func(t *testing.T) {
+				var got, exp bytes.Buffer
+				opts := &HandlerOptions{StrictSlogCompliance: strict}
+				base := NewJsonHandler(&exp, opts).WithAttrs([]slog.Attr{slog.String("svc", "api")})
+				if name == "parent-attrs" {
+					base = base.WithGroup("a").WithAttrs([]slog.Attr{slog.Int("n", 1)})
+				}
+				base.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "foobar", 0))
+
+				hdl := withGroups(NewJsonHandler(&got, opts).WithAttrs([]slog.Attr{slog.String("svc", "api")}))
+				hdl.Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "foobar", 0))
+				if got.String() != exp.String() {
+					t.Errorf("Got %s, expected %s", got.String(), exp.String())
+				}
+			})
+		}
+	}
+}
+
 // TestZerolog_ZeroTime verifies that a zero timestamp will not be logged.
 //   - "- If r.Time is the zero time, ignore the time."
 //   - https://pkg.go.dev/log/slog@master#Handler
@@ -291,6 +552,31 @@ func TestZerolog_ConsoleHandler(t *testing.T) {
 	}
 }
 
+func TestZerolog_ConsoleHandlerTimeFormat(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	now := time.Date(2024, 1, 2, 15, 4, 5, 123456789, time.Local)
+	for name, tc := range map[string]struct {
+		hdl func(io.Writer) *Handler
+		exp string
+	}{
+		"default": {func(w io.Writer) *Handler { return NewConsoleHandler(w, nil) }, "2024-01-02 15:04:05 INF"},
+		"milli": {func(w io.Writer) *Handler {
+			return NewConsoleHandlerWithTimeFormat(w, time.StampMilli, nil)
+		}, "Jan  2 15:04:05.123 INF"},
+		"nano": {func(w io.Writer) *Handler {
+			return NewConsoleHandlerWithTimeFormat(w, "15:04:05.000000000", nil)
+		}, "15:04:05.123456789 INF"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			out := bytes.Buffer{}
+			tc.hdl(&out).WithGroup("g").Handle(context.Background(), slog.NewRecord(now, slog.LevelInfo, "foobar", 0))
+			if txt := out.String(); !strings.HasPrefix(txt, tc.exp+" foobar") {
+				t.Errorf("Unexpected console output %q, expected prefix %q", txt, tc.exp)
+			}
+		})
+	}
+}
+
 // TestHandler uses slogtest.TestHandler from stdlib to validate
 // the zerolog handler implementation.
 func TestHandler(t *testing.T) {
@@ -310,3 +596,274 @@ func TestHandler(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+type ctxKey struct{}
+
+type countingHook struct{ count int }
+
+func (h *countingHook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	h.count++
+	if v, ok := e.GetCtx().Value(ctxKey{}).(string); ok {
+		e.Str("trace_id", v)
+	}
+}
+
+func TestZerolog_Hooks(t *testing.T) {
+	out := bytes.Buffer{}
+	hook := &countingHook{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "abc")
+	hdl := NewJsonHandler(&out, &HandlerOptions{Hooks: []zerolog.Hook{hook}})
+	grouped := hdl.WithGroup("g1").WithAttrs([]slog.Attr{slog.Int("a", 1)}).WithGroup("g2")
+
+	for i, h := range []slog.Handler{hdl, hdl.WithAttrs([]slog.Attr{slog.Int("b", 2)}), grouped} {
+		rec := slog.NewRecord(now, slog.LevelInfo, "foobar", 0)
+		rec.AddAttrs(slog.String("foo", "bar"))
+		if err := h.Handle(ctx, rec); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if hook.count != i+1 {
+			t.Fatalf("Hook expected to run %d times but ran %d times", i+1, hook.count)
+		}
+		m := map[string]any{}
+		if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+			t.Fatalf("Failed to json decode log output: %s", err.Error())
+		}
+		if m["trace_id"] != "abc" {
+			t.Fatalf("Missing field injected by hook in %v", m)
+		}
+		out.Reset()
+	}
+
+	hdl.Handle(ctx, slog.NewRecord(now, slog.LevelDebug, "filtered", 0))
+	if hook.count != 3 {
+		t.Fatalf("Hook must not run for filtered records")
+	}
+}
+
+func TestHandler_EmptyAttrs(t *testing.T) {
+	for _, dropEmptyKeys := range []bool{false, true} {
+		out := bytes.Buffer{}
+		logger := slog.New(NewJsonHandler(&out, &HandlerOptions{DropEmptyKeys: dropEmptyKeys})).
+			With(slog.Attr{}, "a", 1).
+			WithGroup("g").With(slog.Attr{})
+		logger.Info("msg", slog.Attr{}, slog.Any("", nil), "", 2, slog.Group("grp", slog.Attr{}, "b", 3))
+
+		m := map[string]any{}
+		if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+			t.Fatalf("Failed to json decode log output: %s", err.Error())
+		}
+		exp := map[string]any{"grp": map[string]any{"b": 3.0}}
+		if !dropEmptyKeys {
+			exp[""] = 2.0
+		}
+		if m["a"] != 1.0 || !jsonEqual(m["g"], exp) {
+			t.Errorf("DropEmptyKeys %t: unexpected output %s", dropEmptyKeys, out.String())
+		}
+	}
+}
+
+func TestHandler_Zero(t *testing.T) {
+	var zero Handler
+	ctx := context.Background()
+	handlers := map[string]slog.Handler{
+		"zero":      &zero,
+		"WithAttrs": zero.WithAttrs([]slog.Attr{slog.Int("a", 1)}),
+		"WithGroup": zero.WithGroup("g"),
+		"Named":     zero.Named("c"),
+		"mixed":     zero.WithGroup("g").WithAttrs([]slog.Attr{slog.Int("a", 1)}).WithGroup("h"),
+	}
+	for name, hdl := range handlers {
+		if hdl != slog.Handler(&zero) {
+			t.Errorf("%s: expected the zero handler itself, got %T", name, hdl)
+		}
+		for _, lvl := range []slog.Level{slog.LevelDebug, slog.LevelError, slog.Level(100)} {
+			if hdl.Enabled(ctx, lvl) {
+				t.Errorf("%s: level %s must not be enabled", name, lvl)
+			}
+		}
+		if err := hdl.Handle(ctx, slog.NewRecord(time.Now(), slog.LevelError, "msg", 0)); err != nil {
+			t.Errorf("%s: unexpected Handle error %v", name, err)
+		}
+	}
+
+	slog.New(&zero).With("a", 1).WithGroup("g").Error("msg", "b", 2)
+	if err := zero.HandleRaw(slog.LevelError, []byte(`not json`)); err != nil {
+		t.Errorf("unexpected HandleRaw error %v", err)
+	}
+	if _, err := zero.LevelWriter().WriteLevel(zerolog.ErrorLevel, []byte(`{"message":"msg"}`)); err != nil {
+		t.Errorf("unexpected LevelWriter error %v", err)
+	}
+	if lvl := zero.MinLevel(); lvl != SlogLevel(zerolog.Disabled) {
+		t.Errorf("unexpected MinLevel %s", lvl)
+	}
+	if s := zero.Stats(); !reflect.DeepEqual(s, Stats{}) {
+		t.Errorf("unexpected Stats %+v", s)
+	}
+	zero.Collector().Collect(func(name, _ string, value uint64) {
+		if value != 0 {
+			t.Errorf("unexpected counter %s = %d", name, value)
+		}
+	})
+	if schema := zero.Schema(); len(schema) != 0 {
+		t.Errorf("unexpected Schema %v", schema)
+	}
+	if problems := zero.Check(); len(problems) != 1 || problems[0].Code != ProblemZeroHandler {
+		t.Errorf("unexpected Check problems %v", problems)
+	}
+	if zero.Fingerprint() != (&Handler{}).Fingerprint() {
+		t.Errorf("Fingerprint of zero handlers must be stable")
+	}
+	if path := zero.GroupPath(); len(path) != 0 {
+		t.Errorf("unexpected GroupPath %v", path)
+	}
+	if err := zero.Warmup(ctx); err != nil {
+		t.Errorf("unexpected Warmup error %v", err)
+	}
+	if err := zero.Close(); err != nil {
+		t.Errorf("unexpected Close error %v", err)
+	}
+	if err := zero.Drain(ctx); err != nil {
+		t.Errorf("unexpected Drain error %v", err)
+	}
+}
+
+func TestHandler_EmptyGroups(t *testing.T) {
+	for _, strict := range []bool{false, true} {
+		for name, tc := range map[string]struct {
+			log func(*slog.Logger)
+			exp map[string]any
+		}{
+			"multi sub empty": {
+				log: func(l *slog.Logger) {
+					l.WithGroup("group").Info("msg", "first", "one", "second", 2, slog.Group("subGroup"))
+				},
+				exp: map[string]any{"group": map[string]any{"first": "one", "second": 2.0}},
+			},
+			"empty group": {
+				log: func(l *slog.Logger) { l.WithGroup("group").Info("msg", slog.Group("subGroup")) },
+				exp: map[string]any{},
+			},
+			"nested empty groups": {
+				log: func(l *slog.Logger) {
+					l.WithGroup("group").WithGroup("subGroup").Info("msg", slog.Group("a", slog.Group("b"), slog.Attr{}))
+				},
+				exp: map[string]any{},
+			},
+			"empty child group": {
+				log: func(l *slog.Logger) {
+					l.With("a", 1).WithGroup("group").With(slog.Group("empty")).WithGroup("subGroup").Info("msg", slog.Group("sub"))
+				},
+				exp: map[string]any{"a": 1.0},
+			},
+			"parent with attrs": {
+				log: func(l *slog.Logger) {
+					l.WithGroup("group").With("a", 1).WithGroup("subGroup").Info("msg", slog.Group("sub"))
+				},
+				exp: map[string]any{"group": map[string]any{"a": 1.0}},
+			},
+		} {
+			out := bytes.Buffer{}
+			tc.log(slog.New(NewJsonHandler(&out, &HandlerOptions{StrictSlogCompliance: strict})))
+			m := map[string]any{}
+			if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+				t.Fatalf("Failed to json decode log output: %s", err.Error())
+			}
+			for _, k := range []string{zerolog.LevelFieldName, zerolog.TimestampFieldName, zerolog.MessageFieldName, slog.LevelKey, slog.TimeKey, slog.MessageKey} {
+				delete(m, k)
+			}
+			if !jsonEqual(m, tc.exp) {
+				t.Errorf("%s, strict %t: unexpected output %s", name, strict, out.String())
+			}
+		}
+	}
+}
+
+func TestHandler_WithEmptyGroup(t *testing.T) {
+	for _, strict := range []bool{false, true} {
+		out := bytes.Buffer{}
+		var h slog.Handler = NewJsonHandler(&out, &HandlerOptions{StrictSlogCompliance: strict})
+		if h.WithGroup("") != h || h.WithGroup("  ") != h {
+			t.Errorf("Strict %t: WithGroup with an empty name must return the handler itself", strict)
+		}
+		g := h.WithGroup("a")
+		if g.WithGroup("") != g {
+			t.Errorf("Strict %t: WithGroup with an empty name must return the group handler itself", strict)
+		}
+
+		logger := slog.New(h).With("x", 1).WithGroup("a").With("y", 2).WithGroup("").With("z", 3).WithGroup("b")
+		logger.Info("msg", "k", "v")
+		m := map[string]any{}
+		if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+			t.Fatalf("Failed to json decode log output: %s", err.Error())
+		}
+		exp := map[string]any{"y": 2.0, "z": 3.0, "b": map[string]any{"k": "v"}}
+		if m["x"] != 1.0 || !jsonEqual(m["a"], exp) {
+			t.Errorf("Strict %t: unexpected output %s", strict, out.String())
+		}
+	}
+}
+
+func TestHandler_WithEmptyAttrs(t *testing.T) {
+	var h slog.Handler = NewJsonHandler(io.Discard, nil)
+	g := h.WithGroup("g")
+	empty := []slog.Attr{{}, slog.Group("e"), slog.Group("", slog.Attr{})}
+	for _, hdl := range []slog.Handler{h, g} {
+		if hdl.WithAttrs(nil) != hdl || hdl.WithAttrs([]slog.Attr{}) != hdl || hdl.WithAttrs(empty) != hdl {
+			t.Errorf("WithAttrs without attributes must return %T itself", hdl)
+		}
+		if allocs := testing.AllocsPerRun(100, func() { hdl.WithAttrs(nil) }); allocs != 0 {
+			t.Errorf("WithAttrs without attributes on %T allocated %v times", hdl, allocs)
+		}
+		if hdl.WithAttrs([]slog.Attr{slog.Int("a", 1)}) == hdl {
+			t.Errorf("WithAttrs with attributes must return a new %T", hdl)
+		}
+	}
+}
+
+func TestNewHandler_LoggerUnchanged(t *testing.T) {
+	out := bytes.Buffer{}
+	hook := zerolog.HookFunc(func(e *zerolog.Event, level zerolog.Level, msg string) { e.Bool("hooked", true) })
+	logger := zerolog.New(&out).Level(zerolog.InfoLevel).With().Str("svc", "api").Int("n", 1).Logger().Hook(hook)
+	direct := func() []byte {
+		out.Reset()
+		logger.Debug().Msg("debug")
+		logger.Info().Str("k", "v").Msg("info")
+		logger.Warn().Dict("g", zerolog.Dict().Int("a", 1)).Send()
+		child := logger.With().Str("child", "c").Logger()
+		child.Error().Msg("error")
+		return slices.Clone(out.Bytes())
+	}
+	before := direct()
+
+	for i, opts := range []*HandlerOptions{
+		nil,
+		{Level: slog.LevelDebug},
+		{StrictSlogCompliance: true},
+		{DedupKeys: true, TagProvenance: true},
+		{Hooks: []zerolog.Hook{hook}, AddServiceName: true, ServiceName: "svc"},
+		{TrustLoggerTimestamps: true, AddSource: true, EnvelopeFirst: true},
+		{LogstashFormat: &LogstashOptions{AttrsKey: "fields"}, OmitLevel: true},
+		{AttrLevelOverrides: AttrLevelOverrides{Key: "tenant", Levels: map[string]slog.Leveler{"a": slog.LevelDebug}}},
+		{LevelAttrs: map[slog.Level]func() []slog.Attr{slog.LevelWarn: func() []slog.Attr { return []slog.Attr{slog.Int("lvl", 1)} }}},
+		{EmitSchemaOnStart: true, LogConfigOnFirstUse: true, DefaultAttrs: []slog.Attr{slog.String("def", "d")}},
+		{WarnOnDuplicateKeys: true, AddDeltaTime: true, ComponentKey: "component"},
+	} {
+		hdl := NewHandler(logger, opts)
+		cancel := hdl.BoostLevel(slog.LevelDebug-4, time.Minute)
+		hdl.Check()
+		hdl.Schema()
+		l := slog.New(hdl)
+		l.Debug("debug", "tenant", "a")
+		l.With("svc", "other", "tenant", "a").Info("info", "k", "v")
+		l.WithGroup("g").With("a", 1).WithGroup("h").Warn("warn", "svc", "x", "hooked", false)
+		l.With("component", "db").WithGroup("").With().Error("error", slog.Group("g", "a", 2))
+		sub := l.With("x", 1)
+		sub.Info("again")
+		hdl.Named("named").Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "named", 0))
+		cancel()
+
+		if after := direct(); !bytes.Equal(after, before) {
+			t.Fatalf("Options #%d: logging through the original logger changed from\n%s\nto\n%s", i, before, after)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+// Package zeroslogtest provides helpers to detect misuses of log/slog in test suites.
+package zeroslogtest
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// Check is a set of checks performed by a StrictHandler.
+type Check uint
+
+const (
+	// CheckDuplicateKeys fails the test when a record has several attributes with the same key in the
+	// same group, counting the attributes added with WithAttrs and the names of the groups added with WithGroup.
+	CheckDuplicateKeys Check = 1 << iota
+	// CheckReservedKeys fails the test when a top-level attribute has the key of a field written by handlers
+	// themselves: slog's built-in keys, and zerolog's field names.
+	CheckReservedKeys
+	// CheckUngroupedAttrs fails the test when an attribute has a dotted key starting with the name of a group it
+	// could have been added to, like "req.id" next to a "req" group, or inside the "req" group of WithGroup.
+	CheckUngroupedAttrs
+	// CheckSource fails the test when a record has no source, for handlers expected to write one with AddSource.
+	CheckSource
+	// CheckSingleLine fails the test when a message contains a newline, for handlers expected to write
+	// records on a single line.
+	CheckSingleLine
+
+	// DefaultChecks are the checks performed when none is given to NewStrictHandler. They don't depend
+	// on the configuration of the handler.
+	DefaultChecks = CheckDuplicateKeys | CheckReservedKeys | CheckUngroupedAttrs
+	// AllChecks are all the checks.
+	AllChecks = DefaultChecks | CheckSource | CheckSingleLine
+)
+
+// StrictHandler is a slog.Handler passing records to another handler, after checking that they follow
+// slog's conventions. Violations are reported with t.Errorf, and records are still passed to the handler.
+type StrictHandler struct {
+	inner  slog.Handler
+	t      testing.TB
+	checks Check
+	// scopes are the root scope, followed by a scope for each group added with WithGroup.
+	scopes []scope
+}
+
+// scope holds the attributes added with WithAttrs to a group.
+type scope struct {
+	group string
+	attrs []slog.Attr
+}
+
+var _ slog.Handler = (*StrictHandler)(nil)
+
+// NewStrictHandler creates a StrictHandler wrapping inner, and reporting violations to t. The checks
+// are combined, and default to DefaultChecks.
+func NewStrictHandler(inner slog.Handler, t testing.TB, checks ...Check) *StrictHandler {
+	h := &StrictHandler{inner: inner, t: t, scopes: []scope{{}}}
+	for _, c := range checks {
+		h.checks |= c
+	}
+	if len(checks) == 0 {
+		h.checks = DefaultChecks
+	}
+	return h
+}
+
+// Enabled implements slog.Handler.
+func (h *StrictHandler) Enabled(ctx context.Context, lvl slog.Level) bool {
+	return h.inner.Enabled(ctx, lvl)
+}
+
+// Handle implements slog.Handler.
+func (h *StrictHandler) Handle(ctx context.Context, rec slog.Record) error {
+	h.t.Helper()
+	if h.checks&CheckSource != 0 && rec.PC == 0 {
+		h.t.Errorf("zeroslogtest: record %q has no source", rec.Message)
+	}
+	if h.checks&CheckSingleLine != 0 && strings.ContainsAny(rec.Message, "\r\n") {
+		h.t.Errorf("zeroslogtest: message %q contains a newline", rec.Message)
+	}
+	if h.checks&(CheckDuplicateKeys|CheckReservedKeys|CheckUngroupedAttrs) != 0 {
+		attrs := make([]slog.Attr, 0, rec.NumAttrs())
+		rec.Attrs(func(a slog.Attr) bool {
+			attrs = append(attrs, a)
+			return true
+		})
+		h.checkScopes(rec.Message, attrs)
+	}
+	return h.inner.Handle(ctx, rec)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *StrictHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.inner = h.inner.WithAttrs(attrs)
+	h2.scopes = append([]scope(nil), h.scopes...)
+	last := &h2.scopes[len(h2.scopes)-1]
+	last.attrs = append(last.attrs[:len(last.attrs):len(last.attrs)], attrs...)
+	return &h2
+}
+
+// WithGroup implements slog.Handler.
+func (h *StrictHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.inner = h.inner.WithGroup(name)
+	h2.scopes = append(h.scopes[:len(h.scopes):len(h.scopes)], scope{group: name})
+	return &h2
+}
+
+// checkScopes checks the attributes of each scope, the record attributes attrs being in the last one.
+func (h *StrictHandler) checkScopes(msg string, attrs []slog.Attr) {
+	h.t.Helper()
+	path := ""
+	for i, s := range h.scopes {
+		if i > 0 {
+			path += s.group + "."
+		}
+		level := s.attrs
+		var child string
+		if i == len(h.scopes)-1 {
+			level = append(level[:len(level):len(level)], attrs...)
+		} else {
+			child = h.scopes[i+1].group
+		}
+		h.checkLevel(msg, path, s.group, level, child)
+	}
+}
+
+// checkLevel checks attrs, written in the group whose dot-joined path is path, and whose name is group.
+// child is the name of the group added with WithGroup in this group, if any.
+func (h *StrictHandler) checkLevel(msg, path, group string, attrs []slog.Attr, child string) {
+	h.t.Helper()
+	attrs = inline(attrs)
+	keys := make(map[string]bool, len(attrs)+1)
+	groups := map[string]bool{}
+	if child != "" {
+		keys[child] = true
+		groups[child] = true
+	}
+	for _, a := range attrs {
+		if a.Value.Resolve().Kind() == slog.KindGroup {
+			groups[a.Key] = true
+		}
+	}
+	for _, a := range attrs {
+		if h.checks&CheckDuplicateKeys != 0 && keys[a.Key] {
+			h.t.Errorf("zeroslogtest: record %q has duplicate key %q", msg, path+a.Key)
+		}
+		keys[a.Key] = true
+		if h.checks&CheckReservedKeys != 0 && path == "" && isReserved(a.Key) {
+			h.t.Errorf("zeroslogtest: record %q has reserved key %q", msg, a.Key)
+		}
+		if h.checks&CheckUngroupedAttrs != 0 {
+			if prefix, _, ok := strings.Cut(a.Key, "."); ok && groups[prefix] {
+				h.t.Errorf("zeroslogtest: record %q has key %q, which should be in group %q", msg, path+a.Key, path+prefix)
+			} else if ok && prefix == group {
+				h.t.Errorf("zeroslogtest: record %q has key %q, which repeats the name of its group %q", msg, path+a.Key, strings.TrimSuffix(path, "."))
+			}
+		}
+		if value := a.Value.Resolve(); value.Kind() == slog.KindGroup {
+			h.checkLevel(msg, path+a.Key+".", a.Key, value.Group(), "")
+		}
+	}
+}
+
+// inline returns attrs without empty attributes, and with the members of groups with an empty key
+// in place of the groups, like slog handlers write them.
+func inline(attrs []slog.Attr) []slog.Attr {
+	var inlined []slog.Attr
+	for _, a := range attrs {
+		switch {
+		case a.Equal(slog.Attr{}):
+		case a.Key == "" && a.Value.Resolve().Kind() == slog.KindGroup:
+			inlined = append(inlined, inline(a.Value.Resolve().Group())...)
+		default:
+			inlined = append(inlined, a)
+		}
+	}
+	return inlined
+}
+
+// isReserved reports whether key is the key of a field written by handlers themselves.
+func isReserved(key string) bool {
+	switch key {
+	case slog.TimeKey, slog.LevelKey, slog.MessageKey, slog.SourceKey,
+		zerolog.TimestampFieldName, zerolog.LevelFieldName, zerolog.MessageFieldName, zerolog.CallerFieldName:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,125 @@
+package zeroslogtest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeT records the errors reported by a StrictHandler.
+type fakeT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestStrictHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		checks []Check
+		log    func(l *slog.Logger)
+		want   string
+	}{
+		{
+			name: "DuplicateKey",
+			log:  func(l *slog.Logger) { l.Info("msg", "a", 1, "a", 2) },
+			want: `duplicate key "a"`,
+		},
+		{
+			name: "DuplicateKey_WithAttrs",
+			log:  func(l *slog.Logger) { l.With("a", 1).Info("msg", "a", 2) },
+			want: `duplicate key "a"`,
+		},
+		{
+			name: "DuplicateKey_InlineGroup",
+			log:  func(l *slog.Logger) { l.Info("msg", "a", 1, slog.Group("", "a", 2)) },
+			want: `duplicate key "a"`,
+		},
+		{
+			name: "DuplicateKey_WithGroup",
+			log:  func(l *slog.Logger) { l.With("g", 1).WithGroup("g").Info("msg", "a", 2) },
+			want: `duplicate key "g"`,
+		},
+		{
+			name: "DuplicateKey_InGroup",
+			log:  func(l *slog.Logger) { l.Info("msg", slog.Group("g", "a", 1, "a", 2)) },
+			want: `duplicate key "g.a"`,
+		},
+		{
+			name: "ReservedKey",
+			log:  func(l *slog.Logger) { l.Info("msg", "level", "debug") },
+			want: `reserved key "level"`,
+		},
+		{
+			name: "UngroupedAttr",
+			log:  func(l *slog.Logger) { l.Info("msg", slog.Group("req", "method", "GET"), "req.id", 1) },
+			want: `key "req.id", which should be in group "req"`,
+		},
+		{
+			name: "UngroupedAttr_WithGroup",
+			log:  func(l *slog.Logger) { l.WithGroup("req").Info("msg", "req.id", 1) },
+			want: `key "req.req.id", which repeats the name of its group "req"`,
+		},
+		{
+			name:   "Source",
+			checks: []Check{CheckSource},
+			log: func(l *slog.Logger) {
+				l.Handler().Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "msg", 0))
+			},
+			want: `record "msg" has no source`,
+		},
+		{
+			name:   "SingleLine",
+			checks: []Check{CheckSingleLine},
+			log:    func(l *slog.Logger) { l.Info("first\nsecond") },
+			want:   `message "first\nsecond" contains a newline`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ft := &fakeT{TB: t}
+			var b bytes.Buffer
+			test.log(slog.New(NewStrictHandler(slog.NewJSONHandler(&b, nil), ft, test.checks...)))
+			if len(ft.errors) != 1 || !strings.Contains(ft.errors[0], test.want) {
+				t.Errorf("Expected an error containing %q, got %q", test.want, ft.errors)
+			}
+			if b.Len() == 0 {
+				t.Error("Expected the record to be passed to the inner handler")
+			}
+		})
+	}
+}
+
+func TestStrictHandler_Clean(t *testing.T) {
+	ft := &fakeT{TB: t}
+	l := slog.New(NewStrictHandler(slog.NewJSONHandler(io.Discard, &slog.HandlerOptions{AddSource: true}), ft, AllChecks))
+	l.Info("msg", "a", 1, slog.Group("g", "a", 2), slog.Group("", "b", 3), "c.d", 4)
+	l = l.With("a", 1).WithGroup("g").With("a", 2)
+	l.Info("msg", "b", 3, slog.Group("h", "a", 4, "b.c", 5), "level", "not reserved in a group")
+	if len(ft.errors) > 0 {
+		t.Errorf("Unexpected errors: %q", ft.errors)
+	}
+}
+
+func TestStrictHandler_Toggle(t *testing.T) {
+	ft := &fakeT{TB: t}
+	l := slog.New(NewStrictHandler(slog.NewJSONHandler(io.Discard, nil), ft, CheckReservedKeys))
+	l.Info("first\nsecond", "a", 1, "a", 2, slog.Group("g"), "g.a", 3)
+	if len(ft.errors) > 0 {
+		t.Errorf("Unexpected errors: %q", ft.errors)
+	}
+	l.Info("msg", "msg", "value")
+	if len(ft.errors) != 1 {
+		t.Errorf("Expected a reserved key error, got %q", ft.errors)
+	}
+}